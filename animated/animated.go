@@ -0,0 +1,113 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+// Package animated decodes animated images: GIF and APNG. Frames are composed
+// at decoding, respecting disposal and blending of each format, so every frame
+// is a complete picture, ready to be uploaded to a texture.
+package animated
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"time"
+)
+
+// Frame is a single complete frame and how long it's shown.
+type Frame struct {
+	Image *image.RGBA
+	Delay time.Duration
+}
+
+// Image is a sequence of frames of the same size.
+type Image struct {
+	Frames []Frame
+	// How many times the animation is played. Zero means forever.
+	Loops int
+}
+
+// Size returns size of frames in pixels.
+func (img *Image) Size() image.Point {
+	if len(img.Frames) == 0 {
+		return image.Point{}
+	}
+	return img.Frames[0].Image.Bounds().Size()
+}
+
+// Length returns how long a single loop takes.
+func (img *Image) Length() time.Duration {
+	var d time.Duration
+	for _, f := range img.Frames {
+		d += f.Delay
+	}
+	return d
+}
+
+// Finished returns true if the animation has played all its loops by time t.
+// Endless animations never finish.
+func (img *Image) Finished(t time.Duration) bool {
+	length := img.Length()
+	return img.Loops > 0 && (length <= 0 || t >= length*time.Duration(img.Loops))
+}
+
+// FrameAt returns index of the frame shown at time t since the start. When all
+// the loops are played, the last frame stays.
+func (img *Image) FrameAt(t time.Duration) int {
+	if len(img.Frames) == 0 {
+		return -1
+	}
+	if img.Finished(t) {
+		return len(img.Frames) - 1
+	}
+	length := img.Length()
+	if length <= 0 || t < 0 {
+		return 0
+	}
+
+	t %= length
+	for i, f := range img.Frames {
+		if t < f.Delay {
+			return i
+		}
+		t -= f.Delay
+	}
+	return len(img.Frames) - 1
+}
+
+// Decode decodes an animated image, GIF or PNG, which may be an APNG. Any other
+// image format registered in package image is decoded as a single frame.
+func Decode(r io.Reader) (*Image, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(8)
+
+	switch {
+	case bytes.HasPrefix(magic, []byte("GIF8")):
+		return DecodeGIF(br)
+	case bytes.Equal(magic, pngSignature):
+		return DecodeAPNG(br)
+	}
+
+	still, _, err := image.Decode(br)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode an image: %v", err)
+	}
+	return Still(still), nil
+}
+
+// Still returns an animation of a single frame showing img.
+func Still(img image.Image) *Image {
+	return &Image{Frames: []Frame{{Image: toRGBA(img)}}, Loops: 1}
+}
+
+// LoadFile decodes an animated image from a file.
+func LoadFile(file string) (*Image, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load image from %q: %v", file, err)
+	}
+	defer f.Close()
+
+	return Decode(f)
+}
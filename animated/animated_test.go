@@ -0,0 +1,169 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package animated
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+	"time"
+)
+
+func TestDecodeGIF(t *testing.T) {
+	palette := color.Palette{color.Transparent, color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 255, 255}}
+	frame := func(r image.Rectangle, index uint8) *image.Paletted {
+		p := image.NewPaletted(r, palette)
+		for i := range p.Pix {
+			p.Pix[i] = index
+		}
+		return p
+	}
+
+	src := &gif.GIF{
+		Image: []*image.Paletted{
+			frame(image.Rect(0, 0, 2, 2), 1),
+			frame(image.Rect(0, 0, 1, 1), 2),
+			frame(image.Rect(1, 1, 2, 2), 2),
+		},
+		Delay:     []int{10, 0, 5},
+		Disposal:  []byte{gif.DisposalNone, gif.DisposalBackground, gif.DisposalNone},
+		LoopCount: -1,
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(img.Frames) != 3 || img.Loops != 1 {
+		t.Fatalf("Got %d frames and %d loops, want 3 and 1", len(img.Frames), img.Loops)
+	}
+	if img.Frames[1].Delay != defaultDelay || img.Frames[2].Delay != 50*time.Millisecond {
+		t.Errorf("Delays are %v and %v, want %v and 50ms", img.Frames[1].Delay, img.Frames[2].Delay, defaultDelay)
+	}
+
+	last := img.Frames[2].Image
+	// Second frame was disposed to background, the first one stays under it
+	if c := last.RGBAAt(0, 0); c.A != 0 {
+		t.Errorf("Disposed pixel is %v, want transparent", c)
+	}
+	if c := last.RGBAAt(1, 0); c != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("Pixel of the first frame is %v, want red", c)
+	}
+	if c := last.RGBAAt(1, 1); c != (color.RGBA{0, 0, 255, 255}) {
+		t.Errorf("Pixel of the last frame is %v, want blue", c)
+	}
+}
+
+// encodeIDAT returns image data of a PNG.
+func encodeIDAT(t *testing.T, img image.Image) (ihdr, idat []byte) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	chunks, err := readChunks(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range chunks {
+		switch c.typ {
+		case "IHDR":
+			ihdr = c.data
+		case "IDAT":
+			idat = append(idat, c.data...)
+		}
+	}
+	return ihdr, idat
+}
+
+func frameControl(seq, w, h, x, y uint32, num, den uint16, dispose, blend byte) []byte {
+	data := make([]byte, 26)
+	be := binary.BigEndian
+	be.PutUint32(data, seq)
+	be.PutUint32(data[4:], w)
+	be.PutUint32(data[8:], h)
+	be.PutUint32(data[12:], x)
+	be.PutUint32(data[16:], y)
+	be.PutUint16(data[20:], num)
+	be.PutUint16(data[22:], den)
+	data[24], data[25] = dispose, blend
+	return data
+}
+
+func TestDecodeAPNG(t *testing.T) {
+	// Both frames have transparency, so they are encoded the same way
+	first := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	first.SetNRGBA(0, 0, color.NRGBA{255, 0, 0, 255})
+	second := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	second.SetNRGBA(0, 0, color.NRGBA{0, 0, 255, 128})
+
+	ihdr, data0 := encodeIDAT(t, first)
+	_, data1 := encodeIDAT(t, second)
+
+	actl := make([]byte, 8)
+	binary.BigEndian.PutUint32(actl, 2)
+	binary.BigEndian.PutUint32(actl[4:], 3)
+
+	var buf bytes.Buffer
+	buf.Write(pngSignature)
+	writeChunk(&buf, "IHDR", ihdr)
+	writeChunk(&buf, "acTL", actl)
+	writeChunk(&buf, "fcTL", frameControl(0, 2, 1, 0, 0, 1, 10, apngDisposeNone, apngBlendSource))
+	writeChunk(&buf, "IDAT", data0)
+	writeChunk(&buf, "fcTL", frameControl(1, 1, 1, 1, 0, 50, 1000, apngDisposeNone, apngBlendOver))
+	writeChunk(&buf, "fdAT", append([]byte{0, 0, 0, 2}, data1...))
+	writeChunk(&buf, "IEND", nil)
+
+	img, err := Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(img.Frames) != 2 || img.Loops != 3 {
+		t.Fatalf("Got %d frames and %d loops, want 2 and 3", len(img.Frames), img.Loops)
+	}
+	if img.Frames[0].Delay != 100*time.Millisecond || img.Frames[1].Delay != 50*time.Millisecond {
+		t.Errorf("Delays are %v and %v, want 100ms and 50ms", img.Frames[0].Delay, img.Frames[1].Delay)
+	}
+
+	last := img.Frames[1].Image
+	if c := last.RGBAAt(0, 0); c != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("Pixel of the first frame is %v, want red", c)
+	}
+	if c := last.RGBAAt(1, 0); c.B < 120 || c.A < 120 || c.A > 136 {
+		t.Errorf("Blended pixel is %v, want half transparent blue", c)
+	}
+}
+
+func TestFrameAt(t *testing.T) {
+	img := &Image{
+		Frames: []Frame{{Delay: 100 * time.Millisecond}, {Delay: 50 * time.Millisecond}},
+		Loops:  2,
+	}
+
+	cases := []struct {
+		t    time.Duration
+		want int
+	}{
+		{0, 0},
+		{120 * time.Millisecond, 1},
+		{160 * time.Millisecond, 0},
+		{290 * time.Millisecond, 1},
+		// All loops are played, the last frame stays
+		{time.Second, 1},
+	}
+	for _, c := range cases {
+		if got := img.FrameAt(c.t); got != c.want {
+			t.Errorf("FrameAt(%v) = %d, want %d", c.t, got, c.want)
+		}
+	}
+	if !img.Finished(300 * time.Millisecond) {
+		t.Error("Animation hasn't finished after two loops")
+	}
+}
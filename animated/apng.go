@@ -0,0 +1,233 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package animated
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"time"
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// APNG dispose and blend operations
+const (
+	apngDisposeNone       = 0
+	apngDisposeBackground = 1
+	apngDisposePrevious   = 2
+
+	apngBlendSource = 0
+	apngBlendOver   = 1
+)
+
+type pngChunk struct {
+	typ  string
+	data []byte
+}
+
+// apngFrame is a frame control chunk with the data of the frame.
+type apngFrame struct {
+	width, height, x, y uint32
+	delay               time.Duration
+	dispose, blend      byte
+	data                []byte
+}
+
+// DecodeAPNG decodes all the frames of an animated PNG. Usual PNGs are decoded
+// as a single frame.
+func DecodeAPNG(r io.Reader) (*Image, error) {
+	raw, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode APNG: %v", err)
+	}
+	chunks, err := readChunks(raw)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode APNG: %v", err)
+	}
+
+	// Image header and chunks going before the image data (palette,
+	// transparency, gamma and so on) are shared by all the frames
+	var (
+		ihdr     []byte
+		shared   []pngChunk
+		animated bool
+		loops    int
+		frames   []*apngFrame
+		current  *apngFrame
+		seenIDAT bool
+	)
+	for _, c := range chunks {
+		switch c.typ {
+		case "IHDR":
+			ihdr = c.data
+		case "acTL":
+			if len(c.data) < 8 {
+				return nil, fmt.Errorf("Failed to decode APNG: acTL chunk too short")
+			}
+			animated = true
+			loops = int(binary.BigEndian.Uint32(c.data[4:]))
+		case "fcTL":
+			f, err := parseFrameControl(c.data)
+			if err != nil {
+				return nil, err
+			}
+			current = f
+			frames = append(frames, f)
+		case "IDAT":
+			seenIDAT = true
+			// Default image is the first frame only if a fcTL goes before it
+			if current != nil {
+				current.data = append(current.data, c.data...)
+			}
+		case "fdAT":
+			if len(c.data) < 4 || current == nil {
+				return nil, fmt.Errorf("Failed to decode APNG: broken fdAT chunk")
+			}
+			// Frame data is IDAT data after a sequence number
+			current.data = append(current.data, c.data[4:]...)
+		case "IEND":
+		default:
+			if !seenIDAT {
+				shared = append(shared, c)
+			}
+		}
+	}
+	if len(ihdr) < 13 {
+		return nil, fmt.Errorf("Failed to decode APNG: no IHDR chunk")
+	}
+
+	if !animated || len(frames) == 0 {
+		still, err := png.Decode(bytes.NewReader(raw))
+		if err != nil {
+			return nil, fmt.Errorf("Failed to decode PNG: %v", err)
+		}
+		return &Image{Frames: []Frame{{Image: toRGBA(still)}}, Loops: 1}, nil
+	}
+
+	width, height := binary.BigEndian.Uint32(ihdr), binary.BigEndian.Uint32(ihdr[4:])
+	canvas := image.NewRGBA(image.Rect(0, 0, int(width), int(height)))
+	img := &Image{Loops: loops}
+
+	for i, f := range frames {
+		part, err := decodeFrame(ihdr, shared, f)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to decode APNG frame %d: %v", i, err)
+		}
+		rect := image.Rect(int(f.x), int(f.y), int(f.x+f.width), int(f.y+f.height))
+
+		dispose := f.dispose
+		if i == 0 && dispose == apngDisposePrevious {
+			dispose = apngDisposeBackground
+		}
+		var previous *image.RGBA
+		if dispose == apngDisposePrevious {
+			previous = copyRGBA(canvas)
+		}
+
+		op := draw.Over
+		if f.blend == apngBlendSource {
+			op = draw.Src
+		}
+		draw.Draw(canvas, rect, part, part.Bounds().Min, op)
+		img.Frames = append(img.Frames, Frame{Image: copyRGBA(canvas), Delay: f.delay})
+
+		switch dispose {
+		case apngDisposeBackground:
+			draw.Draw(canvas, rect, image.Transparent, image.Point{}, draw.Src)
+		case apngDisposePrevious:
+			canvas = previous
+		}
+	}
+	return img, nil
+}
+
+// readChunks splits a PNG stream into chunks, checking their CRCs.
+func readChunks(raw []byte) ([]pngChunk, error) {
+	if !bytes.HasPrefix(raw, pngSignature) {
+		return nil, fmt.Errorf("not a PNG")
+	}
+	raw = raw[len(pngSignature):]
+
+	var chunks []pngChunk
+	for len(raw) >= 12 {
+		length := binary.BigEndian.Uint32(raw)
+		if uint64(length)+12 > uint64(len(raw)) {
+			return nil, fmt.Errorf("chunk too long")
+		}
+		typ, data := raw[4:8], raw[8:8+length]
+		crc := binary.BigEndian.Uint32(raw[8+length:])
+		if crc32.ChecksumIEEE(raw[4:8+length]) != crc {
+			return nil, fmt.Errorf("wrong CRC of %s chunk", typ)
+		}
+		chunks = append(chunks, pngChunk{typ: string(typ), data: data})
+		raw = raw[12+length:]
+	}
+	return chunks, nil
+}
+
+func parseFrameControl(data []byte) (*apngFrame, error) {
+	if len(data) < 26 {
+		return nil, fmt.Errorf("Failed to decode APNG: fcTL chunk too short")
+	}
+	be := binary.BigEndian
+	f := &apngFrame{
+		width:   be.Uint32(data[4:]),
+		height:  be.Uint32(data[8:]),
+		x:       be.Uint32(data[12:]),
+		y:       be.Uint32(data[16:]),
+		dispose: data[24],
+		blend:   data[25],
+	}
+
+	// Delay is a fraction of a second, zero denominator means 1/100
+	num, den := be.Uint16(data[20:]), be.Uint16(data[22:])
+	if den == 0 {
+		den = 100
+	}
+	f.delay = time.Duration(num) * time.Second / time.Duration(den)
+	if f.delay < minDelay {
+		f.delay = defaultDelay
+	}
+	return f, nil
+}
+
+// decodeFrame decodes frame data as a PNG made of the shared chunks, with the
+// size of the frame.
+func decodeFrame(ihdr []byte, shared []pngChunk, f *apngFrame) (image.Image, error) {
+	var buf bytes.Buffer
+	buf.Write(pngSignature)
+
+	header := append([]byte(nil), ihdr...)
+	binary.BigEndian.PutUint32(header, f.width)
+	binary.BigEndian.PutUint32(header[4:], f.height)
+	writeChunk(&buf, "IHDR", header)
+	for _, c := range shared {
+		writeChunk(&buf, c.typ, c.data)
+	}
+	writeChunk(&buf, "IDAT", f.data)
+	writeChunk(&buf, "IEND", nil)
+
+	return png.Decode(&buf)
+}
+
+func writeChunk(w io.Writer, typ string, data []byte) {
+	var head [8]byte
+	binary.BigEndian.PutUint32(head[:], uint32(len(data)))
+	copy(head[4:], typ)
+	w.Write(head[:])
+	w.Write(data)
+
+	crc := crc32.NewIEEE()
+	crc.Write(head[4:])
+	crc.Write(data)
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc.Sum32())
+	w.Write(sum[:])
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package animated
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"io"
+	"time"
+)
+
+// minDelay is the shortest delay of a frame. Browsers show frames with shorter
+// delays (usually zero) for 100ms, and GIFs are made to look right there.
+const minDelay = 20 * time.Millisecond
+
+// defaultDelay is the delay of frames with too short ones.
+const defaultDelay = 100 * time.Millisecond
+
+// DecodeGIF decodes all the frames of a GIF.
+func DecodeGIF(r io.Reader) (*Image, error) {
+	g, err := gif.DecodeAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode GIF: %v", err)
+	}
+	if len(g.Image) == 0 {
+		return nil, fmt.Errorf("Failed to decode GIF: no frames")
+	}
+
+	img := &Image{}
+	switch {
+	case g.LoopCount == 0:
+		img.Loops = 0
+	case g.LoopCount < 0:
+		img.Loops = 1
+	default:
+		img.Loops = g.LoopCount + 1
+	}
+
+	bounds := image.Rect(0, 0, g.Config.Width, g.Config.Height)
+	if bounds.Empty() {
+		bounds = g.Image[0].Bounds()
+	}
+	canvas := image.NewRGBA(bounds)
+
+	for i, frame := range g.Image {
+		var disposal byte
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+
+		// Disposal tells what to do with the frame, after it's shown
+		var previous *image.RGBA
+		if disposal == gif.DisposalPrevious {
+			previous = copyRGBA(canvas)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+
+		var delay time.Duration
+		if i < len(g.Delay) {
+			delay = time.Duration(g.Delay[i]) * 10 * time.Millisecond
+		}
+		if delay < minDelay {
+			delay = defaultDelay
+		}
+		img.Frames = append(img.Frames, Frame{Image: copyRGBA(canvas), Delay: delay})
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+	return img, nil
+}
+
+func copyRGBA(img *image.RGBA) *image.RGBA {
+	c := image.NewRGBA(img.Bounds())
+	copy(c.Pix, img.Pix)
+	return c
+}
+
+// toRGBA converts any image to RGBA with origin at zero.
+func toRGBA(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Src)
+	return rgba
+}
@@ -0,0 +1,233 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+// Package animation animates properties of widgets, or anything else, over
+// time. Tweens change a single value from one to another with an easing,
+// groups play several animations one after another or at the same time.
+// Any animation may be delayed, repeated and played back and forth.
+// Animations are played by a Player, which is updated with the frame delta:
+//
+//	move := animation.MoveTo(button, g.PosF{X: 1, Y: 0.5}, time.Second, animation.OutBack)
+//	fade := animation.Float(pixmap.SetOpacity, 1, 0, 300*time.Millisecond, animation.Linear)
+//	window.Animations().Play(animation.Sequence(move, fade))
+package animation
+
+import (
+	"math"
+	"time"
+)
+
+// Infinite is the duration of animations repeated forever.
+const Infinite = time.Duration(math.MaxInt64)
+
+// Forever is passed to SetRepeat to repeat an animation until it's stopped.
+const Forever = -1
+
+// Animation is anything that changes over time. Animations don't track time
+// themselves: they are told where they should be by a Player or a group.
+type Animation interface {
+	// Duration returns how long the animation takes with all its delays and
+	// repeats, or Infinite.
+	Duration() time.Duration
+
+	// Seek brings the animation to the given moment since its start. Moments
+	// before the start (including the delay) leave untouched whatever the
+	// animation changes, unless it was already running: then it's brought
+	// back to its beginning.
+	Seek(time.Duration)
+
+	// Rewind makes the animation forget it was ever played, so it starts from
+	// scratch the next time.
+	Rewind()
+}
+
+// timing implements everything common for all the animations: delay, repeats,
+// yoyo and callbacks. Animations embed it and call seek with a function
+// bringing a single run of the animation to the given moment.
+type timing struct {
+	delay  time.Duration
+	repeat int
+	yoyo   bool
+
+	onStart, onComplete func()
+
+	// Called on start before onStart, used by tweens starting from the
+	// current value
+	begin func()
+
+	started, done bool
+
+	// Index of the run seen by the last seek
+	run int
+}
+
+// SetDelay sets the time waited before the animation starts. It's waited only
+// once, not before every repeat.
+func (tm *timing) SetDelay(d time.Duration) {
+	tm.delay = d
+}
+
+// Delay returns the time waited before the animation starts.
+func (tm *timing) Delay() time.Duration {
+	return tm.delay
+}
+
+// SetRepeat sets how many times the animation is repeated after the first
+// run. Pass Forever to repeat it until it's stopped.
+func (tm *timing) SetRepeat(n int) {
+	tm.repeat = n
+}
+
+// Repeat returns how many times the animation is repeated after the first run.
+func (tm *timing) Repeat() int {
+	return tm.repeat
+}
+
+// SetYoyo makes every other repeat of the animation play backwards, so it goes
+// back and forth.
+func (tm *timing) SetYoyo(y bool) {
+	tm.yoyo = y
+}
+
+// Yoyo returns true if every other repeat of the animation plays backwards.
+func (tm *timing) Yoyo() bool {
+	return tm.yoyo
+}
+
+// SetOnStart sets a function called when the animation starts, right after
+// the delay.
+func (tm *timing) SetOnStart(f func()) {
+	tm.onStart = f
+}
+
+// SetOnComplete sets a function called when the animation reaches its end,
+// after all the repeats. Animations in a repeated group complete once per run
+// of the group.
+func (tm *timing) SetOnComplete(f func()) {
+	tm.onComplete = f
+}
+
+// Rewind makes the animation forget it was ever played.
+func (tm *timing) Rewind() {
+	tm.started, tm.done = false, false
+	tm.run = 0
+}
+
+// total returns the duration of an animation, whose single run takes length.
+func (tm *timing) total(length time.Duration) time.Duration {
+	if tm.repeat < 0 || length == Infinite {
+		return Infinite
+	}
+	return add(tm.delay, length*time.Duration(tm.repeat+1))
+}
+
+// seek maps a moment since the start of the animation to a moment of a single
+// run, which takes length, and passes it to seekRun.
+func (tm *timing) seek(t, length time.Duration, seekRun func(time.Duration)) {
+	if t < tm.delay {
+		if tm.started {
+			seekRun(0)
+			tm.Rewind()
+		}
+		return
+	}
+
+	total := tm.total(length)
+	if tm.done && t >= total {
+		return
+	}
+	if !tm.started {
+		tm.started = true
+		tm.run = 0
+		if tm.begin != nil {
+			tm.begin()
+		}
+		if tm.onStart != nil {
+			tm.onStart()
+		}
+	}
+
+	t -= tm.delay
+	run, within := 0, length
+	if length > 0 && length != Infinite {
+		run, within = int(t/length), t%length
+	}
+	if tm.repeat >= 0 && run > tm.repeat {
+		run, within = tm.repeat, length
+	}
+
+	// Big steps may jump over the end of the previous run: it's finished first,
+	// so everything gets where it should be and callbacks are called
+	if run > tm.run {
+		seekRun(tm.runEnd(run-1, length))
+	}
+	tm.run = run
+
+	if tm.yoyo && run%2 == 1 {
+		within = length - within
+	}
+	seekRun(within)
+
+	tm.done = t+tm.delay >= total
+	if tm.done && tm.onComplete != nil {
+		tm.onComplete()
+	}
+}
+
+// runEnd returns the moment of a run, where the run ends.
+func (tm *timing) runEnd(run int, length time.Duration) time.Duration {
+	if tm.yoyo && run%2 == 1 {
+		return 0
+	}
+	return length
+}
+
+// add adds two durations, Infinite stays Infinite.
+func add(a, b time.Duration) time.Duration {
+	if a == Infinite || b == Infinite || a > Infinite-b {
+		return Infinite
+	}
+	return a + b
+}
+
+// Tween changes a value over time. It's defined by its duration, easing and
+// a function setting the value for the given progress, which goes from 0 to 1.
+// Use Float, Point, Size, Rect and Color to tween values of these types.
+type Tween struct {
+	timing
+
+	length time.Duration
+	easing Easing
+	update func(progress float32)
+}
+
+// NewTween returns a tween calling update with eased progress of the tween,
+// which goes from 0 to 1, or slightly beyond with easings like Back. Nil
+// easing is Linear.
+func NewTween(d time.Duration, e Easing, update func(progress float32)) *Tween {
+	if e == nil {
+		e = Linear
+	}
+	return &Tween{length: d, easing: e, update: update}
+}
+
+// Duration returns how long the tween takes with its delay and repeats.
+func (tw *Tween) Duration() time.Duration {
+	return tw.total(tw.length)
+}
+
+// Seek sets the value the tween should have at the given moment.
+func (tw *Tween) Seek(t time.Duration) {
+	tw.seek(t, tw.length, func(t time.Duration) {
+		p := float32(1)
+		if tw.length > 0 {
+			p = float32(float64(t) / float64(tw.length))
+		}
+		tw.update(tw.easing(p))
+	})
+}
+
+// Wait returns an animation doing nothing for the given time. It's handy to
+// make pauses in sequences.
+func Wait(d time.Duration) *Tween {
+	return NewTween(d, Linear, func(float32) {})
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package animation
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	g "github.com/Sergobot/Rocky/geometry"
+)
+
+func near(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-4
+}
+
+func TestEasings(t *testing.T) {
+	easings := map[string]Easing{
+		"Linear": Linear, "InQuad": InQuad, "OutQuad": OutQuad, "InOutQuad": InOutQuad,
+		"InCubic": InCubic, "OutCubic": OutCubic, "InOutCubic": InOutCubic,
+		"InBack": InBack, "OutBack": OutBack, "InOutBack": InOutBack,
+		"InElastic": InElastic, "OutElastic": OutElastic, "InOutElastic": InOutElastic,
+		"InBounce": InBounce, "OutBounce": OutBounce, "InOutBounce": InOutBounce,
+		"Bezier": Bezier(0.25, 0.1, 0.25, 1),
+	}
+	for name, e := range easings {
+		if !near(e(0), 0) || !near(e(1), 1) {
+			t.Errorf("%s(0) = %v, %s(1) = %v, want 0 and 1", name, e(0), name, e(1))
+		}
+	}
+
+	if got := InOutQuad(0.5); !near(got, 0.5) {
+		t.Errorf("InOutQuad(0.5) = %v, want 0.5", got)
+	}
+	if got := OutBack(0.8); got <= 1 {
+		t.Errorf("OutBack(0.8) = %v, want overshoot above 1", got)
+	}
+	// Bezier with control points on the diagonal is linear
+	if got := Bezier(0.3, 0.3, 0.7, 0.7)(0.4); !near(got, 0.4) {
+		t.Errorf("Linear Bezier(0.4) = %v, want 0.4", got)
+	}
+}
+
+func TestTween(t *testing.T) {
+	var v float32 = -1
+	tw := Float(func(f float32) { v = f }, 0, 10, time.Second, Linear)
+	tw.SetDelay(time.Second)
+
+	completed := 0
+	tw.SetOnComplete(func() { completed++ })
+
+	tw.Seek(500 * time.Millisecond)
+	if v != -1 {
+		t.Errorf("Value changed during the delay: %v", v)
+	}
+	tw.Seek(1500 * time.Millisecond)
+	if !near(v, 5) {
+		t.Errorf("Value in the middle = %v, want 5", v)
+	}
+	tw.Seek(3 * time.Second)
+	tw.Seek(4 * time.Second)
+	if v != 10 || completed != 1 {
+		t.Errorf("Value at the end = %v, completed %d times, want 10 and once", v, completed)
+	}
+}
+
+func TestYoyo(t *testing.T) {
+	var v float32
+	tw := Float(func(f float32) { v = f }, 0, 1, time.Second, Linear)
+	tw.SetRepeat(1)
+	tw.SetYoyo(true)
+
+	if d := tw.Duration(); d != 2*time.Second {
+		t.Errorf("Duration() = %v, want 2s", d)
+	}
+	tw.Seek(1500 * time.Millisecond)
+	if !near(v, 0.5) {
+		t.Errorf("Value on the way back = %v, want 0.5", v)
+	}
+	tw.Seek(2 * time.Second)
+	if v != 0 {
+		t.Errorf("Value at the end = %v, want 0", v)
+	}
+}
+
+func TestSequence(t *testing.T) {
+	var pos g.PointF
+	set := func(p g.PointF) { pos = p }
+	right := Point(set, g.PointF{}, g.PointF{X: 1}, time.Second, Linear)
+	down := Point(set, g.PointF{X: 1}, g.PointF{X: 1, Y: 1}, time.Second, Linear)
+	seq := Sequence(right, Wait(time.Second), down)
+
+	var order []string
+	right.SetOnComplete(func() { order = append(order, "right") })
+	down.SetOnComplete(func() { order = append(order, "down") })
+	seq.SetOnComplete(func() { order = append(order, "seq") })
+
+	p := NewPlayer()
+	p.Play(seq)
+	p.Update(1500 * time.Millisecond)
+	if pos != (g.PointF{X: 1}) {
+		t.Errorf("Position during the pause = %v, want {1 0}", pos)
+	}
+
+	// A big step jumps over the end, but everything completes in order
+	p.Update(5 * time.Second)
+	if pos != (g.PointF{X: 1, Y: 1}) {
+		t.Errorf("Position at the end = %v, want {1 1}", pos)
+	}
+	if len(order) != 3 || order[0] != "right" || order[1] != "down" || order[2] != "seq" {
+		t.Errorf("Completed in order %v, want [right down seq]", order)
+	}
+	if p.IsPlaying(seq) {
+		t.Errorf("Finished sequence is still playing")
+	}
+}
+
+func TestParallelRepeat(t *testing.T) {
+	var a, b float32
+	par := Parallel(
+		Float(func(f float32) { a = f }, 0, 1, time.Second, Linear),
+		Float(func(f float32) { b = f }, 0, 1, 2*time.Second, Linear),
+	)
+	par.SetRepeat(Forever)
+	if par.Duration() != Infinite {
+		t.Errorf("Duration() = %v, want Infinite", par.Duration())
+	}
+
+	p := NewPlayer()
+	p.Play(par)
+	p.Update(2500 * time.Millisecond)
+	if !near(a, 0.5) || !near(b, 0.25) {
+		t.Errorf("Values in the second run = %v, %v, want 0.5 and 0.25", a, b)
+	}
+	if !p.IsPlaying(par) {
+		t.Errorf("Endless animation has stopped")
+	}
+}
+
+type mover struct{ pos g.PosF }
+
+func (m *mover) Pos() g.PosF     { return m.pos }
+func (m *mover) SetPos(p g.PosF) { m.pos = p }
+
+func TestMoveTo(t *testing.T) {
+	m := &mover{pos: g.PosF{X: 1}}
+	tw := MoveTo(m, g.PosF{X: 3}, time.Second, Linear)
+
+	p := NewPlayer()
+	p.Play(tw)
+	p.Update(500 * time.Millisecond)
+	if m.pos != (g.PosF{X: 2}) {
+		t.Errorf("Position in the middle = %v, want {2 0}", m.pos)
+	}
+
+	// Played again, it starts from where the widget is
+	p.Update(time.Second)
+	m.pos = g.PosF{X: 5}
+	p.Play(tw)
+	p.Update(500 * time.Millisecond)
+	if m.pos != (g.PosF{X: 4}) {
+		t.Errorf("Position in the middle of the replay = %v, want {4 0}", m.pos)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package animation
+
+import "math"
+
+// Easing maps linear progress of an animation, from 0 to 1, to the progress
+// of the animated value. It's 0 at 0 and 1 at 1, but may go beyond them in
+// between, like Back and Elastic do.
+type Easing func(t float32) float32
+
+// Standard easing functions. In ones accelerate, Out ones decelerate, InOut
+// ones do both. See easings.net to get an idea how they look.
+var (
+	Linear Easing = func(t float32) float32 { return t }
+
+	InQuad    Easing = func(t float32) float32 { return t * t }
+	OutQuad          = Out(InQuad)
+	InOutQuad        = InOut(InQuad)
+
+	InCubic    Easing = func(t float32) float32 { return t * t * t }
+	OutCubic          = Out(InCubic)
+	InOutCubic        = InOut(InCubic)
+
+	InBack    Easing = inBack
+	OutBack          = Out(InBack)
+	InOutBack        = InOut(InBack)
+
+	InElastic    Easing = inElastic
+	OutElastic          = Out(InElastic)
+	InOutElastic        = InOut(InElastic)
+
+	InBounce    = In(OutBounce)
+	OutBounce   = Easing(outBounce)
+	InOutBounce = InOut(InBounce)
+)
+
+// Out turns an In easing into the Out one, like InQuad into OutQuad.
+func Out(in Easing) Easing {
+	return func(t float32) float32 {
+		return 1 - in(1-t)
+	}
+}
+
+// In turns an Out easing into the In one. It's the same as Out, since Out is
+// its own inverse.
+func In(out Easing) Easing {
+	return Out(out)
+}
+
+// InOut makes an easing going through the first half of the animation with
+// in, and through the second half with its Out version.
+func InOut(in Easing) Easing {
+	return func(t float32) float32 {
+		if t < 0.5 {
+			return in(2*t) / 2
+		}
+		return 1 - in(2-2*t)/2
+	}
+}
+
+// backOvershoot is how far Back easings go beyond the end, the usual value
+// giving 10% overshoot.
+const backOvershoot = 1.70158
+
+func inBack(t float32) float32 {
+	return t * t * ((backOvershoot+1)*t - backOvershoot)
+}
+
+func inElastic(t float32) float32 {
+	if t == 0 || t == 1 {
+		return t
+	}
+	return -float32(math.Pow(2, float64(10*t-10)) * math.Sin(float64(10*t-10.75)*2*math.Pi/3))
+}
+
+func outBounce(t float32) float32 {
+	const n, d = 7.5625, 2.75
+	switch {
+	case t < 1/d:
+		return n * t * t
+	case t < 2/d:
+		t -= 1.5 / d
+		return n*t*t + 0.75
+	case t < 2.5/d:
+		t -= 2.25 / d
+		return n*t*t + 0.9375
+	default:
+		t -= 2.625 / d
+		return n*t*t + 0.984375
+	}
+}
+
+// Bezier returns an easing defined by a cubic Bezier curve from (0, 0) to
+// (1, 1) with control points (x1, y1) and (x2, y2), just like CSS
+// cubic-bezier() does. x1 and x2 must be in [0, 1].
+func Bezier(x1, y1, x2, y2 float32) Easing {
+	// Coefficients of the polynomials: at^3 + bt^2 + ct
+	cx := 3 * x1
+	bx := 3*(x2-x1) - cx
+	ax := 1 - cx - bx
+	cy := 3 * y1
+	by := 3*(y2-y1) - cy
+	ay := 1 - cy - by
+
+	curveX := func(s float32) float32 { return ((ax*s+bx)*s + cx) * s }
+	slopeX := func(s float32) float32 { return (3*ax*s+2*bx)*s + cx }
+
+	return func(t float32) float32 {
+		if t <= 0 || t >= 1 {
+			return t
+		}
+
+		// Find the curve parameter s with curveX(s) = t. Newton's method is
+		// fast, but may fail on flat parts, so bisection backs it up.
+		s := t
+		for i := 0; i < 8; i++ {
+			dx := curveX(s) - t
+			if dx > -1e-6 && dx < 1e-6 {
+				return ((ay*s+by)*s + cy) * s
+			}
+			d := slopeX(s)
+			if d > -1e-6 && d < 1e-6 {
+				break
+			}
+			s -= dx / d
+		}
+
+		lo, hi := float32(0), float32(1)
+		s = t
+		for i := 0; i < 32; i++ {
+			x := curveX(s)
+			if x > t-1e-6 && x < t+1e-6 {
+				break
+			}
+			if x < t {
+				lo = s
+			} else {
+				hi = s
+			}
+			s = (lo + hi) / 2
+		}
+		return ((ay*s+by)*s + cy) * s
+	}
+}
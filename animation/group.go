@@ -0,0 +1,98 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package animation
+
+import "time"
+
+// Group is an animation made of other animations, played one after another
+// or all at the same time. Groups may be nested, delayed and repeated just
+// like any other animation.
+type Group struct {
+	timing
+
+	children []Animation
+	parallel bool
+}
+
+// Sequence returns a group playing animations one after another.
+func Sequence(anims ...Animation) *Group {
+	return &Group{children: anims}
+}
+
+// Parallel returns a group playing animations at the same time. It lasts as
+// long as the longest of them.
+func Parallel(anims ...Animation) *Group {
+	return &Group{children: anims, parallel: true}
+}
+
+// Add adds an animation to the end of a sequence, or to a parallel group.
+func (gr *Group) Add(a Animation) {
+	gr.children = append(gr.children, a)
+}
+
+// Animations returns the animations of the group.
+func (gr *Group) Animations() []Animation {
+	return gr.children
+}
+
+// length returns the duration of a single run of the group.
+func (gr *Group) length() time.Duration {
+	var d time.Duration
+	for _, c := range gr.children {
+		if gr.parallel {
+			if cd := c.Duration(); cd > d {
+				d = cd
+			}
+		} else {
+			d = add(d, c.Duration())
+		}
+	}
+	return d
+}
+
+// Duration returns how long the group takes with its delay and repeats.
+func (gr *Group) Duration() time.Duration {
+	return gr.total(gr.length())
+}
+
+// Seek brings all the animations of the group to the given moment.
+func (gr *Group) Seek(t time.Duration) {
+	gr.seek(t, gr.length(), gr.seekRun)
+}
+
+func (gr *Group) seekRun(t time.Duration) {
+	if gr.parallel {
+		for _, c := range gr.children {
+			c.Seek(t)
+		}
+		return
+	}
+
+	starts := make([]time.Duration, len(gr.children))
+	var start time.Duration
+	for i, c := range gr.children {
+		starts[i] = start
+		start = add(start, c.Duration())
+	}
+
+	// Animations which haven't started yet go back to their beginnings first,
+	// the last one first, so the running ones win if they change the same value
+	for i := len(gr.children) - 1; i >= 0; i-- {
+		if t < starts[i] {
+			gr.children[i].Seek(t - starts[i])
+		}
+	}
+	for i, c := range gr.children {
+		if t >= starts[i] {
+			c.Seek(t - starts[i])
+		}
+	}
+}
+
+// Rewind makes the group and all its animations forget they were ever played.
+func (gr *Group) Rewind() {
+	gr.timing.Rewind()
+	for _, c := range gr.children {
+		c.Rewind()
+	}
+}
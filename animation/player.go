@@ -0,0 +1,105 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package animation
+
+import "time"
+
+// Player plays animations. It's updated with the time passed since the last
+// frame, so animations run at the same speed whatever the frame rate is.
+// Every window has one, updated right before the widgets are drawn, but you
+// may create your own with NewPlayer, for example to pause a part of the game.
+type Player struct {
+	playing []*playback
+	speed   float64
+}
+
+type playback struct {
+	anim    Animation
+	elapsed time.Duration
+}
+
+// NewPlayer returns a player with no animations playing at normal speed.
+func NewPlayer() *Player {
+	return &Player{speed: 1}
+}
+
+// SetSpeed scales the time animations are updated with: 2 plays them twice as
+// fast, 0 pauses them.
+func (p *Player) SetSpeed(s float64) {
+	if s < 0 {
+		s = 0
+	}
+	p.speed = s
+}
+
+// Speed returns the scale of the time animations are updated with.
+func (p *Player) Speed() float64 {
+	return p.speed
+}
+
+// Play starts playing an animation from the beginning. If it's already
+// playing, it's restarted.
+func (p *Player) Play(a Animation) {
+	a.Rewind()
+	for _, pb := range p.playing {
+		if pb.anim == a {
+			pb.elapsed = 0
+			return
+		}
+	}
+	p.playing = append(p.playing, &playback{anim: a})
+}
+
+// Stop stops an animation. Whatever it changes stays as it is at the moment.
+func (p *Player) Stop(a Animation) {
+	for i, pb := range p.playing {
+		if pb.anim == a {
+			p.playing = append(p.playing[:i], p.playing[i+1:]...)
+			return
+		}
+	}
+}
+
+// StopAll stops all the animations.
+func (p *Player) StopAll() {
+	p.playing = nil
+}
+
+// IsPlaying returns true if an animation is playing and hasn't reached its end
+// yet.
+func (p *Player) IsPlaying(a Animation) bool {
+	for _, pb := range p.playing {
+		if pb.anim == a {
+			return true
+		}
+	}
+	return false
+}
+
+// Update advances all the animations by dt. Animations reaching their end are
+// removed from the player.
+func (p *Player) Update(dt time.Duration) {
+	if p.speed != 1 {
+		dt = time.Duration(float64(dt) * p.speed)
+	}
+
+	// Callbacks may play and stop animations, so iterate over a copy
+	playing := append([]*playback(nil), p.playing...)
+	for _, pb := range playing {
+		pb.elapsed = add(pb.elapsed, dt)
+		pb.anim.Seek(pb.elapsed)
+		if d := pb.anim.Duration(); d != Infinite && pb.elapsed >= d {
+			p.finished(pb)
+		}
+	}
+}
+
+// finished removes a playback, unless a callback has restarted it.
+func (p *Player) finished(pb *playback) {
+	for i, q := range p.playing {
+		if q == pb && pb.elapsed != 0 {
+			p.playing = append(p.playing[:i], p.playing[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package animation
+
+import (
+	"time"
+
+	g "github.com/Sergobot/Rocky/geometry"
+	"github.com/Sergobot/Rocky/paint"
+)
+
+// Float returns a tween calling set with values going from one to another.
+// set is usually a setter of a widget, like SetRotation or SetOpacity.
+func Float(set func(float32), from, to float32, d time.Duration, e Easing) *Tween {
+	return NewTween(d, e, func(p float32) {
+		set(lerp(from, to, p))
+	})
+}
+
+// Point returns a tween calling set with points moving from one to another.
+func Point(set func(g.PointF), from, to g.PointF, d time.Duration, e Easing) *Tween {
+	return NewTween(d, e, func(p float32) {
+		set(lerpPoint(from, to, p))
+	})
+}
+
+// Size returns a tween calling set with sizes changing from one to another.
+func Size(set func(g.SizeF), from, to g.SizeF, d time.Duration, e Easing) *Tween {
+	return NewTween(d, e, func(p float32) {
+		set(lerpSize(from, to, p))
+	})
+}
+
+// Rect returns a tween calling set with rects changing from one to another.
+// It's handy to animate geometry of a widget.
+func Rect(set func(g.RectF), from, to g.RectF, d time.Duration, e Easing) *Tween {
+	return NewTween(d, e, func(p float32) {
+		set(lerpRect(from, to, p))
+	})
+}
+
+// Color returns a tween calling set with colors blending from one to another.
+// Colors are blended component by component, alpha included.
+func Color(set func(paint.Color), from, to paint.Color, d time.Duration, e Easing) *Tween {
+	return NewTween(d, e, func(p float32) {
+		set(paint.Color{
+			R: lerp(from.R, to.R, p),
+			G: lerp(from.G, to.G, p),
+			B: lerp(from.B, to.B, p),
+			A: lerp(from.A, to.A, p),
+		})
+	})
+}
+
+// Mover is anything positioned with SetPos, like every widget.
+type Mover interface {
+	Pos() g.PosF
+	SetPos(g.PosF)
+}
+
+// Resizer is anything sized with SetSize, like every widget.
+type Resizer interface {
+	Size() g.SizeF
+	SetSize(g.SizeF)
+}
+
+// Placer is anything placed with SetGeometry, like every widget.
+type Placer interface {
+	Geometry() g.RectF
+	SetGeometry(g.RectF)
+}
+
+// MoveTo returns a tween moving a widget from wherever it is, when the tween
+// starts, to the given position.
+func MoveTo(m Mover, to g.PosF, d time.Duration, e Easing) *Tween {
+	var from g.PosF
+	tw := NewTween(d, e, func(p float32) {
+		m.SetPos(g.PosF(lerpPoint(g.PointF(from), g.PointF(to), p)))
+	})
+	tw.begin = func() {
+		from = m.Pos()
+	}
+	return tw
+}
+
+// ResizeTo returns a tween resizing a widget from whatever size it has, when
+// the tween starts, to the given one.
+func ResizeTo(r Resizer, to g.SizeF, d time.Duration, e Easing) *Tween {
+	var from g.SizeF
+	tw := NewTween(d, e, func(p float32) {
+		r.SetSize(lerpSize(from, to, p))
+	})
+	tw.begin = func() {
+		from = r.Size()
+	}
+	return tw
+}
+
+// GeometryTo returns a tween changing geometry of a widget from whatever it
+// is, when the tween starts, to the given one.
+func GeometryTo(pl Placer, to g.RectF, d time.Duration, e Easing) *Tween {
+	var from g.RectF
+	tw := NewTween(d, e, func(p float32) {
+		pl.SetGeometry(lerpRect(from, to, p))
+	})
+	tw.begin = func() {
+		from = pl.Geometry()
+	}
+	return tw
+}
+
+func lerp(a, b, p float32) float32 {
+	return a + (b-a)*p
+}
+
+func lerpPoint(a, b g.PointF, p float32) g.PointF {
+	return g.PointF{X: lerp(a.X, b.X, p), Y: lerp(a.Y, b.Y, p)}
+}
+
+func lerpSize(a, b g.SizeF, p float32) g.SizeF {
+	return g.SizeF{W: lerp(a.W, b.W, p), H: lerp(a.H, b.H, p)}
+}
+
+func lerpRect(a, b g.RectF, p float32) g.RectF {
+	return g.RectF{
+		PosF:  g.PosF(lerpPoint(g.PointF(a.PosF), g.PointF(b.PosF), p)),
+		SizeF: lerpSize(a.SizeF, b.SizeF, p),
+	}
+}
@@ -0,0 +1,439 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+// Package atlas packs many small images into a few big ones, called pages, so
+// they are drawn with a few textures instead of hundreds. Packing may happen
+// at runtime with Builder, or at build time: Save writes pages and metadata,
+// which Load reads back.
+package atlas
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	// Images are usually PNG, but JPEG ones may be packed too
+	_ "image/jpeg"
+
+	g "github.com/Sergobot/Rocky/geometry"
+)
+
+// Method is an algorithm of packing rects on a page.
+type Method int
+
+// Packing methods. MaxRects is the default one.
+const (
+	// MaxRects packs tighter, but is slower
+	MaxRects Method = iota
+	// Skyline is faster and good for images of similar heights
+	Skyline Method = iota
+)
+
+// DefaultPageSize is the maximum size of pages, unless it's set in Options.
+// Any OpenGL 3.3 driver supports textures of that size.
+const DefaultPageSize = 2048
+
+// Options tell how images are packed.
+type Options struct {
+	// MaxPageSize is the maximum width and height of a page. It's rounded up
+	// to a power of two. Pages are shrunk to the smallest power of two fitting
+	// what's packed on them.
+	MaxPageSize int
+
+	// Padding is the number of transparent pixels between images
+	Padding int
+
+	// Extrude repeats edge pixels of images this many times around them, so
+	// filtering doesn't blend them with their neighbours
+	Extrude int
+
+	Method Method
+}
+
+// Region is a named image packed into an atlas.
+type Region struct {
+	Name string `json:"name"`
+	// Index of the page
+	Page int `json:"page"`
+
+	// Rect of the image on the page in pixels
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+
+	// Texture coordinates of the image: top-left and bottom-right corners
+	U0 float32 `json:"u0"`
+	V0 float32 `json:"v0"`
+	U1 float32 `json:"u1"`
+	V1 float32 `json:"v1"`
+}
+
+// Rect returns rect of the region on its page in pixels.
+func (r Region) Rect() g.Rect {
+	return g.Rect{Pos: g.Pos{X: r.X, Y: r.Y}, Size: g.Size{W: r.W, H: r.H}}
+}
+
+// Page is a single image with packed images on it.
+type Page struct {
+	// Image is not stored in metadata, it's saved to File
+	Image *image.RGBA `json:"-"`
+	// File the page is saved to, relative to the metadata file
+	File string `json:"file,omitempty"`
+
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// Atlas is a set of pages and regions on them.
+type Atlas struct {
+	Pages   []Page   `json:"pages"`
+	Regions []Region `json:"regions"`
+
+	// Indices of regions by name
+	index map[string]int
+}
+
+// Region returns a region with the given name.
+func (a *Atlas) Region(name string) (Region, bool) {
+	if a.index == nil {
+		a.index = make(map[string]int, len(a.Regions))
+		for i, r := range a.Regions {
+			a.index[r.Name] = i
+		}
+	}
+	i, ok := a.index[name]
+	if !ok {
+		return Region{}, false
+	}
+	return a.Regions[i], true
+}
+
+// Builder collects images and packs them into an atlas.
+type Builder struct {
+	Options Options
+
+	names  []string
+	images []image.Image
+}
+
+// NewBuilder returns an empty builder.
+func NewBuilder(o Options) *Builder {
+	return &Builder{Options: o}
+}
+
+// Add adds an image with the given name. Images with the same name replace
+// each other.
+func (b *Builder) Add(name string, img image.Image) {
+	for i, n := range b.names {
+		if n == name {
+			b.images[i] = img
+			return
+		}
+	}
+	b.names = append(b.names, name)
+	b.images = append(b.images, img)
+}
+
+// AddFile adds an image file named with its path.
+func (b *Builder) AddFile(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("Failed to add image to atlas: %v", err)
+	}
+	defer f.Close()
+
+	return b.addReader(file, f)
+}
+
+// AddFS adds an image file from a file system named with its path.
+func (b *Builder) AddFS(fsys fs.FS, path string) error {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return fmt.Errorf("Failed to add image to atlas: %v", err)
+	}
+	defer f.Close()
+
+	return b.addReader(path, f)
+}
+
+func (b *Builder) addReader(name string, r io.Reader) error {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return fmt.Errorf("Failed to decode image %q: %v", name, err)
+	}
+	b.Add(name, img)
+	return nil
+}
+
+// Len returns the number of images added.
+func (b *Builder) Len() int {
+	return len(b.names)
+}
+
+// bySize sorts images by their longer side, longest first, which is the
+// order packers do best with.
+type bySize struct {
+	order []int
+	sizes []image.Point
+}
+
+func (s bySize) Len() int      { return len(s.order) }
+func (s bySize) Swap(i, j int) { s.order[i], s.order[j] = s.order[j], s.order[i] }
+func (s bySize) Less(i, j int) bool {
+	a, b := s.sizes[s.order[i]], s.sizes[s.order[j]]
+	return longSide(a) > longSide(b) || (longSide(a) == longSide(b) && a.X*a.Y > b.X*b.Y)
+}
+
+func longSide(p image.Point) int {
+	if p.X > p.Y {
+		return p.X
+	}
+	return p.Y
+}
+
+// nextPowerOfTwo returns the smallest power of two not less than n.
+func nextPowerOfTwo(n int) int {
+	p := 1
+	for p < n {
+		p *= 2
+	}
+	return p
+}
+
+// Build packs all the images added into as few pages as needed.
+func (b *Builder) Build() (*Atlas, error) {
+	o := b.Options
+	size := o.MaxPageSize
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+	size = nextPowerOfTwo(size)
+
+	// Cell of an image is the image with extruded edges and padding on the
+	// right and bottom sides. Padding at the right and bottom edges of a page
+	// isn't needed, so pages are packed as if they were bigger by padding.
+	border := 2*o.Extrude + o.Padding
+	packed := size + o.Padding
+	cells := make([]image.Point, len(b.images))
+	order := make([]int, len(b.images))
+	for i, img := range b.images {
+		cells[i] = b.images[i].Bounds().Size().Add(image.Pt(border, border))
+		order[i] = i
+		if cells[i].X > packed || cells[i].Y > packed {
+			return nil, fmt.Errorf("Failed to pack image %q: it's bigger than a page (%v)", b.names[i], img.Bounds().Size())
+		}
+	}
+	sort.Stable(bySize{order, cells})
+
+	var packers []packer
+	positions := make([]image.Point, len(b.images))
+	pages := make([]int, len(b.images))
+	used := []image.Point{}
+	for _, i := range order {
+		placed := false
+		for p, pk := range packers {
+			if pos, ok := pk.insert(cells[i].X, cells[i].Y); ok {
+				positions[i], pages[i], placed = pos, p, true
+				break
+			}
+		}
+		if !placed {
+			var pk packer = newMaxRects(packed, packed)
+			if o.Method == Skyline {
+				pk = newSkyline(packed, packed)
+			}
+			packers = append(packers, pk)
+			used = append(used, image.Point{})
+			// It fits an empty page, since it's not bigger than one
+			positions[i], _ = pk.insert(cells[i].X, cells[i].Y)
+			pages[i] = len(packers) - 1
+		}
+
+		// Padding on the right and bottom of the last image doesn't count
+		p := &used[pages[i]]
+		end := positions[i].Add(cells[i]).Sub(image.Pt(o.Padding, o.Padding))
+		if end.X > p.X {
+			p.X = end.X
+		}
+		if end.Y > p.Y {
+			p.Y = end.Y
+		}
+	}
+
+	a := &Atlas{Pages: make([]Page, len(packers))}
+	for i, u := range used {
+		w, h := nextPowerOfTwo(u.X), nextPowerOfTwo(u.Y)
+		a.Pages[i] = Page{Image: image.NewRGBA(image.Rect(0, 0, w, h)), Width: w, Height: h}
+	}
+
+	for i, img := range b.images {
+		page := a.Pages[pages[i]]
+		at := positions[i].Add(image.Pt(o.Extrude, o.Extrude))
+		s := img.Bounds().Size()
+		draw.Draw(page.Image, image.Rectangle{Min: at, Max: at.Add(s)}, img, img.Bounds().Min, draw.Src)
+		extrude(page.Image, image.Rectangle{Min: at, Max: at.Add(s)}, o.Extrude)
+
+		fw, fh := float32(page.Width), float32(page.Height)
+		a.Regions = append(a.Regions, Region{
+			Name: b.names[i],
+			Page: pages[i],
+			X:    at.X, Y: at.Y, W: s.X, H: s.Y,
+			U0: float32(at.X) / fw, V0: float32(at.Y) / fh,
+			U1: float32(at.X+s.X) / fw, V1: float32(at.Y+s.Y) / fh,
+		})
+	}
+
+	return a, nil
+}
+
+// extrude copies edge pixels of r on img n pixels outwards.
+func extrude(img *image.RGBA, r image.Rectangle, n int) {
+	if n <= 0 || r.Empty() {
+		return
+	}
+	outer := r.Inset(-n).Intersect(img.Bounds())
+	for y := outer.Min.Y; y < outer.Max.Y; y++ {
+		sy := clamp(y, r.Min.Y, r.Max.Y-1)
+		for x := outer.Min.X; x < outer.Max.X; x++ {
+			if image.Pt(x, y).In(r) {
+				continue
+			}
+			sx := clamp(x, r.Min.X, r.Max.X-1)
+			d, s := img.PixOffset(x, y), img.PixOffset(sx, sy)
+			copy(img.Pix[d:d+4], img.Pix[s:s+4])
+		}
+	}
+}
+
+func clamp(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// WriteMetadata writes pages and regions as JSON. Images of pages aren't
+// written, see Save.
+func (a *Atlas) WriteMetadata(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(a); err != nil {
+		return fmt.Errorf("Failed to write atlas metadata: %v", err)
+	}
+	return nil
+}
+
+// ReadMetadata reads pages and regions written by WriteMetadata. Images of
+// pages are not loaded.
+func ReadMetadata(r io.Reader) (*Atlas, error) {
+	a := new(Atlas)
+	if err := json.NewDecoder(r).Decode(a); err != nil {
+		return nil, fmt.Errorf("Failed to read atlas metadata: %v", err)
+	}
+	return a, nil
+}
+
+// Save writes pages as PNG images next to the metadata file, named after it:
+// name-0.png, name-1.png and so on.
+func (a *Atlas) Save(file string) error {
+	dir := filepath.Dir(file)
+	base := filepath.Base(file)
+	base = base[:len(base)-len(filepath.Ext(base))]
+
+	for i := range a.Pages {
+		p := &a.Pages[i]
+		if p.Image == nil {
+			return fmt.Errorf("Failed to save atlas: page %d has no image", i)
+		}
+		p.File = fmt.Sprintf("%s-%d.png", base, i)
+		if err := writePNG(filepath.Join(dir, p.File), p.Image); err != nil {
+			return fmt.Errorf("Failed to save atlas page: %v", err)
+		}
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return fmt.Errorf("Failed to save atlas: %v", err)
+	}
+	if err := a.WriteMetadata(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func writePNG(file string, img image.Image) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Load reads an atlas saved with Save, with images of its pages.
+func Load(file string) (*Atlas, error) {
+	return LoadFS(os.DirFS(filepath.Dir(file)), filepath.Base(file))
+}
+
+// LoadFS reads an atlas saved with Save from a file system, for example one
+// embedded into the binary.
+func LoadFS(fsys fs.FS, name string) (*Atlas, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load atlas: %v", err)
+	}
+	defer f.Close()
+
+	a, err := ReadMetadata(f)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pages are next to the metadata. Paths in fs.FS always use slashes.
+	dir := path.Dir(name)
+	for i := range a.Pages {
+		p := &a.Pages[i]
+		img, err := loadImage(fsys, path.Join(dir, p.File))
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load page %d of atlas: %v", i, err)
+		}
+		p.Image = toRGBA(img)
+	}
+	return a, nil
+}
+
+func loadImage(fsys fs.FS, name string) (image.Image, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	return img, err
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Src)
+	return rgba
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package atlas
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func filled(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+func TestPack(t *testing.T) {
+	for _, method := range []Method{MaxRects, Skyline} {
+		rnd := rand.New(rand.NewSource(1))
+		b := NewBuilder(Options{MaxPageSize: 256, Padding: 2, Extrude: 1, Method: method})
+		for i := 0; i < 200; i++ {
+			b.Add(fmt.Sprint(i), filled(4+rnd.Intn(40), 4+rnd.Intn(40), color.RGBA{A: 255}))
+		}
+
+		a, err := b.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(a.Regions) != 200 {
+			t.Fatalf("Method %d: got %d regions, want 200", method, len(a.Regions))
+		}
+		for _, p := range a.Pages {
+			if p.Width > 256 || p.Height > 256 || p.Width&(p.Width-1) != 0 || p.Height&(p.Height-1) != 0 {
+				t.Errorf("Method %d: page is %dx%d, want powers of two up to 256", method, p.Width, p.Height)
+			}
+		}
+
+		// Images with their extruded edges and padding don't overlap
+		for i, r := range a.Regions {
+			ri := image.Rect(r.X-1, r.Y-1, r.X+r.W+1, r.Y+r.H+1)
+			page := a.Pages[r.Page]
+			if !ri.In(image.Rect(0, 0, page.Width, page.Height)) {
+				t.Errorf("Method %d: region %v is outside of its page", method, ri)
+			}
+			for _, o := range a.Regions[i+1:] {
+				ro := image.Rect(o.X-1, o.Y-1, o.X+o.W+1, o.Y+o.H+1)
+				if r.Page == o.Page && ri.Inset(-1).Overlaps(ro) {
+					t.Errorf("Method %d: regions %v and %v are too close", method, ri, ro)
+				}
+			}
+		}
+	}
+}
+
+// An image as big as a page fits it, though it has padding.
+func TestPackFullPage(t *testing.T) {
+	for _, method := range []Method{MaxRects, Skyline} {
+		b := NewBuilder(Options{MaxPageSize: 64, Padding: 2, Extrude: 1, Method: method})
+		b.Add("full", filled(62, 62, color.RGBA{A: 255}))
+		b.Add("small", filled(10, 10, color.RGBA{A: 255}))
+
+		a, err := b.Build()
+		if err != nil {
+			t.Fatalf("Method %d: %v", method, err)
+		}
+		if len(a.Pages) != 2 || a.Pages[0].Width != 64 || a.Pages[0].Height != 64 {
+			t.Errorf("Method %d: got %d pages, the first is %dx%d, want 2 pages, 64x64", method, len(a.Pages), a.Pages[0].Width, a.Pages[0].Height)
+		}
+	}
+}
+
+func TestExtrudeAndUV(t *testing.T) {
+	b := NewBuilder(Options{Extrude: 2})
+	b.Add("red", filled(3, 2, color.RGBA{255, 0, 0, 255}))
+	a, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, ok := a.Region("red")
+	if !ok {
+		t.Fatal("Region wasn't found")
+	}
+	p := a.Pages[0]
+	if p.Width != 8 || p.Height != 8 {
+		t.Errorf("Page is %dx%d, want 8x8", p.Width, p.Height)
+	}
+	if r.X != 2 || r.Y != 2 || r.U0 != 0.25 || r.V1 != 0.5 {
+		t.Errorf("Region is %+v, want it at (2, 2) with UVs from 0.25 to 0.5", r)
+	}
+	// Corners are extruded too
+	if c := p.Image.RGBAAt(0, 0); c.R != 255 {
+		t.Errorf("Extruded pixel is %v, want red", c)
+	}
+	if c := p.Image.RGBAAt(7, 7); c.A != 0 {
+		t.Errorf("Pixel outside of the image is %v, want transparent", c)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "atlas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	b := NewBuilder(Options{MaxPageSize: 16})
+	b.Add("a", filled(16, 16, color.RGBA{0, 255, 0, 255}))
+	b.Add("b", filled(8, 8, color.RGBA{0, 0, 255, 255}))
+	a, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Pages) != 2 {
+		t.Fatalf("Got %d pages, want 2", len(a.Pages))
+	}
+
+	file := filepath.Join(dir, "sprites.json")
+	if err := a.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, ok := loaded.Region("b")
+	if !ok || r.Page != 1 {
+		t.Fatalf("Region b is %+v, want it on the second page", r)
+	}
+	if c := loaded.Pages[1].Image.RGBAAt(r.X, r.Y); c.B != 255 {
+		t.Errorf("Pixel of region b is %v, want blue", c)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package atlas
+
+import (
+	"image"
+)
+
+// packer places rects of the given size on a page, never overlapping.
+type packer interface {
+	insert(w, h int) (image.Point, bool)
+}
+
+// maxRects keeps the list of all the maximal free rects of a page. A new rect
+// goes to the free rect it fits best: the one leaving the shortest side left.
+// It packs tighter than skyline, but is slower with many images.
+type maxRects struct {
+	free []image.Rectangle
+}
+
+func newMaxRects(w, h int) *maxRects {
+	return &maxRects{free: []image.Rectangle{image.Rect(0, 0, w, h)}}
+}
+
+func (m *maxRects) insert(w, h int) (image.Point, bool) {
+	best, bestShort, bestLong := -1, 0, 0
+	for i, f := range m.free {
+		fw, fh := f.Dx(), f.Dy()
+		if w > fw || h > fh {
+			continue
+		}
+		short, long := fw-w, fh-h
+		if short > long {
+			short, long = long, short
+		}
+		if best < 0 || short < bestShort || (short == bestShort && long < bestLong) {
+			best, bestShort, bestLong = i, short, long
+		}
+	}
+	if best < 0 {
+		return image.Point{}, false
+	}
+
+	placed := image.Rectangle{Min: m.free[best].Min, Max: m.free[best].Min.Add(image.Pt(w, h))}
+
+	// Every free rect overlapping the placed one is split into up to four
+	// maximal rects around it
+	var free []image.Rectangle
+	for _, f := range m.free {
+		if !f.Overlaps(placed) {
+			free = append(free, f)
+			continue
+		}
+		if placed.Min.X > f.Min.X {
+			free = append(free, image.Rect(f.Min.X, f.Min.Y, placed.Min.X, f.Max.Y))
+		}
+		if placed.Max.X < f.Max.X {
+			free = append(free, image.Rect(placed.Max.X, f.Min.Y, f.Max.X, f.Max.Y))
+		}
+		if placed.Min.Y > f.Min.Y {
+			free = append(free, image.Rect(f.Min.X, f.Min.Y, f.Max.X, placed.Min.Y))
+		}
+		if placed.Max.Y < f.Max.Y {
+			free = append(free, image.Rect(f.Min.X, placed.Max.Y, f.Max.X, f.Max.Y))
+		}
+	}
+
+	// Free rects inside of other ones are useless
+	m.free = m.free[:0]
+	for i, f := range free {
+		contained := false
+		for j, o := range free {
+			if i != j && f.In(o) && (f != o || j < i) {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			m.free = append(m.free, f)
+		}
+	}
+
+	return placed.Min, true
+}
+
+// skylineNode is a horizontal segment of the skyline.
+type skylineNode struct {
+	x, y, w int
+}
+
+// skyline keeps only the top edge of what's packed, like a city skyline, and
+// places rects as low as possible on it. Space under overhangs is lost, but
+// it's fast and packs images of similar heights well.
+type skyline struct {
+	w, h  int
+	nodes []skylineNode
+}
+
+func newSkyline(w, h int) *skyline {
+	return &skyline{w: w, h: h, nodes: []skylineNode{{0, 0, w}}}
+}
+
+// fit returns how high a rect of width w would be placed, if its left edge is
+// at the node i, or -1 if it doesn't fit there.
+func (s *skyline) fit(i, w, h int) int {
+	x := s.nodes[i].x
+	if x+w > s.w {
+		return -1
+	}
+	y := 0
+	for left := w; left > 0; i++ {
+		if s.nodes[i].y > y {
+			y = s.nodes[i].y
+		}
+		left -= s.nodes[i].w
+	}
+	if y+h > s.h {
+		return -1
+	}
+	return y
+}
+
+func (s *skyline) insert(w, h int) (image.Point, bool) {
+	best, bestY, bestW := -1, 0, 0
+	for i := range s.nodes {
+		y := s.fit(i, w, h)
+		if y < 0 {
+			continue
+		}
+		if best < 0 || y < bestY || (y == bestY && s.nodes[i].w < bestW) {
+			best, bestY, bestW = i, y, s.nodes[i].w
+		}
+	}
+	if best < 0 {
+		return image.Point{}, false
+	}
+
+	pos := image.Pt(s.nodes[best].x, bestY)
+
+	// The new node covers nodes under the rect, fully or partially
+	nodes := append([]skylineNode(nil), s.nodes[:best]...)
+	nodes = append(nodes, skylineNode{pos.X, bestY + h, w})
+	right := pos.X + w
+	for _, n := range s.nodes[best:] {
+		if n.x+n.w <= right {
+			continue
+		}
+		if n.x < right {
+			n.w -= right - n.x
+			n.x = right
+		}
+		nodes = append(nodes, n)
+	}
+
+	// Neighbours of the same height are merged
+	s.nodes = nodes[:1]
+	for _, n := range nodes[1:] {
+		last := &s.nodes[len(s.nodes)-1]
+		if last.y == n.y {
+			last.w += n.w
+		} else {
+			s.nodes = append(s.nodes, n)
+		}
+	}
+
+	return pos, true
+}
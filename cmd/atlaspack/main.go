@@ -0,0 +1,57 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+// Command atlaspack packs images into texture atlas pages at build time. Pages
+// are written as PNG images next to the JSON metadata, which atlas.Load and
+// gl33.LoadAtlas read. For example, with go:generate:
+//
+//	//go:generate atlaspack -o assets/sprites.json -padding 2 sprites/*.png
+//
+// Images are named with their paths as given on the command line.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/Sergobot/Rocky/atlas"
+)
+
+func main() {
+	out := flag.String("o", "atlas.json", "metadata file, pages are written next to it")
+	size := flag.Int("size", atlas.DefaultPageSize, "maximum page size")
+	padding := flag.Int("padding", 0, "transparent pixels between images")
+	extrude := flag.Int("extrude", 0, "pixels to extrude image edges by")
+	skyline := flag.Bool("skyline", false, "use skyline packing instead of MaxRects")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: atlaspack [flags] images...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	o := atlas.Options{MaxPageSize: *size, Padding: *padding, Extrude: *extrude}
+	if *skyline {
+		o.Method = atlas.Skyline
+	}
+	b := atlas.NewBuilder(o)
+	for _, file := range flag.Args() {
+		if err := b.AddFile(file); err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	a, err := b.Build()
+	if err != nil {
+		log.Fatalln("Failed to pack images:", err)
+	}
+	if err := a.Save(*out); err != nil {
+		log.Fatalln(err)
+	}
+	log.Printf("Packed %d images into %d pages", len(a.Regions), len(a.Pages))
+}
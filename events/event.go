@@ -0,0 +1,107 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package events
+
+import g "github.com/Sergobot/Rocky/geometry"
+
+// Type variables tell what kind of an event happened.
+type Type int
+
+// Supported event types
+const (
+	// MouseMove is sent when the cursor moves.
+	MouseMove Type = iota
+	// MousePress and MouseRelease are sent when a mouse button is pressed or
+	// released. Event's Button tells which one.
+	MousePress   Type = iota
+	MouseRelease Type = iota
+	// Scroll is sent when mouse wheel or touchpad is scrolled. Event's Scroll
+	// holds the offset.
+	Scroll Type = iota
+	// KeyPress, KeyRepeat and KeyRelease are sent when a key is pressed, held
+	// down or released. Event's Key tells which one.
+	KeyPress   Type = iota
+	KeyRepeat  Type = iota
+	KeyRelease Type = iota
+	// Char is sent when user types a character. Use it for text input instead of
+	// KeyPress, since it respects keyboard layout. Event's Char holds the rune.
+	Char Type = iota
+	// FocusIn and FocusOut are sent to a widget when it gains or loses keyboard
+	// focus. They are not propagated to parents.
+	FocusIn  Type = iota
+	FocusOut Type = iota
+	// DragEnter, DragMove, DragLeave and Drop are sent during drag and drop.
+	// A widget accepts DragEnter to become the drop target, then it gets
+	// DragMove every time the cursor moves and accepts it, if the drop is
+	// possible at that point. Finally, it gets either Drop or DragLeave.
+	// Event's Payload holds the dragged data.
+	DragEnter Type = iota
+	DragMove  Type = iota
+	DragLeave Type = iota
+	Drop      Type = iota
+)
+
+// Event holds information about something that happened to a window: user
+// moved the mouse, pressed a key and so on. Events are delivered to widgets,
+// which may accept them to stop further propagation.
+type Event struct {
+	Type Type
+
+	// Pos is cursor position in window's normalized coordinates (look at
+	// gl33.NormalizedViewportSize to learn more). It's set for all the events.
+	Pos g.PointF
+
+	// Mouse button, for MousePress and MouseRelease
+	Button MouseButton
+
+	// Keyboard key, for KeyPress, KeyRepeat and KeyRelease
+	Key Key
+
+	// Modifier keys held down while the event happened
+	Mods Modifier
+
+	// Scroll offset, for Scroll
+	Scroll g.PointF
+
+	// Typed character, for Char
+	Char rune
+
+	// Dragged data, for drag and drop events. Use type assertion to check if
+	// it's something a widget can accept.
+	Payload interface{}
+
+	accepted bool
+}
+
+// Accept marks the event as handled, so it's not propagated any further.
+func (e *Event) Accept() {
+	e.accepted = true
+}
+
+// Ignore clears accepted flag, so the event is propagated to the next widget.
+func (e *Event) Ignore() {
+	e.accepted = false
+}
+
+// Accepted returns true if some widget has already handled the event.
+func (e *Event) Accepted() bool {
+	return e.accepted
+}
+
+// IsMouse returns true for events, which are sent to a widget under the cursor.
+func (e *Event) IsMouse() bool {
+	switch e.Type {
+	case MouseMove, MousePress, MouseRelease, Scroll:
+		return true
+	}
+	return false
+}
+
+// IsKey returns true for events, which are sent to a focused widget.
+func (e *Event) IsKey() bool {
+	switch e.Type {
+	case KeyPress, KeyRepeat, KeyRelease, Char:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package events
+
+// MouseButton variables tell which mouse button was pressed or released.
+type MouseButton int
+
+// Mouse buttons. Values are the same as in GLFW.
+const (
+	MouseLeft   MouseButton = 0
+	MouseRight  MouseButton = 1
+	MouseMiddle MouseButton = 2
+)
+
+// Modifier is a bit set of modifier keys held down.
+type Modifier int
+
+// Modifier keys. Values are the same as in GLFW.
+const (
+	ModShift   Modifier = 0x1
+	ModControl Modifier = 0x2
+	ModAlt     Modifier = 0x4
+	ModSuper   Modifier = 0x8
+)
+
+// Key is a keyboard key. Its values are the same as in GLFW, so keys that don't
+// have a named constant here are still delivered and may be compared to
+// printable ASCII characters, like Key('A') or Key('1').
+type Key int
+
+// Most used keys. Printable keys are equal to their uppercase ASCII codes.
+const (
+	KeyUnknown   Key = -1
+	KeySpace     Key = 32
+	KeyEscape    Key = 256
+	KeyEnter     Key = 257
+	KeyTab       Key = 258
+	KeyBackspace Key = 259
+	KeyInsert    Key = 260
+	KeyDelete    Key = 261
+	KeyRight     Key = 262
+	KeyLeft      Key = 263
+	KeyDown      Key = 264
+	KeyUp        Key = 265
+	KeyPageUp    Key = 266
+	KeyPageDown  Key = 267
+	KeyHome      Key = 268
+	KeyEnd       Key = 269
+	KeyF1        Key = 290
+	KeyF2        Key = 291
+	KeyF3        Key = 292
+	KeyF4        Key = 293
+	KeyF5        Key = 294
+	KeyF6        Key = 295
+	KeyF7        Key = 296
+	KeyF8        Key = 297
+	KeyF9        Key = 298
+	KeyF10       Key = 299
+	KeyF11       Key = 300
+	KeyF12       Key = 301
+	KeyKPEnter   Key = 335
+)
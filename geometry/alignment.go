@@ -0,0 +1,43 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package geometry
+
+// Alignment describes where a smaller object is placed inside a bigger one.
+// Horizontal and vertical flags can be combined with bitwise OR, for example
+// AlignLeft | AlignBottom. If there is no flag for some axis, object is
+// aligned to the left (top) edge.
+type Alignment int
+
+// Alignment flags. AlignCenter is just a shortcut for both centering flags.
+const (
+	AlignLeft Alignment = 1 << iota
+	AlignRight
+	AlignHCenter
+	AlignTop
+	AlignBottom
+	AlignVCenter
+
+	AlignCenter = AlignHCenter | AlignVCenter
+)
+
+// Align returns a rect of size s placed inside r according to the alignment.
+// s may be bigger than r, then the returned rect just sticks out of r.
+func (a Alignment) Align(s SizeF, r RectF) RectF {
+	res := RectF{PosF: r.PosF, SizeF: s}
+
+	switch {
+	case a&AlignRight != 0:
+		res.X = r.X + r.W - s.W
+	case a&AlignHCenter != 0:
+		res.X = r.X + (r.W-s.W)/2
+	}
+
+	switch {
+	case a&AlignBottom != 0:
+		res.Y = r.Y + r.H - s.H
+	case a&AlignVCenter != 0:
+		res.Y = r.Y + (r.H-s.H)/2
+	}
+
+	return res
+}
@@ -0,0 +1,29 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package geometry
+
+// InsetsF are distances from each edge of a rect inwards, like paddings of a
+// widget or borders of a nine-patch image.
+type InsetsF struct {
+	Left, Top, Right, Bottom float32
+}
+
+// UniformInsets returns insets with the same distance from every edge.
+func UniformInsets(d float32) InsetsF {
+	return InsetsF{d, d, d, d}
+}
+
+// Shrink returns r with insets cut off. Size never gets negative.
+func (in InsetsF) Shrink(r RectF) RectF {
+	r.X += in.Left
+	r.Y += in.Top
+	r.W -= in.Left + in.Right
+	r.H -= in.Top + in.Bottom
+	if r.W < 0 {
+		r.W = 0
+	}
+	if r.H < 0 {
+		r.H = 0
+	}
+	return r
+}
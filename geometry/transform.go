@@ -0,0 +1,79 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package geometry
+
+import "math"
+
+// Transform is a 2D affine transformation. A point (x, y) becomes
+// (A*x + C*y + E, B*x + D*y + F). The zero value is not valid, start with
+// Identity.
+type Transform struct {
+	A, B, C, D, E, F float32
+}
+
+// Identity returns a transform, which leaves points where they are.
+func Identity() Transform {
+	return Transform{A: 1, D: 1}
+}
+
+// Translation returns a transform moving points by (x, y).
+func Translation(x, y float32) Transform {
+	return Transform{A: 1, D: 1, E: x, F: y}
+}
+
+// Scaling returns a transform scaling points by sx and sy around the origin.
+func Scaling(sx, sy float32) Transform {
+	return Transform{A: sx, D: sy}
+}
+
+// Rotation returns a transform rotating points by angle (in radians) around
+// pivot. Y axis goes down, so positive angles rotate clockwise on the screen.
+func Rotation(angle float32, pivot PointF) Transform {
+	sin, cos := math.Sincos(float64(angle))
+	s, c := float32(sin), float32(cos)
+	return Transform{
+		A: c, B: s,
+		C: -s, D: c,
+		E: pivot.X - c*pivot.X + s*pivot.Y,
+		F: pivot.Y - s*pivot.X - c*pivot.Y,
+	}
+}
+
+// Mul returns a transform applying o first and then t.
+func (t Transform) Mul(o Transform) Transform {
+	return Transform{
+		A: t.A*o.A + t.C*o.B,
+		B: t.B*o.A + t.D*o.B,
+		C: t.A*o.C + t.C*o.D,
+		D: t.B*o.C + t.D*o.D,
+		E: t.A*o.E + t.C*o.F + t.E,
+		F: t.B*o.E + t.D*o.F + t.F,
+	}
+}
+
+// Apply transforms a point.
+func (t Transform) Apply(p PointF) PointF {
+	return PointF{
+		X: t.A*p.X + t.C*p.Y + t.E,
+		Y: t.B*p.X + t.D*p.Y + t.F,
+	}
+}
+
+// Invert returns the inverse transform. Transforms squashing everything into
+// a line or a point have no inverse, Identity is returned for them.
+func (t Transform) Invert() Transform {
+	det := t.A*t.D - t.B*t.C
+	if det == 0 {
+		return Identity()
+	}
+
+	inv := Transform{A: t.D / det, B: -t.B / det, C: -t.C / det, D: t.A / det}
+	inv.E = -(inv.A*t.E + inv.C*t.F)
+	inv.F = -(inv.B*t.E + inv.D*t.F)
+	return inv
+}
+
+// IsIdentity returns true if the transform leaves points where they are.
+func (t Transform) IsIdentity() bool {
+	return t == Identity()
+}
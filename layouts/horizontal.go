@@ -23,7 +23,8 @@ type Horizontal struct {
 func (hbl *Horizontal) Activate() {
 	var widgetRect g.RectF
 
-	count := len(hbl.Widgets())
+	arranged := hbl.Arranged()
+	count := len(arranged)
 	width := hbl.Geometry().W / float32(count)
 
 	widgetRect.W = width
@@ -31,7 +32,7 @@ func (hbl *Horizontal) Activate() {
 	widgetRect.X = hbl.Geometry().X
 	widgetRect.Y = hbl.Geometry().Y
 
-	for _, v := range hbl.Widgets() {
+	for _, v := range arranged {
 		v.SetGeometry(widgetRect)
 		widgetRect.X += width
 	}
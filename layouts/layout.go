@@ -30,6 +30,19 @@ type Layout interface {
 	// Returns slice of all the widgets attached to a layout. May be required when
 	// drawing.
 	Widgets() []widgets.Widget
+
+	// Owner is the widget holding a layout, usually a container. It's set by
+	// the owner itself.
+	SetOwner(Owner)
+	Owner() Owner
+}
+
+// Owner is a widget holding a layout. Layouts tell it about widgets added and
+// removed, so that they become its children and are drawn, no matter whether
+// they are added to the layout or to the owner.
+type Owner interface {
+	WidgetAdded(widgets.Widget)
+	WidgetRemoved(widgets.Widget)
 }
 
 // BasicLayout is a very basic layout struct, used to be embedded in other layouts.
@@ -38,12 +51,18 @@ type BasicLayout struct {
 	geometry g.RectF
 
 	widgets []widgets.Widget
+
+	// Widget holding the layout, if any
+	owner Owner
 }
 
 // AddWidget adds a widget to a layout.
 func (bl *BasicLayout) AddWidget(w widgets.Widget) {
 	w.GetReady()
 	bl.widgets = append(bl.Widgets(), w)
+	if bl.owner != nil {
+		bl.owner.WidgetAdded(w)
+	}
 
 	bl.Activate()
 }
@@ -60,6 +79,9 @@ func (bl *BasicLayout) RemoveWidget(w widgets.Widget) error {
 	if !removed {
 		return fmt.Errorf("Widget not found in layout")
 	}
+	if bl.owner != nil {
+		bl.owner.WidgetRemoved(w)
+	}
 
 	bl.Activate()
 
@@ -84,7 +106,31 @@ func (bl *BasicLayout) Activate() {
 	// a more specific one.
 }
 
+// SetOwner sets the widget holding a layout. Owners call it, when they get a
+// layout, and set nil, when they drop it.
+func (bl *BasicLayout) SetOwner(o Owner) {
+	bl.owner = o
+}
+
+// Owner returns the widget holding a layout or nil.
+func (bl *BasicLayout) Owner() Owner {
+	return bl.owner
+}
+
 // Widgets returns slice of widgets attached to a layout.
 func (bl *BasicLayout) Widgets() []widgets.Widget {
 	return bl.widgets
 }
+
+// Arranged returns widgets, which take space in a layout: visible ones and
+// hidden ones reserving their space (see Widget.SetReserveSpace). Layouts
+// share space between these widgets only.
+func (bl *BasicLayout) Arranged() []widgets.Widget {
+	var res []widgets.Widget
+	for _, w := range bl.widgets {
+		if w.Visible() || w.ReserveSpace() {
+			res = append(res, w)
+		}
+	}
+	return res
+}
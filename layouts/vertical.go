@@ -26,7 +26,8 @@ type Vertical struct {
 func (vbl *Vertical) Activate() {
 	var widgetRect g.RectF
 
-	count := len(vbl.Widgets())
+	arranged := vbl.Arranged()
+	count := len(arranged)
 	height := vbl.Geometry().H / float32(count)
 
 	widgetRect.W = vbl.Geometry().W
@@ -34,7 +35,7 @@ func (vbl *Vertical) Activate() {
 	widgetRect.X = vbl.Geometry().X
 	widgetRect.Y = vbl.Geometry().Y
 
-	for _, v := range vbl.Widgets() {
+	for _, v := range arranged {
 		v.SetGeometry(widgetRect)
 		widgetRect.Y += height
 	}
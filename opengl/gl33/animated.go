@@ -0,0 +1,124 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+	"io/fs"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+
+	"github.com/Sergobot/Rocky/animated"
+)
+
+// AnimatedTexture is a Texture showing a frame of an animated image, GIF or
+// APNG. Only the current frame is kept in video memory: frames are uploaded,
+// when they are switched.
+type AnimatedTexture struct {
+	Texture
+
+	img   *animated.Image
+	frame int
+}
+
+// LoadFromFile loads an animated image from a file. Images, which are not
+// animated, are loaded as a single frame.
+func (t *AnimatedTexture) LoadFromFile(file string) error {
+	img, err := animated.LoadFile(file)
+	if err != nil {
+		return fmt.Errorf("Failed to load texture from %q: %v", file, err)
+	}
+	if err := t.SetImage(img); err != nil {
+		return err
+	}
+	watchTexture(t, file)
+	return nil
+}
+
+// LoadFromFS loads an animated image from a file in a file system.
+func (t *AnimatedTexture) LoadFromFS(fsys fs.FS, path string) error {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return fmt.Errorf("Failed to load texture from %q: %v", path, err)
+	}
+	defer f.Close()
+
+	return t.LoadFromReader(f)
+}
+
+// LoadFromBytes loads an animated image from memory.
+func (t *AnimatedTexture) LoadFromBytes(data []byte) error {
+	return t.LoadFromReader(bytes.NewReader(data))
+}
+
+// LoadFromReader loads an animated image read from r.
+func (t *AnimatedTexture) LoadFromReader(r io.Reader) error {
+	img, err := animated.Decode(r)
+	if err != nil {
+		return err
+	}
+	return t.SetImage(img)
+}
+
+// LoadFromImage shows a still image as a single frame.
+func (t *AnimatedTexture) LoadFromImage(img image.Image) error {
+	if img == nil || img.Bounds().Empty() {
+		return fmt.Errorf("Failed to load texture from an empty image")
+	}
+	return t.SetImage(animated.Still(img))
+}
+
+// SetImage sets the animated image and shows its first frame.
+func (t *AnimatedTexture) SetImage(img *animated.Image) error {
+	if img == nil || len(img.Frames) == 0 {
+		return fmt.Errorf("Failed to set an animated image: no frames")
+	}
+	t.img, t.frame = img, 0
+	return t.loadImage(t, img.Frames[0].Image)
+}
+
+// Release deletes the OpenGL texture and drops the animated image.
+func (t *AnimatedTexture) Release() {
+	unwatchTexture(t)
+	t.release()
+	t.img, t.frame = nil, 0
+}
+
+// Image returns the animated image or nil.
+func (t *AnimatedTexture) Image() *animated.Image {
+	return t.img
+}
+
+// SetFrame shows a frame with the given index.
+func (t *AnimatedTexture) SetFrame(i int) error {
+	if t.img == nil || !t.ready {
+		return fmt.Errorf("Prevented setting a frame of an empty texture")
+	}
+	if i < 0 || i >= len(t.img.Frames) {
+		return fmt.Errorf("Failed to set frame %d: there are %d frames", i, len(t.img.Frames))
+	}
+	if i == t.frame {
+		return nil
+	}
+
+	// All the frames are of the same size, so the image is just replaced
+	rgba := t.img.Frames[i].Image
+	gl.BindTexture(gl.TEXTURE_2D, t.texture)
+	gl.TexSubImage2D(gl.TEXTURE_2D, 0, 0, 0, int32(t.width), int32(t.height),
+		gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(rgba.Pix))
+	if t.mipmapped {
+		gl.GenerateMipmap(gl.TEXTURE_2D)
+	}
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+
+	t.frame = i
+	return nil
+}
+
+// Frame returns index of the frame shown.
+func (t *AnimatedTexture) Frame() int {
+	return t.frame
+}
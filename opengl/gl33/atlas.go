@@ -0,0 +1,72 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"fmt"
+
+	"github.com/Sergobot/Rocky/atlas"
+)
+
+// Atlas is an atlas.Atlas with its pages uploaded as textures. Draw regions
+// of the same page together to bind its texture once.
+type Atlas struct {
+	*atlas.Atlas
+
+	textures []*Texture
+}
+
+// NewAtlas uploads pages of an atlas to textures with the given options.
+// Images of pages may be dropped afterwards to free memory.
+func NewAtlas(a *atlas.Atlas, o TextureOptions) (*Atlas, error) {
+	res := &Atlas{Atlas: a, textures: make([]*Texture, len(a.Pages))}
+	for i, p := range a.Pages {
+		if p.Image == nil {
+			res.Release()
+			return nil, fmt.Errorf("Failed to upload atlas: page %d has no image", i)
+		}
+		t := new(Texture)
+		t.SetOptions(o)
+		if err := t.LoadFromImage(p.Image); err != nil {
+			res.Release()
+			return nil, fmt.Errorf("Failed to upload page %d of atlas: %v", i, err)
+		}
+		res.textures[i] = t
+	}
+	return res, nil
+}
+
+// LoadAtlas loads an atlas saved with atlas.Save and uploads its pages.
+func LoadAtlas(file string, o TextureOptions) (*Atlas, error) {
+	a, err := atlas.Load(file)
+	if err != nil {
+		return nil, err
+	}
+	return NewAtlas(a, o)
+}
+
+// Page returns the texture of a page.
+func (a *Atlas) Page(i int) *Texture {
+	if i < 0 || i >= len(a.textures) {
+		return nil
+	}
+	return a.textures[i]
+}
+
+// Release deletes textures of the pages.
+func (a *Atlas) Release() {
+	for _, t := range a.textures {
+		if t != nil {
+			t.Release()
+		}
+	}
+}
+
+// Texture returns a region with the given name and the texture of its page.
+func (a *Atlas) Texture(name string) (*Texture, atlas.Region, bool) {
+	r, ok := a.Region(name)
+	if !ok {
+		return nil, r, false
+	}
+	return a.Page(r.Page), r, true
+}
@@ -0,0 +1,251 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// Variable is an active attribute or uniform of a shader program.
+type Variable struct {
+	// Name of the variable. Arrays are named without "[0]" OpenGL appends.
+	Name string
+	// Type is an OpenGL type, like gl.FLOAT_VEC2, see TypeName
+	Type uint32
+	// Size is the number of elements of an array, 1 for other variables
+	Size int32
+	// Location of the variable, -1 for uniforms in uniform blocks
+	Location int32
+	// Block is index of the uniform block a uniform is in, or -1
+	Block int32
+}
+
+// UniformBlock is an active uniform block of a shader program.
+type UniformBlock struct {
+	Name  string
+	Index uint32
+	// Binding point of the block
+	Binding uint32
+	// DataSize is the size of a buffer backing the block in bytes
+	DataSize int32
+}
+
+// typeInfo is a name of a GLSL type and the number of floats (or ints) in it.
+type typeInfo struct {
+	name       string
+	components int32
+	// Attributes of float types are set with glVertexAttribPointer
+	float bool
+}
+
+var types = map[uint32]typeInfo{
+	gl.FLOAT:             {"float", 1, true},
+	gl.FLOAT_VEC2:        {"vec2", 2, true},
+	gl.FLOAT_VEC3:        {"vec3", 3, true},
+	gl.FLOAT_VEC4:        {"vec4", 4, true},
+	gl.FLOAT_MAT2:        {"mat2", 4, true},
+	gl.FLOAT_MAT3:        {"mat3", 9, true},
+	gl.FLOAT_MAT4:        {"mat4", 16, true},
+	gl.INT:               {"int", 1, false},
+	gl.INT_VEC2:          {"ivec2", 2, false},
+	gl.INT_VEC3:          {"ivec3", 3, false},
+	gl.INT_VEC4:          {"ivec4", 4, false},
+	gl.UNSIGNED_INT:      {"uint", 1, false},
+	gl.UNSIGNED_INT_VEC2: {"uvec2", 2, false},
+	gl.UNSIGNED_INT_VEC3: {"uvec3", 3, false},
+	gl.UNSIGNED_INT_VEC4: {"uvec4", 4, false},
+	gl.BOOL:              {"bool", 1, false},
+	gl.BOOL_VEC2:         {"bvec2", 2, false},
+	gl.BOOL_VEC3:         {"bvec3", 3, false},
+	gl.BOOL_VEC4:         {"bvec4", 4, false},
+	gl.SAMPLER_1D:        {"sampler1D", 1, false},
+	gl.SAMPLER_2D:        {"sampler2D", 1, false},
+	gl.SAMPLER_3D:        {"sampler3D", 1, false},
+	gl.SAMPLER_CUBE:      {"samplerCube", 1, false},
+	gl.SAMPLER_2D_ARRAY:  {"sampler2DArray", 1, false},
+	gl.SAMPLER_2D_SHADOW: {"sampler2DShadow", 1, false},
+}
+
+// TypeName returns GLSL name of an OpenGL type, like "vec2" for
+// gl.FLOAT_VEC2.
+func TypeName(t uint32) string {
+	if info, ok := types[t]; ok {
+		return info.name
+	}
+	return fmt.Sprintf("type %#x", t)
+}
+
+// introspect queries active attributes, uniforms and uniform blocks of a
+// linked program.
+func (sp *ShaderProgram) introspect() {
+	p := sp.program
+	sp.attributes, sp.activeUniforms, sp.blocks = nil, nil, nil
+
+	var n, maxLen int32
+	gl.GetProgramiv(p, gl.ACTIVE_ATTRIBUTES, &n)
+	gl.GetProgramiv(p, gl.ACTIVE_ATTRIBUTE_MAX_LENGTH, &maxLen)
+	buf := make([]uint8, maxLen+1)
+	for i := uint32(0); i < uint32(n); i++ {
+		var length, size int32
+		var t uint32
+		gl.GetActiveAttrib(p, i, int32(len(buf)), &length, &size, &t, &buf[0])
+		name := string(buf[:length])
+		v := Variable{Name: arrayName(name), Type: t, Size: size, Block: -1}
+		v.Location = gl.GetAttribLocation(p, gl.Str(name+"\x00"))
+		sp.attributes = append(sp.attributes, v)
+	}
+
+	gl.GetProgramiv(p, gl.ACTIVE_UNIFORMS, &n)
+	gl.GetProgramiv(p, gl.ACTIVE_UNIFORM_MAX_LENGTH, &maxLen)
+	buf = make([]uint8, maxLen+1)
+	for i := uint32(0); i < uint32(n); i++ {
+		var length, size int32
+		var t uint32
+		gl.GetActiveUniform(p, i, int32(len(buf)), &length, &size, &t, &buf[0])
+		name := string(buf[:length])
+		v := Variable{Name: arrayName(name), Type: t, Size: size}
+		v.Location = gl.GetUniformLocation(p, gl.Str(name+"\x00"))
+		index := i
+		gl.GetActiveUniformsiv(p, 1, &index, gl.UNIFORM_BLOCK_INDEX, &v.Block)
+		sp.activeUniforms = append(sp.activeUniforms, v)
+	}
+
+	gl.GetProgramiv(p, gl.ACTIVE_UNIFORM_BLOCKS, &n)
+	gl.GetProgramiv(p, gl.ACTIVE_UNIFORM_BLOCK_MAX_NAME_LENGTH, &maxLen)
+	buf = make([]uint8, maxLen+1)
+	for i := uint32(0); i < uint32(n); i++ {
+		var length, binding int32
+		gl.GetActiveUniformBlockName(p, i, int32(len(buf)), &length, &buf[0])
+		b := UniformBlock{Name: string(buf[:length]), Index: i}
+		gl.GetActiveUniformBlockiv(p, i, gl.UNIFORM_BLOCK_BINDING, &binding)
+		gl.GetActiveUniformBlockiv(p, i, gl.UNIFORM_BLOCK_DATA_SIZE, &b.DataSize)
+		b.Binding = uint32(binding)
+		sp.blocks = append(sp.blocks, b)
+	}
+
+	sortVariables(sp.attributes)
+	sortVariables(sp.activeUniforms)
+}
+
+// arrayName strips "[0]" OpenGL appends to names of arrays.
+func arrayName(name string) string {
+	return strings.TrimSuffix(name, "[0]")
+}
+
+type byName []Variable
+
+func (v byName) Len() int           { return len(v) }
+func (v byName) Swap(i, j int)      { v[i], v[j] = v[j], v[i] }
+func (v byName) Less(i, j int) bool { return v[i].Name < v[j].Name }
+
+func sortVariables(v []Variable) {
+	sort.Sort(byName(v))
+}
+
+// Attributes returns active vertex attributes of a linked program, sorted by
+// name. Attributes not used by shaders are optimized out and are not active.
+func (sp *ShaderProgram) Attributes() []Variable {
+	return sp.attributes
+}
+
+// Uniforms returns active uniforms of a linked program, sorted by name.
+func (sp *ShaderProgram) Uniforms() []Variable {
+	return sp.activeUniforms
+}
+
+// UniformBlocks returns active uniform blocks of a linked program.
+func (sp *ShaderProgram) UniformBlocks() []UniformBlock {
+	return sp.blocks
+}
+
+// Attribute returns an active attribute with the given name.
+func (sp *ShaderProgram) Attribute(name string) (Variable, bool) {
+	return findVariable(sp.attributes, name)
+}
+
+func findVariable(vars []Variable, name string) (Variable, bool) {
+	i := sort.Search(len(vars), func(i int) bool { return vars[i].Name >= name })
+	if i < len(vars) && vars[i].Name == name {
+		return vars[i], true
+	}
+	return Variable{}, false
+}
+
+// VertexAttrib describes a vertex attribute in a buffer of floats.
+type VertexAttrib struct {
+	Name string
+	// Size is the number of floats in the attribute, from 1 to 4
+	Size int32
+	// Offset of the attribute from the start of a vertex in floats
+	Offset int
+	// Divisor makes the attribute advance once per Divisor instances, when
+	// drawing with instancing. 0 advances it every vertex.
+	Divisor uint32
+}
+
+// VertexLayout describes vertices in a buffer of floats.
+type VertexLayout struct {
+	// Stride is the number of floats in a vertex
+	Stride  int
+	Attribs []VertexAttrib
+}
+
+// checkLayout returns an error, if a layout doesn't suit attributes.
+func checkLayout(l VertexLayout, attributes []Variable) error {
+	names := make([]string, len(attributes))
+	for i, a := range attributes {
+		names[i] = a.Name
+	}
+
+	for _, a := range l.Attribs {
+		v, ok := findVariable(attributes, a.Name)
+		if !ok {
+			return fmt.Errorf("There is no active attribute %q, active ones are: %s", a.Name, strings.Join(names, ", "))
+		}
+		info, known := types[v.Type]
+		if !known || !info.float || info.components > 4 {
+			return fmt.Errorf("Attribute %q is %s, only float, vec2, vec3 and vec4 are supported", a.Name, TypeName(v.Type))
+		}
+		if a.Size < 1 || a.Size > info.components {
+			return fmt.Errorf("Attribute %q is %s, but %d floats are given for it", a.Name, info.name, a.Size)
+		}
+		if a.Offset < 0 || a.Offset+int(a.Size) > l.Stride {
+			return fmt.Errorf("Attribute %q at offset %d doesn't fit in a vertex of %d floats", a.Name, a.Offset, l.Stride)
+		}
+	}
+	return nil
+}
+
+// CheckLayout returns an error, if vertices in a layout can't be passed to the
+// program: some attributes are not active in the program (misspelled or
+// unused by shaders), their types don't match or they don't fit in a vertex.
+func (sp *ShaderProgram) CheckLayout(l VertexLayout) error {
+	if !sp.linked {
+		return fmt.Errorf("Shader program isn't linked")
+	}
+	return checkLayout(l, sp.attributes)
+}
+
+// SetupLayout checks a layout and sets vertex attributes of the vertex array
+// bound to read vertices from the array buffer bound. Nothing is set, if the
+// layout doesn't suit the program.
+func (sp *ShaderProgram) SetupLayout(l VertexLayout) error {
+	if err := sp.CheckLayout(l); err != nil {
+		return err
+	}
+
+	for _, a := range l.Attribs {
+		v, _ := sp.Attribute(a.Name)
+		loc := uint32(v.Location)
+		gl.EnableVertexAttribArray(loc)
+		gl.VertexAttribPointer(loc, a.Size, gl.FLOAT, false, int32(l.Stride*4), gl.PtrOffset(a.Offset*4))
+		if a.Divisor != 0 {
+			gl.VertexAttribDivisor(loc, a.Divisor)
+		}
+	}
+	return nil
+}
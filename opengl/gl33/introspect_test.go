@@ -0,0 +1,50 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+func TestCheckLayout(t *testing.T) {
+	attributes := []Variable{
+		{Name: "arr", Type: gl.FLOAT_VEC2, Size: 4},
+		{Name: "ids", Type: gl.INT, Size: 1},
+		{Name: "vert", Type: gl.FLOAT_VEC3, Size: 1},
+		{Name: "vertTexCoord", Type: gl.FLOAT_VEC2, Size: 1},
+	}
+
+	tests := []struct {
+		attribs []VertexAttrib
+		err     string
+	}{
+		{[]VertexAttrib{{Name: "vert", Size: 3}, {Name: "vertTexCoord", Size: 2, Offset: 3}}, ""},
+		// Missing components are filled by OpenGL
+		{[]VertexAttrib{{Name: "vert", Size: 2}}, ""},
+		{[]VertexAttrib{{Name: "vertTexCoords", Size: 2}}, "active ones are: arr, ids, vert, vertTexCoord"},
+		{[]VertexAttrib{{Name: "vertTexCoord", Size: 3}}, "is vec2, but 3 floats"},
+		{[]VertexAttrib{{Name: "ids", Size: 1}}, "is int"},
+		{[]VertexAttrib{{Name: "vert", Size: 3, Offset: 3}}, "doesn't fit"},
+	}
+	for _, test := range tests {
+		err := checkLayout(VertexLayout{Stride: 5, Attribs: test.attribs}, attributes)
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("Layout %+v: unexpected error %v", test.attribs, err)
+		case test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)):
+			t.Errorf("Layout %+v: error is %v, want one containing %q", test.attribs, err, test.err)
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	if n := arrayName("lights[0]"); n != "lights" {
+		t.Errorf("arrayName(\"lights[0]\") = %q, want \"lights\"", n)
+	}
+	if n := TypeName(gl.FLOAT_MAT4); n != "mat4" {
+		t.Errorf("TypeName(FLOAT_MAT4) = %q, want \"mat4\"", n)
+	}
+}
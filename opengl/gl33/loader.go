@@ -0,0 +1,219 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ShaderLoader loads shader programs from files, see Preprocess. Programs are
+// cached, so each variant, that is a set of defines, is compiled only once:
+//
+//	sp, err := loader.Program("sprite.vert", "sprite.frag", "WITH_TINT")
+//
+// With hot reload on, see EnableHotReload, programs are relinked when their
+// files change. The same *ShaderProgram is kept, so there's no need to ask for
+// it again.
+type ShaderLoader struct {
+	fsys     fs.FS
+	programs map[string]*loadedProgram
+
+	// Errors of reloading programs by key
+	errs map[string]error
+}
+
+// loadedProgram is a program variant and files it was made of.
+type loadedProgram struct {
+	program          *ShaderProgram
+	vertex, fragment string
+	defines          []string
+
+	// Modification times of the files when they were loaded
+	files map[string]time.Time
+	// Is true if the program has to be reloaded whether files changed or not
+	stale bool
+}
+
+// NewShaderLoader returns a ShaderLoader reading files from a file system,
+// like an embed.FS or os.DirFS.
+func NewShaderLoader(fsys fs.FS) *ShaderLoader {
+	return &ShaderLoader{
+		fsys:     fsys,
+		programs: make(map[string]*loadedProgram),
+		errs:     make(map[string]error),
+	}
+}
+
+// FS returns the file system shaders are read from.
+func (l *ShaderLoader) FS() fs.FS {
+	return l.fsys
+}
+
+// SetFS changes the file system shaders are read from. Programs loaded already
+// are reloaded from it by the next Reload. Point a loader of embedded shaders
+// to their source directory with os.DirFS to edit them live.
+func (l *ShaderLoader) SetFS(fsys fs.FS) {
+	l.fsys = fsys
+	for _, p := range l.programs {
+		p.stale = true
+	}
+	if hotReloader != nil {
+		hotReloader.watchLoader(l)
+	}
+}
+
+// programKey returns the key a program variant is cached with.
+func programKey(vertex, fragment string, defines []string) string {
+	return vertex + "|" + fragment + "|" + strings.Join(defines, ",")
+}
+
+// Source preprocesses a shader file with the given defines.
+func (l *ShaderLoader) Source(name string, defines ...string) (*Source, error) {
+	return Preprocess(l.fsys, name, normalizeDefines(defines)...)
+}
+
+// Program returns a program linked from a vertex and a fragment shader files
+// with the given defines. The order of defines doesn't matter.
+func (l *ShaderLoader) Program(vertex, fragment string, defines ...string) (*ShaderProgram, error) {
+	defines = normalizeDefines(defines)
+	key := programKey(vertex, fragment, defines)
+	if p, ok := l.programs[key]; ok {
+		return p.program, nil
+	}
+	if !Initialized() {
+		return nil, fmt.Errorf("Failed to load shader program: OpenGL is not initialized")
+	}
+
+	p := &loadedProgram{
+		program:  new(ShaderProgram),
+		vertex:   vertex,
+		fragment: fragment,
+		defines:  defines,
+	}
+	files, err := l.link(p.program, vertex, fragment, defines)
+	if err != nil {
+		return nil, err
+	}
+	p.files = l.stamp(files)
+	l.programs[key] = p
+
+	if hotReloader != nil {
+		hotReloader.watchLoader(l)
+	}
+	return p.program, nil
+}
+
+// link compiles shader files and links them into a program. It returns names
+// of the files preprocessed.
+func (l *ShaderLoader) link(sp *ShaderProgram, vertex, fragment string, defines []string) ([]string, error) {
+	var files []string
+	var shaders [2]Shader
+	for i, f := range []struct {
+		name string
+		t    ShaderType
+	}{
+		{vertex, VertexShader},
+		{fragment, FragmentShader},
+	} {
+		src, err := Preprocess(l.fsys, f.name, defines...)
+		if err != nil {
+			return files, err
+		}
+		files = append(files, src.Files()...)
+		if err := shaders[i].CompileSource(src, f.t); err != nil {
+			return files, fmt.Errorf("Failed to compile %s shader %s: %v", f.t, f.name, err)
+		}
+		// Linked programs don't need their shaders
+		defer shaders[i].Release()
+	}
+
+	if err := sp.Link(shaders[0], shaders[1]); err != nil {
+		return files, fmt.Errorf("Failed to link %s and %s: %v", vertex, fragment, err)
+	}
+	return files, nil
+}
+
+// stamp returns modification times of files.
+func (l *ShaderLoader) stamp(files []string) map[string]time.Time {
+	res := make(map[string]time.Time, len(files))
+	for _, f := range files {
+		res[f] = fsModTime(l.fsys, f)
+	}
+	return res
+}
+
+// changed returns true if a program has to be reloaded.
+func (l *ShaderLoader) changed(p *loadedProgram) bool {
+	if p.stale {
+		return true
+	}
+	for f, mtime := range p.files {
+		if !fsModTime(l.fsys, f).Equal(mtime) {
+			return true
+		}
+	}
+	return false
+}
+
+// Reload relinks programs, whose files have changed. A program, which fails to
+// reload, keeps working as it was, and the error is returned by Err until its
+// files are fixed.
+func (l *ShaderLoader) Reload() error {
+	for key, p := range l.programs {
+		if !l.changed(p) {
+			continue
+		}
+		p.stale = false
+
+		// Vertex arrays are set up with attribute locations of the old
+		// program, so the new one must have the same ones
+		fresh := new(ShaderProgram)
+		for _, a := range p.program.Attributes() {
+			fresh.BindAttribLocation(a.Name, uint32(a.Location))
+		}
+
+		files, err := l.link(fresh, p.vertex, p.fragment, p.defines)
+		if err != nil {
+			// Failed programs are not retried until files change again, so
+			// old files are watched too: the one to fix may be not even read
+			for f := range p.files {
+				files = append(files, f)
+			}
+			p.files = l.stamp(files)
+			log.Println("Failed to reload shader program:", err)
+			l.errs[key] = err
+			continue
+		}
+		p.files = l.stamp(files)
+		delete(l.errs, key)
+		p.program.replace(fresh)
+	}
+	return l.Err()
+}
+
+// Err returns errors of reloading programs, which are not fixed yet, or nil.
+func (l *ShaderLoader) Err() error {
+	if len(l.errs) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(l.errs))
+	for _, err := range l.errs {
+		msgs = append(msgs, err.Error())
+	}
+	sort.Strings(msgs)
+	return fmt.Errorf("%s", strings.Join(msgs, "\n"))
+}
+
+// Clear deletes all the programs loaded.
+func (l *ShaderLoader) Clear() {
+	for key, p := range l.programs {
+		p.program.Release()
+		delete(l.programs, key)
+		delete(l.errs, key)
+	}
+}
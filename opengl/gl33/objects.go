@@ -0,0 +1,281 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// OpenGL objects are deleted explicitly, with Release methods of types owning
+// them. Every object made by this package, or with GenBuffer and alike, is
+// registered until it's deleted, so objects left at shutdown can be reported
+// with ReportLeaks.
+
+// ObjectKind is a kind of OpenGL object.
+type ObjectKind int
+
+// Kinds of objects tracked
+const (
+	TextureObject     ObjectKind = iota
+	ShaderObject      ObjectKind = iota
+	ProgramObject     ObjectKind = iota
+	BufferObject      ObjectKind = iota
+	VertexArrayObject ObjectKind = iota
+)
+
+// String returns name of an object kind, like "texture".
+func (k ObjectKind) String() string {
+	switch k {
+	case TextureObject:
+		return "texture"
+	case ShaderObject:
+		return "shader"
+	case ProgramObject:
+		return "program"
+	case BufferObject:
+		return "buffer"
+	case VertexArrayObject:
+		return "vertex array"
+	}
+	return fmt.Sprintf("ObjectKind(%d)", int(k))
+}
+
+type object struct {
+	kind ObjectKind
+	id   uint32
+}
+
+var (
+	// Objects not deleted yet, with stacks they were made at, if leak
+	// tracking was on
+	live = make(map[object]string)
+
+	trackStacks   bool
+	useFinalizers bool
+)
+
+// SetLeakTracking makes objects remember where they are made from now on, so
+// that ReportLeaks tells where leaked objects come from. It's slow, so it's
+// meant for debugging.
+func SetLeakTracking(on bool) {
+	trackStacks = on
+}
+
+// LeakTracking returns true if leak tracking is on.
+func LeakTracking() bool {
+	return trackStacks
+}
+
+// SetFinalizers makes textures, shaders and programs made from now on delete
+// their OpenGL objects, when they are garbage collected without Release.
+// Finalizers run on their own goroutine, while OpenGL may only be used from
+// the thread it runs on, so the objects are only queued for deletion. They are
+// deleted by DeletePending, which windows call every frame. Textures watched
+// by hot reload are never garbage collected, see Reloader.
+func SetFinalizers(on bool) {
+	useFinalizers = on
+}
+
+func track(kind ObjectKind, id uint32) {
+	var stack string
+	if trackStacks {
+		stack = string(debug.Stack())
+	}
+	live[object{kind, id}] = stack
+	if kind == TextureObject {
+		stats.Textures++
+	}
+}
+
+// deleteObject deletes an OpenGL object and stops tracking it.
+func deleteObject(kind ObjectKind, id uint32) {
+	if id == 0 {
+		return
+	}
+	switch kind {
+	case TextureObject:
+		gl.DeleteTextures(1, &id)
+	case ShaderObject:
+		gl.DeleteShader(id)
+	case ProgramObject:
+		gl.DeleteProgram(id)
+	case BufferObject:
+		gl.DeleteBuffers(1, &id)
+	case VertexArrayObject:
+		gl.DeleteVertexArrays(1, &id)
+	}
+
+	o := object{kind, id}
+	if _, ok := live[o]; ok {
+		delete(live, o)
+		if kind == TextureObject {
+			stats.Textures--
+		}
+	}
+}
+
+// Leak is an OpenGL object, which is not deleted.
+type Leak struct {
+	Kind ObjectKind
+	ID   uint32
+	// Stack the object was made at, if leak tracking was on
+	Stack string
+}
+
+type byObject []Leak
+
+func (l byObject) Len() int      { return len(l) }
+func (l byObject) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
+func (l byObject) Less(i, j int) bool {
+	if l[i].Kind != l[j].Kind {
+		return l[i].Kind < l[j].Kind
+	}
+	return l[i].ID < l[j].ID
+}
+
+// LiveObjects returns objects, which are not deleted yet, by kind and ID.
+func LiveObjects() []Leak {
+	res := make([]Leak, 0, len(live))
+	for o, stack := range live {
+		res = append(res, Leak{Kind: o.kind, ID: o.id, Stack: stack})
+	}
+	sort.Sort(byObject(res))
+	return res
+}
+
+// ReportLeaks logs the number of objects, which are not deleted yet, by kind
+// and returns it. With leak tracking on, every object is logged along with the
+// stack it was made at. Call it at shutdown, after releasing everything.
+func ReportLeaks() int {
+	DeletePending()
+	leaks := LiveObjects()
+	if len(leaks) == 0 {
+		return 0
+	}
+
+	counts := make(map[ObjectKind]int)
+	for _, l := range leaks {
+		counts[l.Kind]++
+		if l.Stack != "" {
+			log.Printf("Leaked OpenGL %s %d, made at:\n%s", l.Kind, l.ID, l.Stack)
+		}
+	}
+	var kinds []string
+	for k := TextureObject; k <= VertexArrayObject; k++ {
+		if n := counts[k]; n > 0 {
+			kinds = append(kinds, fmt.Sprintf("%d %s", n, k))
+		}
+	}
+	log.Printf("Leaked %d OpenGL objects: %s", len(leaks), strings.Join(kinds, ", "))
+	if !trackStacks {
+		log.Println("Turn leak tracking on with SetLeakTracking to see where they were made")
+	}
+	return len(leaks)
+}
+
+// handle owns an OpenGL object. Values owning objects keep a handle, which is
+// garbage collected along with them, so that the finalizer can be set on the
+// handle, wherever the value itself is.
+type handle struct {
+	object
+}
+
+// newHandle tracks a new object and sets the finalizer, if they are on.
+func newHandle(kind ObjectKind, id uint32) *handle {
+	track(kind, id)
+	h := &handle{object{kind, id}}
+	if useFinalizers {
+		runtime.SetFinalizer(h, finalizeHandle)
+	}
+	return h
+}
+
+// release deletes the object. It's fine to release nil handles.
+func (h *handle) release() {
+	if h == nil {
+		return
+	}
+	runtime.SetFinalizer(h, nil)
+	deleteObject(h.kind, h.id)
+	h.id = 0
+}
+
+// Objects of garbage collected handles, waiting for deletion
+var pending struct {
+	sync.Mutex
+	objects []object
+}
+
+func finalizeHandle(h *handle) {
+	pending.Lock()
+	pending.objects = append(pending.objects, h.object)
+	pending.Unlock()
+}
+
+// DeletePending deletes objects of textures, shaders and programs garbage
+// collected without Release, see SetFinalizers, and returns their number. It
+// must be called from the thread OpenGL runs on.
+func DeletePending() int {
+	pending.Lock()
+	objects := pending.objects
+	pending.objects = nil
+	pending.Unlock()
+
+	for _, o := range objects {
+		deleteObject(o.kind, o.id)
+	}
+	return len(objects)
+}
+
+// GenTexture makes a texture object, which is tracked until DeleteTexture.
+// Use it for textures not wrapped into Texture.
+func GenTexture() uint32 {
+	var id uint32
+	gl.GenTextures(1, &id)
+	track(TextureObject, id)
+	return id
+}
+
+// DeleteTexture deletes a texture made with GenTexture and zeroes id.
+func DeleteTexture(id *uint32) {
+	deleteObject(TextureObject, *id)
+	*id = 0
+}
+
+// GenBuffer makes a buffer object, which is tracked until DeleteBuffer.
+func GenBuffer() uint32 {
+	var id uint32
+	gl.GenBuffers(1, &id)
+	track(BufferObject, id)
+	return id
+}
+
+// DeleteBuffer deletes a buffer made with GenBuffer and zeroes id.
+func DeleteBuffer(id *uint32) {
+	deleteObject(BufferObject, *id)
+	*id = 0
+}
+
+// GenVertexArray makes a vertex array object, which is tracked until
+// DeleteVertexArray.
+func GenVertexArray() uint32 {
+	var id uint32
+	gl.GenVertexArrays(1, &id)
+	track(VertexArrayObject, id)
+	return id
+}
+
+// DeleteVertexArray deletes a vertex array made with GenVertexArray and zeroes
+// id.
+func DeleteVertexArray(id *uint32) {
+	deleteObject(VertexArrayObject, *id)
+	*id = 0
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLiveObjects(t *testing.T) {
+	defer func() {
+		live = make(map[object]string)
+		stats.Textures = 0
+		SetLeakTracking(false)
+	}()
+
+	track(BufferObject, 3)
+	track(TextureObject, 7)
+	SetLeakTracking(true)
+	track(TextureObject, 2)
+
+	leaks := LiveObjects()
+	if len(leaks) != 3 || leaks[0].ID != 2 || leaks[1].ID != 7 || leaks[2].Kind != BufferObject {
+		t.Fatalf("Live objects are %+v, want textures 2 and 7 and buffer 3", leaks)
+	}
+	if leaks[1].Stack != "" || !strings.Contains(leaks[0].Stack, "TestLiveObjects") {
+		t.Error("Stack is recorded without leak tracking or missing with it")
+	}
+	if stats.Textures != 2 {
+		t.Errorf("Stats count %d textures, want 2", stats.Textures)
+	}
+}
+
+func TestFinalizeHandle(t *testing.T) {
+	defer func() { pending.objects = nil }()
+
+	finalizeHandle(&handle{object{ProgramObject, 5}})
+	if len(pending.objects) != 1 || pending.objects[0] != (object{ProgramObject, 5}) {
+		t.Errorf("Pending objects are %+v, want program 5", pending.objects)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// Filter tells how a texture is sampled, when it's drawn bigger or smaller
+// than it is.
+type Filter int
+
+// Texture filters. Linear is the default one.
+const (
+	// Linear blends four nearest pixels, which makes scaled images smooth
+	Linear Filter = iota
+	// Nearest takes the nearest pixel, so pixel art stays sharp
+	Nearest Filter = iota
+	// Trilinear is Linear blended between two nearest mipmap levels. Used as a
+	// magnification filter, it's the same as Linear. Mipmaps are always made
+	// for textures minified with it.
+	Trilinear Filter = iota
+)
+
+// Wrap tells how a texture is sampled outside of [0, 1] texture coordinates.
+type Wrap int
+
+// Wrap modes. ClampToEdge is the default one.
+const (
+	// ClampToEdge stretches pixels on the edge of a texture
+	ClampToEdge Wrap = iota
+	// Repeat tiles a texture
+	Repeat Wrap = iota
+	// MirroredRepeat tiles a texture, flipping every other tile
+	MirroredRepeat Wrap = iota
+)
+
+// TextureOptions are settings of texture sampling. Zero TextureOptions are
+// linear filtering without mipmaps and clamping to edges.
+type TextureOptions struct {
+	// Filters used when a texture is drawn smaller and bigger than it is
+	MinFilter, MagFilter Filter
+
+	// Wrap modes along X (S) and Y (T) axes
+	WrapS, WrapT Wrap
+
+	// Mipmaps makes smaller copies of the image, which are sampled when a
+	// texture is drawn small. That's a third more video memory, but minified
+	// textures don't flicker.
+	Mipmaps bool
+
+	// Anisotropy is the maximum level of anisotropic filtering, which keeps
+	// textures seen at an angle sharp. It's clamped to what the driver
+	// supports, 1 and less turn it off. It's ignored, if the driver doesn't
+	// support anisotropic filtering at all.
+	Anisotropy float32
+}
+
+// PixelArtOptions are options for pixel art: no smoothing at all.
+var PixelArtOptions = TextureOptions{MinFilter: Nearest, MagFilter: Nearest}
+
+// Constants of GL_EXT_texture_filter_anisotropic, which is not in the core
+// profile of OpenGL 3.3
+const (
+	textureMaxAnisotropy    = 0x84FE
+	maxTextureMaxAnisotropy = 0x84FF
+)
+
+// Maximum anisotropy supported by the driver, 0 if anisotropic filtering is
+// not supported. Negative until checked.
+var maxAnisotropy float32 = -1
+
+// MaxAnisotropy returns the maximum level of anisotropic filtering supported,
+// or 0 if it isn't supported.
+func MaxAnisotropy() float32 {
+	if maxAnisotropy >= 0 {
+		return maxAnisotropy
+	}
+
+	maxAnisotropy = 0
+	var n int32
+	gl.GetIntegerv(gl.NUM_EXTENSIONS, &n)
+	for i := int32(0); i < n; i++ {
+		ext := gl.GoStr(gl.GetStringi(gl.EXTENSIONS, uint32(i)))
+		if ext == "GL_EXT_texture_filter_anisotropic" || ext == "GL_ARB_texture_filter_anisotropic" {
+			gl.GetFloatv(maxTextureMaxAnisotropy, &maxAnisotropy)
+			break
+		}
+	}
+	return maxAnisotropy
+}
+
+// mipmapped returns true if mipmaps are needed with these options.
+func (o TextureOptions) mipmapped() bool {
+	return o.Mipmaps || o.MinFilter == Trilinear
+}
+
+// minFilter returns OpenGL minification filter for the options.
+func (o TextureOptions) minFilter() int32 {
+	mip := o.mipmapped()
+	switch {
+	case o.MinFilter == Trilinear:
+		return gl.LINEAR_MIPMAP_LINEAR
+	case o.MinFilter == Nearest && mip:
+		return gl.NEAREST_MIPMAP_NEAREST
+	case o.MinFilter == Nearest:
+		return gl.NEAREST
+	case mip:
+		return gl.LINEAR_MIPMAP_NEAREST
+	default:
+		return gl.LINEAR
+	}
+}
+
+// magFilter returns OpenGL magnification filter for the options.
+func (o TextureOptions) magFilter() int32 {
+	if o.MagFilter == Nearest {
+		return gl.NEAREST
+	}
+	return gl.LINEAR
+}
+
+// glWrap returns OpenGL wrap mode.
+func glWrap(w Wrap) int32 {
+	switch w {
+	case Repeat:
+		return gl.REPEAT
+	case MirroredRepeat:
+		return gl.MIRRORED_REPEAT
+	default:
+		return gl.CLAMP_TO_EDGE
+	}
+}
+
+// apply sets the options to the texture bound to GL_TEXTURE_2D.
+func (o TextureOptions) apply() {
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, o.minFilter())
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, o.magFilter())
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, glWrap(o.WrapS))
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, glWrap(o.WrapT))
+
+	if max := MaxAnisotropy(); max > 0 {
+		a := o.Anisotropy
+		if a < 1 {
+			a = 1
+		}
+		if a > max {
+			a = max
+		}
+		gl.TexParameterf(gl.TEXTURE_2D, textureMaxAnisotropy, a)
+	}
+}
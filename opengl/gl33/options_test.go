@@ -0,0 +1,32 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"testing"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+func TestTextureOptionsFilters(t *testing.T) {
+	cases := []struct {
+		o        TextureOptions
+		min, mag int32
+		mipmaps  bool
+	}{
+		{TextureOptions{}, gl.LINEAR, gl.LINEAR, false},
+		{PixelArtOptions, gl.NEAREST, gl.NEAREST, false},
+		{TextureOptions{MinFilter: Nearest, Mipmaps: true}, gl.NEAREST_MIPMAP_NEAREST, gl.LINEAR, true},
+		{TextureOptions{Mipmaps: true}, gl.LINEAR_MIPMAP_NEAREST, gl.LINEAR, true},
+		// Trilinear filtering needs mipmaps even if they are not asked for
+		{TextureOptions{MinFilter: Trilinear, MagFilter: Trilinear}, gl.LINEAR_MIPMAP_LINEAR, gl.LINEAR, true},
+	}
+	for _, c := range cases {
+		if min, mag := c.o.minFilter(), c.o.magFilter(); min != c.min || mag != c.mag {
+			t.Errorf("Filters of %+v are %#x and %#x, want %#x and %#x", c.o, min, mag, c.min, c.mag)
+		}
+		if c.o.mipmapped() != c.mipmaps {
+			t.Errorf("mipmapped() of %+v = %v, want %v", c.o, c.o.mipmapped(), c.mipmaps)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Shader files may include other files with
+//     #include "common.glsl"
+// Paths are relative to the including file. Each file is included once, so
+// there's no need in include guards. Defines are injected right after the
+// #version line, so shaders can check them with #ifdef:
+//     #ifdef WITH_TINT
+//     color *= tint;
+//     #endif
+
+// sourceLine is a line of a shader file.
+type sourceLine struct {
+	file string
+	line int
+}
+
+// Source is a preprocessed shader source, which remembers which file and line
+// each of its lines came from.
+type Source struct {
+	// Name of the file the source was preprocessed from
+	Name string
+	// Text is the source with includes resolved and defines injected
+	Text string
+
+	lines []sourceLine
+	files []string
+}
+
+// Files returns names of the file the source was preprocessed from and the
+// files it includes.
+func (s *Source) Files() []string {
+	return s.files
+}
+
+// Line returns the file and line a line of Text came from. Lines are counted
+// from 1, just like compilers do. Injected defines come from "<defines>".
+func (s *Source) Line(n int) (file string, line int) {
+	if n < 1 || n > len(s.lines) {
+		return s.Name, n
+	}
+	l := s.lines[n-1]
+	return l.file, l.line
+}
+
+var (
+	includeRegexp = regexp.MustCompile(`^\s*#\s*include\s+"([^"]+)"\s*$`)
+	versionRegexp = regexp.MustCompile(`^\s*#\s*version\b`)
+	// Any #include directive, well-formed or not
+	includeDirectiveRegexp = regexp.MustCompile(`^\s*#\s*include\b`)
+	// Compilers refer to lines as 0:12 (Mesa, AMD) or 0(12) (NVIDIA), where 0
+	// is the index of the source string
+	logLineRegexp = regexp.MustCompile(`(?m)(^|\s)0(?::(\d+)|\((\d+)\))`)
+)
+
+// preprocessor resolves includes of a single source.
+type preprocessor struct {
+	fsys     fs.FS
+	included map[string]bool
+	files    []string
+	// Files being included, to report include cycles
+	stack []string
+
+	text  []string
+	lines []sourceLine
+}
+
+// Preprocess reads a shader file from a file system, resolves its includes
+// and injects defines. Defines are either names, like "WITH_TINT", or names
+// with values, like "MAX_LIGHTS=4".
+func Preprocess(fsys fs.FS, name string, defines ...string) (*Source, error) {
+	p := &preprocessor{fsys: fsys, included: make(map[string]bool)}
+	if err := p.file(name, defines); err != nil {
+		return nil, err
+	}
+	return &Source{
+		Name:  name,
+		Text:  strings.Join(p.text, "\n") + "\n",
+		lines: p.lines,
+		files: p.files,
+	}, nil
+}
+
+func (p *preprocessor) emit(text, file string, line int) {
+	p.text = append(p.text, text)
+	p.lines = append(p.lines, sourceLine{file, line})
+}
+
+// file appends lines of a file to the source. Defines are only given for the
+// top-level file.
+func (p *preprocessor) file(name string, defines []string) error {
+	for _, f := range p.stack {
+		if f == name {
+			return fmt.Errorf("Failed to preprocess shader: include cycle %s -> %s", strings.Join(p.stack, " -> "), name)
+		}
+	}
+	if p.included[name] {
+		return nil
+	}
+	p.included[name] = true
+	p.files = append(p.files, name)
+
+	data, err := fs.ReadFile(p.fsys, name)
+	if err != nil {
+		return fmt.Errorf("Failed to read shader: %v", err)
+	}
+	p.stack = append(p.stack, name)
+	defer func() { p.stack = p.stack[:len(p.stack)-1] }()
+
+	lines := strings.Split(strings.TrimSuffix(strings.TrimRight(string(data), "\x00"), "\n"), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], "\r")
+	}
+	// Defines must go after #version, which may be preceded by comments, like
+	// a license. Without #version they go before the first line of code.
+	injected := defines == nil
+	hasVersion := false
+	for _, l := range lines {
+		if versionRegexp.MatchString(l) {
+			hasVersion = true
+			break
+		}
+	}
+	comment := false
+	for i, l := range lines {
+		n := i + 1
+
+		if versionRegexp.MatchString(l) {
+			// Only the main file decides the version
+			if len(p.stack) == 1 {
+				p.emit(l, name, n)
+				if !injected {
+					p.inject(defines)
+					injected = true
+				}
+			}
+			continue
+		}
+		if !injected && !hasVersion && !isComment(l, &comment) {
+			p.inject(defines)
+			injected = true
+		}
+
+		if m := includeRegexp.FindStringSubmatch(l); m != nil {
+			if err := p.file(path.Join(path.Dir(name), m[1]), nil); err != nil {
+				return err
+			}
+			continue
+		}
+		if includeDirectiveRegexp.MatchString(l) {
+			return fmt.Errorf("Failed to preprocess shader: %s:%d: malformed #include", name, n)
+		}
+		p.emit(l, name, n)
+	}
+	if !injected {
+		// The file is all comments
+		p.inject(defines)
+	}
+	return nil
+}
+
+// isComment returns true if a line is blank or holds a comment only. comment
+// tells if the line starts inside a block comment and is updated for the next
+// one.
+func isComment(l string, comment *bool) bool {
+	l = strings.TrimSpace(l)
+	if *comment {
+		end := strings.Index(l, "*/")
+		if end < 0 {
+			return true
+		}
+		*comment = false
+		l = strings.TrimSpace(l[end+2:])
+	}
+	if l == "" || strings.HasPrefix(l, "//") {
+		return true
+	}
+	if strings.HasPrefix(l, "/*") {
+		end := strings.Index(l[2:], "*/")
+		if end < 0 {
+			*comment = true
+			return true
+		}
+		return isComment(l[end+4:], comment)
+	}
+	return false
+}
+
+func (p *preprocessor) inject(defines []string) {
+	for _, d := range defines {
+		p.emit("#define "+strings.Replace(d, "=", " ", 1), "<defines>", 0)
+	}
+}
+
+// normalizeDefines sorts defines and removes duplicates, so that the same set
+// of defines always makes the same program.
+func normalizeDefines(defines []string) []string {
+	res := make([]string, 0, len(defines))
+	for _, d := range defines {
+		if d = strings.TrimSpace(d); d != "" {
+			res = append(res, d)
+		}
+	}
+	sort.Strings(res)
+
+	unique := res[:0]
+	for i, d := range res {
+		if i == 0 || d != res[i-1] {
+			unique = append(unique, d)
+		}
+	}
+	return unique
+}
+
+// mapLog replaces line numbers in a compile log with files and lines they came
+// from.
+func (s *Source) mapLog(log string) string {
+	return logLineRegexp.ReplaceAllStringFunc(log, func(m string) string {
+		sub := logLineRegexp.FindStringSubmatch(m)
+		n := sub[2]
+		if n == "" {
+			n = sub[3]
+		}
+		line, err := strconv.Atoi(n)
+		if err != nil {
+			return m
+		}
+		file, l := s.Line(line)
+		return fmt.Sprintf("%s%s:%d", sub[1], file, l)
+	})
+}
+
+// CompileSource compiles a preprocessed shader. Lines in compile errors refer
+// to the files the source was preprocessed from.
+func (s *Shader) CompileSource(src *Source, t ShaderType) error {
+	if err := s.Compile(src.Text, t); err != nil {
+		return fmt.Errorf("%s", src.mapLog(err.Error()))
+	}
+	return nil
+}
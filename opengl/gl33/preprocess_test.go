@@ -0,0 +1,76 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+var shaderFiles = fstest.MapFS{
+	"main.frag": {Data: []byte("#version 330 core\n#include \"lib/a.glsl\"\n#include \"lib/b.glsl\"\nvoid main() {}\n")},
+	// b.glsl includes a.glsl too, but it's included only once
+	"lib/a.glsl": {Data: []byte("float a() { return 1.0; }\n")},
+	"lib/b.glsl": {Data: []byte("#include \"a.glsl\"\nfloat b() { return a(); }\n")},
+	// Defines go after #version, not before the license comment
+	"license.frag": {Data: []byte("// Copyright\n#version 330 core\nvoid main() {}\n")},
+	// Without #version they go after comments, before the first line of code
+	"noversion.frag": {Data: []byte("/* Copyright\n */\n// Tint\nvoid main() {}\n")},
+	// Includes in comments are left alone
+	"commented.frag": {Data: []byte("#version 330 core\n// #include \"lib/a.glsl\"\nvoid main() {}\n")},
+	"comments.frag":  {Data: []byte("// Nothing but a comment\n")},
+	"malformed.frag": {Data: []byte("  # include <lib/a.glsl>\n")},
+	"cycle.frag":     {Data: []byte("#include \"cycle.glsl\"\n")},
+	"cycle.glsl":     {Data: []byte("#include \"cycle.frag\"\n")},
+}
+
+func TestPreprocess(t *testing.T) {
+	src, err := Preprocess(shaderFiles, "main.frag", normalizeDefines([]string{"WITH_TINT", "MAX_LIGHTS=4", "WITH_TINT"})...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "#version 330 core\n#define MAX_LIGHTS 4\n#define WITH_TINT\n" +
+		"float a() { return 1.0; }\nfloat b() { return a(); }\nvoid main() {}\n"
+	if src.Text != want {
+		t.Errorf("Preprocessed source is\n%s\nwant\n%s", src.Text, want)
+	}
+
+	if file, line := src.Line(5); file != "lib/b.glsl" || line != 2 {
+		t.Errorf("Line 5 comes from %s:%d, want lib/b.glsl:2", file, line)
+	}
+	if file, _ := src.Line(2); file != "<defines>" {
+		t.Errorf("Line 2 comes from %s, want <defines>", file)
+	}
+
+	log := src.mapLog("Failed to compile shader: 0:6(10): error: syntax error\n0(4) : error C0000: bad")
+	if !strings.Contains(log, "shader: main.frag:4(10)") || !strings.Contains(log, "\nlib/a.glsl:1 : error") {
+		t.Errorf("Mapped log is %q", log)
+	}
+
+	for _, c := range []struct{ name, want string }{
+		{"license.frag", "// Copyright\n#version 330 core\n#define WITH_TINT\nvoid main() {}\n"},
+		{"noversion.frag", "/* Copyright\n */\n// Tint\n#define WITH_TINT\nvoid main() {}\n"},
+		{"commented.frag", "#version 330 core\n#define WITH_TINT\n// #include \"lib/a.glsl\"\nvoid main() {}\n"},
+		{"comments.frag", "// Nothing but a comment\n#define WITH_TINT\n"},
+	} {
+		src, err := Preprocess(shaderFiles, c.name, "WITH_TINT")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if src.Text != c.want {
+			t.Errorf("Preprocessed %s is %q, want %q", c.name, src.Text, c.want)
+		}
+	}
+
+	if _, err := Preprocess(shaderFiles, "malformed.frag"); err == nil || !strings.Contains(err.Error(), "malformed") {
+		t.Errorf("Malformed #include error is %v", err)
+	}
+	if _, err := Preprocess(shaderFiles, "cycle.frag"); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("Include cycle error is %v", err)
+	}
+	if _, err := Preprocess(shaderFiles, "missing.frag"); err == nil {
+		t.Error("No error for a missing file")
+	}
+}
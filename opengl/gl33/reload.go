@@ -0,0 +1,175 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultReloadInterval is how often files are checked for changes by default.
+const DefaultReloadInterval = 500 * time.Millisecond
+
+// fileLoader is a texture, which can be reloaded from a file.
+type fileLoader interface {
+	LoadFromFile(file string) error
+}
+
+// watchedFile is a file and its modification time when it was loaded.
+type watchedFile struct {
+	file  string
+	mtime time.Time
+}
+
+// Reloader watches files textures and shader programs were loaded from and
+// reloads them, when they change. That's meant for development: edit a shader
+// or an image and see the result without restarting the game.
+//
+// Files are checked by polling their modification times, and reloading happens
+// in Poll, which has to be called from the thread OpenGL runs on. Windows do
+// that every frame. If reloading fails, the old version is kept and the error
+// is reported by Err until the file is fixed.
+//
+// The Reloader keeps watched textures reachable, so they are never garbage
+// collected, and finalizers (see SetFinalizers) don't delete them. With hot
+// reload on, textures have to be released explicitly, which stops watching
+// them too.
+type Reloader struct {
+	interval time.Duration
+	last     time.Time
+
+	textures map[fileLoader]*watchedFile
+	loaders  []*ShaderLoader
+
+	// Errors of reloading textures by file
+	errs map[string]error
+}
+
+// The reloader textures and shader loaders register with, if hot reload is on
+var hotReloader *Reloader
+
+// EnableHotReload makes textures loaded with LoadFromFile and programs loaded
+// with ShaderLoaders from now on watched for changes, which are checked every
+// interval. Things loaded before are not watched, so call it first thing.
+// Watched textures are not garbage collected until they are released, see
+// Reloader.
+func EnableHotReload(interval time.Duration) *Reloader {
+	if hotReloader == nil {
+		hotReloader = &Reloader{
+			textures: make(map[fileLoader]*watchedFile),
+			errs:     make(map[string]error),
+		}
+	}
+	hotReloader.interval = interval
+	return hotReloader
+}
+
+// HotReloader returns the Reloader made by EnableHotReload, or nil if hot
+// reload is off.
+func HotReloader() *Reloader {
+	return hotReloader
+}
+
+// fileModTime returns modification time of a file, zero if there is no file.
+func fileModTime(file string) time.Time {
+	info, err := os.Stat(file)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// fsModTime returns modification time of a file in a file system, zero if
+// there is no file or the file system doesn't know it, like embed.FS.
+func fsModTime(fsys fs.FS, name string) time.Time {
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// watchTexture starts watching the file a texture was loaded from, if hot
+// reload is on.
+func watchTexture(t fileLoader, file string) {
+	if r := hotReloader; r != nil {
+		r.textures[t] = &watchedFile{file, fileModTime(file)}
+		delete(r.errs, file)
+	}
+}
+
+// unwatchTexture stops watching a texture.
+func unwatchTexture(t fileLoader) {
+	if r := hotReloader; r != nil {
+		if w, ok := r.textures[t]; ok {
+			delete(r.errs, w.file)
+			delete(r.textures, t)
+		}
+	}
+}
+
+// watchLoader starts watching programs of a shader loader.
+func (r *Reloader) watchLoader(l *ShaderLoader) {
+	for _, watched := range r.loaders {
+		if watched == l {
+			return
+		}
+	}
+	r.loaders = append(r.loaders, l)
+}
+
+// Poll reloads changed files, if the interval has passed since the last check.
+func (r *Reloader) Poll() {
+	now := time.Now()
+	if now.Sub(r.last) < r.interval {
+		return
+	}
+	r.last = now
+	r.Reload()
+}
+
+// Reload reloads changed files right away.
+func (r *Reloader) Reload() {
+	for t, w := range r.textures {
+		mtime := fileModTime(w.file)
+		if mtime.Equal(w.mtime) {
+			continue
+		}
+		// Failed files are not retried until they change again
+		w.mtime = mtime
+
+		if err := t.LoadFromFile(w.file); err != nil {
+			log.Println("Failed to reload texture:", err)
+			r.errs[w.file] = err
+			continue
+		}
+		delete(r.errs, w.file)
+	}
+
+	for _, l := range r.loaders {
+		l.Reload()
+	}
+}
+
+// Err returns errors of reloading files, which are not fixed yet, or nil.
+func (r *Reloader) Err() error {
+	var msgs []string
+	for _, err := range r.errs {
+		msgs = append(msgs, err.Error())
+	}
+	for _, l := range r.loaders {
+		if err := l.Err(); err != nil {
+			msgs = append(msgs, err.Error())
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	sort.Strings(msgs)
+	return fmt.Errorf("%s", strings.Join(msgs, "\n"))
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestLoaderChanged(t *testing.T) {
+	files := fstest.MapFS{
+		"a.frag":      {Data: []byte("void main() {}"), ModTime: time.Unix(1, 0)},
+		"common.glsl": {Data: []byte(""), ModTime: time.Unix(1, 0)},
+	}
+	l := NewShaderLoader(files)
+	p := &loadedProgram{files: l.stamp([]string{"a.frag", "common.glsl"})}
+	if l.changed(p) {
+		t.Error("Program changed, though files didn't")
+	}
+
+	files["common.glsl"].ModTime = time.Unix(2, 0)
+	if !l.changed(p) {
+		t.Error("Program didn't change with an included file")
+	}
+
+	p.files = l.stamp([]string{"a.frag", "common.glsl"})
+	delete(files, "a.frag")
+	if !l.changed(p) {
+		t.Error("Program didn't change with a file removed")
+	}
+
+	p.files = l.stamp([]string{"common.glsl"})
+	l.programs["a"] = p
+	l.SetFS(files)
+	if !l.changed(p) {
+		t.Error("Program isn't reloaded after changing file system")
+	}
+}
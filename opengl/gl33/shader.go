@@ -16,48 +16,78 @@ import (
 // fragment.Compile(fSrc, FragmentShader)
 // var shaderProgram ShaderProgram
 // shaderProgram.Link(vertex, fragment)
+// vertex.Release()
+// fragment.Release()
 // ...
 // shaderProgram.Use()
 // ...
+// shaderProgram.Release()
+// A geometry shader, if there is one, is linked along with them:
+// shaderProgram.Link(vertex, geometry, fragment)
 
-// ShaderType variables store type of a shader: vertex or fragment
+// ShaderType variables store type of a shader: vertex, geometry or fragment
 type ShaderType int
 
-// Currently Rocky doesn't support geometry shaders yet
+// Shader stages supported by OpenGL 3.3. There are no compute shaders in it,
+// use transform feedback to compute with shaders, see
+// ShaderProgram.SetTransformFeedbackVaryings.
 const (
 	VertexShader   ShaderType = iota
 	FragmentShader ShaderType = iota
+	GeometryShader ShaderType = iota
 )
 
+// String returns name of a shader type, like "vertex".
+func (t ShaderType) String() string {
+	switch t {
+	case VertexShader:
+		return "vertex"
+	case FragmentShader:
+		return "fragment"
+	case GeometryShader:
+		return "geometry"
+	}
+	return fmt.Sprintf("ShaderType(%d)", int(t))
+}
+
 // Shader holds following information about a shader:
 // - Its type
 // - OpenGL shader ID
 // - Shader is compiled or not.
 type Shader struct {
-	// Type of a shader: vertex or fragment. Assigned in Shader.Compile()
+	// Type of a shader: vertex, geometry or fragment. Assigned in Shader.Compile()
 	t ShaderType
 
-	// OpenGL shader ID
+	// OpenGL shader ID and its owner
 	shader uint32
+	handle *handle
 
 	// Is true only if the shader is compiled
 	compiled bool
 }
 
-// Compile compiles a shader from its sources
+// Compile compiles a shader from its sources. A shader compiled before is
+// released first.
 func (s *Shader) Compile(source string, t ShaderType) error {
-	s.compiled = false
-	if t == VertexShader {
+	s.Release()
+	switch t {
+	case VertexShader:
 		s.shader = gl.CreateShader(gl.VERTEX_SHADER)
-	} else if t == FragmentShader {
+	case FragmentShader:
 		s.shader = gl.CreateShader(gl.FRAGMENT_SHADER)
-	} else {
+	case GeometryShader:
+		s.shader = gl.CreateShader(gl.GEOMETRY_SHADER)
+	default:
 		return fmt.Errorf("Prevented compiling unsupported shader type: %v", t)
 	}
 	// If shader type is supported, continue
 	s.t = t
+	s.handle = newHandle(ShaderObject, s.shader)
 
 	// Convert shader's source code to a C string
+	if !strings.HasSuffix(source, "\x00") {
+		source += "\x00"
+	}
 	csources, free := gl.Strs(source)
 	gl.ShaderSource(s.shader, 1, csources, nil)
 	free()
@@ -75,6 +105,7 @@ func (s *Shader) Compile(source string, t ShaderType) error {
 		compLog := strings.Repeat("\x00", int(logLength+1))
 		gl.GetShaderInfoLog(s.shader, logLength, nil, gl.Str(compLog))
 
+		s.Release()
 		return fmt.Errorf("Failed to compile shader: %v", compLog)
 	}
 	s.compiled = true
@@ -82,6 +113,15 @@ func (s *Shader) Compile(source string, t ShaderType) error {
 	return nil
 }
 
+// Release deletes the OpenGL shader. Programs linked with it keep working, so
+// shaders may be released right after linking.
+func (s *Shader) Release() {
+	s.handle.release()
+	s.handle = nil
+	s.shader = 0
+	s.compiled = false
+}
+
 // Compiled returns true if a shader is compiled
 func (s *Shader) Compiled() bool {
 	return s.compiled
@@ -95,42 +135,113 @@ func (s *Shader) Type() ShaderType {
 // ShaderProgram contains OpenGL shader program ID and its state:
 // is the shader program successfully linked or not.
 type ShaderProgram struct {
-	// OpenGL shader program ID
+	// OpenGL shader program ID and its owner
 	program uint32
+	handle  *handle
 
 	// Is true only if shader program is linked
 	linked bool
+
+	// Locations of vertex attributes set before linking
+	attribs map[string]uint32
+
+	// Outputs captured with transform feedback and whether they are written
+	// to a single buffer
+	varyings    []string
+	interleaved bool
+
+	// Cached locations of uniforms, see Uniform
+	uniforms map[string]int32
+
+	// Active variables of the linked program, see introspect
+	attributes     []Variable
+	activeUniforms []Variable
+	blocks         []UniformBlock
 }
 
-// Link links a shader program from 2 ALREADY COMPILED shaders
-func (sp *ShaderProgram) Link(vertex, fragment Shader) error {
-	if sp.linked {
-		gl.DeleteProgram(sp.program)
-		sp.program = 0
-		sp.linked = false
+// BindAttribLocation makes a vertex attribute use the given location. It must
+// be called before Link and is applied on every Link afterwards. That's useful
+// to use the same vertex array with several programs.
+func (sp *ShaderProgram) BindAttribLocation(name string, location uint32) {
+	if sp.attribs == nil {
+		sp.attribs = make(map[string]uint32)
 	}
+	sp.attribs[name] = location
+}
 
-	// Shader has wrong type
-	if vertex.Type() != VertexShader {
-		return fmt.Errorf("Shader given as a vertex isn't a vertex one")
+// SetTransformFeedbackVaryings sets outputs of the last vertex processing stage
+// (vertex or geometry shader), which are written to buffers with transform
+// feedback. If interleaved is true, they are all written to a single buffer,
+// otherwise each goes to its own one. It must be called before Link. With
+// varyings set, the fragment shader is optional: programs only computing
+// something don't need it.
+func (sp *ShaderProgram) SetTransformFeedbackVaryings(varyings []string, interleaved bool) {
+	sp.varyings = append([]string(nil), varyings...)
+	sp.interleaved = interleaved
+}
+
+// checkStages returns an error, if shaders can't be linked into a program:
+// some of them aren't compiled, there is no vertex shader, there is more than
+// one shader of a stage, or there is no fragment shader and nothing is
+// captured with transform feedback.
+func checkStages(shaders []Shader, feedback bool) error {
+	count := map[ShaderType]int{}
+	for _, s := range shaders {
+		if !s.compiled {
+			return fmt.Errorf("%v shader isn't compiled", s.t)
+		}
+		count[s.t]++
 	}
-	if fragment.Type() != FragmentShader {
-		return fmt.Errorf("Shader given as a fragment isn't a fragment one")
+
+	for t, n := range count {
+		if n > 1 {
+			return fmt.Errorf("Got %d %v shaders, one is allowed", n, t)
+		}
 	}
-	// Shader isn't compiled yet
-	if !vertex.compiled {
-		return fmt.Errorf("Vertex shader isn't compiled")
+	if count[VertexShader] == 0 {
+		return fmt.Errorf("There is no vertex shader")
 	}
-	if !fragment.compiled {
-		return fmt.Errorf("Fragment shader isn't compiled")
+	if count[FragmentShader] == 0 && !feedback {
+		return fmt.Errorf("There is no fragment shader")
+	}
+	return nil
+}
+
+// Link links a shader program from ALREADY COMPILED shaders: a vertex shader,
+// a fragment one and, optionally, a geometry one. The fragment shader may be
+// omitted, if transform feedback varyings are set.
+func (sp *ShaderProgram) Link(shaders ...Shader) error {
+	sp.Release()
+
+	if err := checkStages(shaders, len(sp.varyings) > 0); err != nil {
+		return fmt.Errorf("Failed to link program: %v", err)
 	}
 
 	sp.program = gl.CreateProgram()
+	sp.handle = newHandle(ProgramObject, sp.program)
 
-	gl.AttachShader(sp.program, vertex.shader)
-	gl.AttachShader(sp.program, fragment.shader)
+	for _, s := range shaders {
+		gl.AttachShader(sp.program, s.shader)
+	}
+	for name, loc := range sp.attribs {
+		gl.BindAttribLocation(sp.program, loc, gl.Str(name+"\x00"))
+	}
+	if len(sp.varyings) > 0 {
+		mode := uint32(gl.SEPARATE_ATTRIBS)
+		if sp.interleaved {
+			mode = gl.INTERLEAVED_ATTRIBS
+		}
+		cvaryings, free := gl.Strs(cStrings(sp.varyings)...)
+		gl.TransformFeedbackVaryings(sp.program, int32(len(sp.varyings)), cvaryings, mode)
+		free()
+	}
 	gl.LinkProgram(sp.program)
 
+	// Shaders may be used in other programs, so they are only detached
+	for _, s := range shaders {
+		gl.DetachShader(sp.program, s.shader)
+	}
+
 	// Print linking errors if any
 	var status int32
 	gl.GetProgramiv(sp.program, gl.LINK_STATUS, &status)
@@ -143,18 +254,47 @@ func (sp *ShaderProgram) Link(vertex, fragment Shader) error {
 		linkLog := strings.Repeat("\x00", int(logLength+1))
 		gl.GetProgramInfoLog(sp.program, logLength, nil, gl.Str(linkLog))
 
+		sp.Release()
 		return fmt.Errorf("failed to link program: %v", linkLog)
 	}
 	sp.linked = true
+	sp.introspect()
 
 	return nil
 }
 
+// cStrings appends a terminating zero to each string, as gl.Strs wants.
+func cStrings(strs []string) []string {
+	res := make([]string, len(strs))
+	for i, s := range strs {
+		res[i] = s + "\x00"
+	}
+	return res
+}
+
 // Linked returns true if a shader program is linked
 func (sp *ShaderProgram) Linked() bool {
 	return sp.linked
 }
 
+// Release deletes the OpenGL program. Attribute locations and transform
+// feedback varyings are kept, so it may be linked again afterwards.
+func (sp *ShaderProgram) Release() {
+	sp.handle.release()
+	sp.handle = nil
+	sp.program = 0
+	sp.linked = false
+	sp.uniforms = nil
+	sp.attributes, sp.activeUniforms, sp.blocks = nil, nil, nil
+}
+
+// replace deletes the program and takes over a freshly linked one, so that
+// everyone holding the program gets the new one.
+func (sp *ShaderProgram) replace(fresh *ShaderProgram) {
+	sp.Release()
+	*sp = *fresh
+}
+
 // Use method makes OpenGL to use the shader program
 func (sp *ShaderProgram) Use() {
 	gl.UseProgram(sp.Program())
@@ -0,0 +1,34 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"testing"
+)
+
+func TestCheckStages(t *testing.T) {
+	vertex := Shader{t: VertexShader, compiled: true}
+	geometry := Shader{t: GeometryShader, compiled: true}
+	fragment := Shader{t: FragmentShader, compiled: true}
+
+	cases := []struct {
+		shaders  []Shader
+		feedback bool
+		ok       bool
+	}{
+		{[]Shader{vertex, fragment}, false, true},
+		{[]Shader{vertex, geometry, fragment}, false, true},
+		{[]Shader{geometry, fragment}, false, false},
+		{[]Shader{vertex, vertex, fragment}, false, false},
+		{[]Shader{vertex, {t: FragmentShader}}, false, false},
+		// Programs capturing outputs don't need fragment shaders
+		{[]Shader{vertex}, false, false},
+		{[]Shader{vertex, geometry}, true, true},
+	}
+	for i, c := range cases {
+		err := checkStages(c.shaders, c.feedback)
+		if (err == nil) != c.ok {
+			t.Errorf("Case %d: checkStages() = %v, want ok = %v", i, err, c.ok)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed shaders
+var embeddedShaders embed.FS
+
+// Shaders loads shaders of this package, like the ones of sprite batches, from
+// files in the shaders directory, which are embedded into the binary. To edit
+// them without rebuilding, turn hot reload on and read them from the source
+// directory instead:
+//
+//	EnableHotReload(DefaultReloadInterval)
+//	Shaders.SetFS(os.DirFS("opengl/gl33/shaders"))
+var Shaders = NewShaderLoader(shaderFS())
+
+func shaderFS() fs.FS {
+	sub, err := fs.Sub(embeddedShaders, "shaders")
+	if err != nil {
+		// That's only possible if the pattern above is broken
+		panic(err)
+	}
+	return sub
+}
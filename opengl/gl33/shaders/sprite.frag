@@ -0,0 +1,13 @@
+#version 330 core
+in vec2 fragTexCoord;
+in vec4 fragColor;
+out vec4 color;
+uniform sampler2D tex;
+uniform bool textured;
+void main() {
+    if (textured) {
+        color = texture(tex, fragTexCoord) * fragColor;
+    } else {
+        color = fragColor;
+    }
+}
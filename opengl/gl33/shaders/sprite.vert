@@ -0,0 +1,12 @@
+#version 330 core
+in vec2 pos;
+in vec2 uv;
+in vec4 color;
+out vec2 fragTexCoord;
+out vec4 fragColor;
+uniform mat4 proj;
+void main() {
+    gl_Position = proj * vec4(pos, 0.0, 1.0);
+    fragTexCoord = uv;
+    fragColor = color;
+}
@@ -0,0 +1,374 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+
+	g "github.com/Sergobot/Rocky/geometry"
+	"github.com/Sergobot/Rocky/paint"
+)
+
+// BlendMode tells how a sprite is mixed with what's under it.
+type BlendMode int
+
+// Blend modes. BlendAlpha is the default one.
+const (
+	// BlendAlpha covers what's under a sprite according to its alpha
+	BlendAlpha BlendMode = iota
+	// BlendAdditive adds colors of a sprite, like light does
+	BlendAdditive BlendMode = iota
+	// BlendMultiply multiplies colors under a sprite by its colors, like
+	// shadows do
+	BlendMultiply BlendMode = iota
+	// BlendOpaque replaces what's under a sprite, ignoring its alpha
+	BlendOpaque BlendMode = iota
+)
+
+// SortMode tells the order sprites are drawn in.
+type SortMode int
+
+// Sort modes. SortNone is the default one.
+const (
+	// SortNone draws sprites in the order they were added, merging only
+	// neighbours with the same texture and blend mode into a draw call
+	SortNone SortMode = iota
+	// SortTexture groups sprites by blend mode and texture, so there is a
+	// draw call for each of their combinations. Sprites with the same texture
+	// keep their order, but the order between textures is lost, so use it
+	// only for sprites not overlapping each other or with the same texture.
+	SortTexture SortMode = iota
+)
+
+// Sprite is a textured quad drawn by a SpriteBatch.
+type Sprite struct {
+	// Texture is OpenGL name of a texture, see Texture.ID. With 0 a sprite is
+	// filled with its color.
+	Texture uint32
+	// Rect the sprite covers, in units of the projection given to Begin
+	Rect g.RectF
+	// UV is the part of the texture drawn, in texture coordinates. Zero UV is
+	// the whole texture.
+	UV g.RectF
+	// Color the texture is multiplied by
+	Color paint.Color
+	// Rotation in radians, clockwise, around the center of the sprite
+	Rotation float32
+	Blend    BlendMode
+}
+
+// spriteFloats is the number of floats per sprite vertex: position (X, Y),
+// texture coordinates (U, V) and color (R, G, B, A).
+const spriteFloats = 8
+
+var spriteLayout = VertexLayout{
+	Stride: spriteFloats,
+	Attribs: []VertexAttrib{
+		{Name: "pos", Size: 2, Offset: 0},
+		{Name: "uv", Size: 2, Offset: 2},
+		{Name: "color", Size: 4, Offset: 4},
+	},
+}
+
+// MaxBatchSprites is the maximum number of sprites drawn in a single draw
+// call. Batches with more sprites are split.
+const MaxBatchSprites = 16384
+
+// quad is a sprite turned into vertices, waiting to be drawn.
+type quad struct {
+	texture uint32
+	blend   BlendMode
+	verts   [4 * spriteFloats]float32
+}
+
+// batchRun is a range of quads drawn with a single draw call.
+type batchRun struct {
+	texture     uint32
+	blend       BlendMode
+	first, size int
+}
+
+// SpriteBatch draws many textured quads with a few draw calls. Quads are
+// collected between Begin and End and streamed into a single vertex buffer,
+// then drawn with a draw call per run of quads with the same texture and
+// blend mode. Use an atlas, see NewAtlas, to have a few textures only.
+type SpriteBatch struct {
+	sortMode SortMode
+
+	proj    mgl32.Mat4
+	drawing bool
+
+	quads []quad
+	order []int
+	verts []float32
+
+	vao, vbo, ebo uint32
+	program       *ShaderProgram
+	ready         bool
+}
+
+// NewSpriteBatch returns a SpriteBatch with OpenGL objects created, so
+// OpenGL has to be initialized.
+func NewSpriteBatch() (*SpriteBatch, error) {
+	if !Initialized() {
+		return nil, fmt.Errorf("Failed to create sprite batch: OpenGL is not initialized")
+	}
+	b := new(SpriteBatch)
+	if err := b.init(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// init loads the shader program, if it's not loaded yet, and creates buffers.
+func (b *SpriteBatch) init() error {
+	sp, err := Shaders.Program("sprite.vert", "sprite.frag")
+	if err != nil {
+		return fmt.Errorf("Failed to prepare sprite shader program: %v", err)
+	}
+	b.program = sp
+	if err := sp.CheckLayout(spriteLayout); err != nil {
+		return fmt.Errorf("Failed to set up sprite vertices: %v", err)
+	}
+
+	b.vao = GenVertexArray()
+	gl.BindVertexArray(b.vao)
+
+	b.vbo = GenBuffer()
+	gl.BindBuffer(gl.ARRAY_BUFFER, b.vbo)
+
+	// Indices never change: two triangles for every quad
+	indices := make([]uint32, 0, MaxBatchSprites*6)
+	for i := uint32(0); i < MaxBatchSprites; i++ {
+		v := i * 4
+		indices = append(indices, v, v+1, v+2, v, v+2, v+3)
+	}
+	b.ebo = GenBuffer()
+	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, b.ebo)
+	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
+	sp.SetupLayout(spriteLayout)
+
+	gl.BindVertexArray(0)
+	b.ready = true
+	return nil
+}
+
+// SetSortMode sets the order sprites are drawn in.
+func (b *SpriteBatch) SetSortMode(m SortMode) {
+	b.sortMode = m
+}
+
+// SortMode returns the order sprites are drawn in.
+func (b *SpriteBatch) SortMode() SortMode {
+	return b.sortMode
+}
+
+// Begin starts collecting sprites. Sprite coordinates are transformed with
+// proj, which may be WindowProjection for normalized window coordinates.
+func (b *SpriteBatch) Begin(proj mgl32.Mat4) {
+	if b.drawing {
+		log.Println("SpriteBatch.Begin called twice, flushing sprites collected")
+		b.Flush()
+	}
+	b.proj = proj
+	b.drawing = true
+}
+
+// Drawing returns true between Begin and End.
+func (b *SpriteBatch) Drawing() bool {
+	return b.drawing
+}
+
+// Draw adds a sprite to the batch.
+func (b *SpriteBatch) Draw(s Sprite) {
+	r := s.Rect
+	corners := [4]g.PointF{
+		{X: r.X, Y: r.Y},
+		{X: r.X + r.W, Y: r.Y},
+		{X: r.X + r.W, Y: r.Y + r.H},
+		{X: r.X, Y: r.Y + r.H},
+	}
+	if s.Rotation != 0 {
+		t := g.Rotation(s.Rotation, g.PointF{X: r.X + r.W/2, Y: r.Y + r.H/2})
+		for i := range corners {
+			corners[i] = t.Apply(corners[i])
+		}
+	}
+	b.DrawQuad(s.Texture, corners, s.UV, s.Color, s.Blend)
+}
+
+// DrawQuad adds a quad of any shape to the batch: corners are top-left,
+// top-right, bottom-right and bottom-left corners of the texture part uv.
+// Zero uv is the whole texture.
+func (b *SpriteBatch) DrawQuad(texture uint32, corners [4]g.PointF, uv g.RectF, col paint.Color, blend BlendMode) {
+	if !b.drawing {
+		log.Println("Prevented adding a sprite to a SpriteBatch outside of Begin and End")
+		return
+	}
+	if uv == (g.RectF{}) {
+		uv.W, uv.H = 1, 1
+	}
+
+	q := quad{texture: texture, blend: blend}
+	uvs := [4]g.PointF{
+		{X: uv.X, Y: uv.Y},
+		{X: uv.X + uv.W, Y: uv.Y},
+		{X: uv.X + uv.W, Y: uv.Y + uv.H},
+		{X: uv.X, Y: uv.Y + uv.H},
+	}
+	for i := range corners {
+		v := q.verts[i*spriteFloats : (i+1)*spriteFloats]
+		v[0], v[1] = corners[i].X, corners[i].Y
+		v[2], v[3] = uvs[i].X, uvs[i].Y
+		v[4], v[5], v[6], v[7] = col.R, col.G, col.B, col.A
+	}
+	b.quads = append(b.quads, q)
+}
+
+// Len returns the number of sprites collected since Begin or the last Flush.
+func (b *SpriteBatch) Len() int {
+	return len(b.quads)
+}
+
+// byState sorts quads by blend mode and texture.
+type byState struct {
+	order []int
+	quads []quad
+}
+
+func (s byState) Len() int      { return len(s.order) }
+func (s byState) Swap(i, j int) { s.order[i], s.order[j] = s.order[j], s.order[i] }
+func (s byState) Less(i, j int) bool {
+	a, b := &s.quads[s.order[i]], &s.quads[s.order[j]]
+	if a.blend != b.blend {
+		return a.blend < b.blend
+	}
+	return a.texture < b.texture
+}
+
+// runs puts quads in drawing order and splits them into runs of the same
+// texture and blend mode, at most MaxBatchSprites long. Quads in order are
+// indices of quads.
+func runs(quads []quad, order []int, m SortMode) []batchRun {
+	if m == SortTexture {
+		sort.Stable(byState{order, quads})
+	}
+
+	var res []batchRun
+	for i, qi := range order {
+		q := &quads[qi]
+		if n := len(res); n > 0 {
+			last := &res[n-1]
+			if last.texture == q.texture && last.blend == q.blend && last.size < MaxBatchSprites {
+				last.size++
+				continue
+			}
+		}
+		res = append(res, batchRun{texture: q.texture, blend: q.blend, first: i, size: 1})
+	}
+	return res
+}
+
+// Flush draws sprites collected so far.
+func (b *SpriteBatch) Flush() {
+	if len(b.quads) == 0 {
+		return
+	}
+	if !b.ready {
+		log.Println("Prevented drawing a not ready SpriteBatch")
+		b.quads = b.quads[:0]
+		return
+	}
+
+	b.order = b.order[:0]
+	for i := range b.quads {
+		b.order = append(b.order, i)
+	}
+	rs := runs(b.quads, b.order, b.sortMode)
+
+	b.verts = b.verts[:0]
+	for _, i := range b.order {
+		b.verts = append(b.verts, b.quads[i].verts[:]...)
+	}
+
+	sp := b.program
+	sp.Use()
+	sp.SetMat4("proj", b.proj)
+	sp.SetSampler("tex", 0)
+
+	gl.BindVertexArray(b.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, b.vbo)
+	// Buffer is reallocated every time, so the driver doesn't wait for the
+	// previous draw calls to finish reading it
+	gl.BufferData(gl.ARRAY_BUFFER, len(b.verts)*4, gl.Ptr(b.verts), gl.STREAM_DRAW)
+
+	gl.ActiveTexture(gl.TEXTURE0)
+	bound := uint32(math.MaxUint32)
+	for _, r := range rs {
+		if r.texture != bound {
+			gl.BindTexture(gl.TEXTURE_2D, r.texture)
+			bound = r.texture
+			if r.texture != 0 {
+				stats.TextureBinds++
+			}
+			sp.SetBool("textured", r.texture != 0)
+		}
+		setBlend(r.blend)
+
+		// Indices are the same for every quad, so the first vertex is moved
+		// instead of indices
+		gl.DrawElementsBaseVertex(gl.TRIANGLES, int32(r.size*6), gl.UNSIGNED_INT, nil, int32(r.first*4))
+		stats.DrawCalls++
+	}
+
+	gl.BindVertexArray(0)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+	setBlend(BlendAlpha)
+
+	b.quads = b.quads[:0]
+}
+
+// setBlend sets OpenGL blending for a blend mode.
+func setBlend(m BlendMode) {
+	switch m {
+	case BlendAdditive:
+		gl.Enable(gl.BLEND)
+		gl.BlendFunc(gl.SRC_ALPHA, gl.ONE)
+	case BlendMultiply:
+		gl.Enable(gl.BLEND)
+		gl.BlendFunc(gl.DST_COLOR, gl.ONE_MINUS_SRC_ALPHA)
+	case BlendOpaque:
+		gl.Disable(gl.BLEND)
+	default:
+		gl.Enable(gl.BLEND)
+		gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	}
+}
+
+// End draws all the sprites collected and stops collecting them.
+func (b *SpriteBatch) End() {
+	if !b.drawing {
+		log.Println("SpriteBatch.End called without Begin")
+		return
+	}
+	b.Flush()
+	b.drawing = false
+}
+
+// Release deletes OpenGL objects of the batch. It can't be used afterwards.
+func (b *SpriteBatch) Release() {
+	if !b.ready {
+		return
+	}
+	DeleteVertexArray(&b.vao)
+	DeleteBuffer(&b.vbo)
+	DeleteBuffer(&b.ebo)
+	b.quads = nil
+	b.ready = false
+}
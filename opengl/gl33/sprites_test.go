@@ -0,0 +1,63 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"math"
+	"testing"
+
+	g "github.com/Sergobot/Rocky/geometry"
+	"github.com/Sergobot/Rocky/paint"
+)
+
+func TestSpriteVertices(t *testing.T) {
+	b := &SpriteBatch{drawing: true}
+	b.Draw(Sprite{
+		Texture:  1,
+		Rect:     g.RectF{PosF: g.PosF{X: 1, Y: 1}, SizeF: g.SizeF{W: 2, H: 4}},
+		Color:    paint.White,
+		Rotation: math.Pi / 2,
+	})
+	if b.Len() != 1 {
+		t.Fatalf("Len() = %d, want 1", b.Len())
+	}
+
+	// Rotated by 90 degrees clockwise around (2, 3), the top-left corner goes
+	// to the top-right
+	v := b.quads[0].verts
+	if math.Abs(float64(v[0]-4)) > 1e-5 || math.Abs(float64(v[1]-2)) > 1e-5 {
+		t.Errorf("Top-left corner is at (%v, %v), want (4, 2)", v[0], v[1])
+	}
+	// Zero UV is the whole texture
+	if v[2*spriteFloats+2] != 1 || v[2*spriteFloats+3] != 1 {
+		t.Errorf("UV of the bottom-right corner is (%v, %v), want (1, 1)", v[2*spriteFloats+2], v[2*spriteFloats+3])
+	}
+}
+
+func TestSpriteRuns(t *testing.T) {
+	textures := []uint32{1, 1, 2, 1, 2}
+	build := func() ([]quad, []int) {
+		quads := make([]quad, len(textures))
+		order := make([]int, len(textures))
+		for i, tex := range textures {
+			quads[i].texture = tex
+			order[i] = i
+		}
+		return quads, order
+	}
+
+	quads, order := build()
+	if rs := runs(quads, order, SortNone); len(rs) != 4 || rs[0].size != 2 {
+		t.Errorf("Unsorted runs are %+v, want 4 with the first of 2 sprites", rs)
+	}
+
+	quads, order = build()
+	rs := runs(quads, order, SortTexture)
+	if len(rs) != 2 || rs[0].size != 3 || rs[1].texture != 2 {
+		t.Errorf("Sorted runs are %+v, want 3 sprites of texture 1 and 2 of texture 2", rs)
+	}
+	// Sprites of the same texture keep their order
+	if order[0] != 0 || order[1] != 1 || order[2] != 3 {
+		t.Errorf("Sorted order is %v, want 0, 1, 3 first", order)
+	}
+}
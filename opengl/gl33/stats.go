@@ -0,0 +1,31 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+// Stats are counters of what's done with OpenGL. Draw calls and texture binds
+// are counted since the last ResetStats, which is usually called every frame.
+type Stats struct {
+	DrawCalls    int
+	TextureBinds int
+	// Textures loaded and not replaced with other images
+	Textures int
+}
+
+var stats Stats
+
+// CountDrawCall adds a draw call to the stats. Widgets call it after every
+// glDraw* call.
+func CountDrawCall() {
+	stats.DrawCalls++
+}
+
+// CurrentStats returns counters accumulated since the last ResetStats.
+func CurrentStats() Stats {
+	return stats
+}
+
+// ResetStats zeroes per-frame counters: draw calls and texture binds.
+func ResetStats() {
+	stats.DrawCalls = 0
+	stats.TextureBinds = 0
+}
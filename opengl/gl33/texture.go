@@ -3,9 +3,12 @@
 package gl33
 
 import (
+	"bytes"
 	"fmt"
 	"image"
 	"image/draw"
+	"io"
+	"io/fs"
 	"os"
 
 	// Packages image/jpeg and image/png are not used explicitly in
@@ -15,6 +18,8 @@ import (
 	_ "image/png"
 
 	"github.com/go-gl/gl/v3.3-core/gl"
+
+	g "github.com/Sergobot/Rocky/geometry"
 )
 
 // Texture struct contains information about an OpenGL texture:
@@ -23,8 +28,9 @@ import (
 // - Its width and height
 // Also Texture can load a texture from image.
 type Texture struct {
-	// OpenGL texture ID
+	// OpenGL texture ID and its owner
 	texture uint32
+	handle  *handle
 
 	// OpenGL texture unit is required if there are more than one texture
 	// used in a single shader.
@@ -35,28 +41,81 @@ type Texture struct {
 
 	// Size of the texture
 	width, height int
+
+	// Sampling settings and whether mipmaps of the current image are made
+	options   TextureOptions
+	mipmapped bool
 }
 
-// LoadFromFile loads texture from an image file.
+// LoadFromFile loads texture from an image file. With hot reload on, the file
+// is watched for changes, see EnableHotReload, and the texture is kept alive
+// until it's released.
 func (t *Texture) LoadFromFile(file string) error {
-	if t.ready {
-		gl.DeleteTextures(1, &t.texture)
+	imgFile, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("Failed to load texture from %q: %v", file, err)
 	}
+	defer imgFile.Close()
 
-	t.ready = false
+	if err := t.LoadFromReader(imgFile); err != nil {
+		return err
+	}
+	watchTexture(t, file)
+	return nil
+}
 
-	// Load an image from file
-	imgFile, err := os.Open(file)
+// LoadFromFS loads texture from an image file in a file system, for example
+// one embedded into the binary with go:embed.
+func (t *Texture) LoadFromFS(fsys fs.FS, path string) error {
+	imgFile, err := fsys.Open(path)
 	if err != nil {
-		return fmt.Errorf("Failed to load texture from %q: %v", file, err)
+		return fmt.Errorf("Failed to load texture from %q: %v", path, err)
 	}
+	defer imgFile.Close()
 
-	// Decode the newly loaded image
-	img, _, err := image.Decode(imgFile)
+	return t.LoadFromReader(imgFile)
+}
+
+// LoadFromBytes loads texture from an encoded image, PNG or JPEG.
+func (t *Texture) LoadFromBytes(data []byte) error {
+	return t.LoadFromReader(bytes.NewReader(data))
+}
+
+// LoadFromReader loads texture from an encoded image, PNG or JPEG, read from r.
+func (t *Texture) LoadFromReader(r io.Reader) error {
+	img, _, err := image.Decode(r)
 	if err != nil {
 		return fmt.Errorf("Failed to decode an image: %v", err)
 	}
 
+	return t.loadImage(t, img)
+}
+
+// LoadFromImage uploads an image, for example a generated one, to the texture.
+func (t *Texture) LoadFromImage(img image.Image) error {
+	if img == nil || img.Bounds().Empty() {
+		return fmt.Errorf("Failed to load texture from an empty image")
+	}
+	return t.loadImage(t, img)
+}
+
+// loadImage uploads an image to the texture. The image doesn't come from the
+// file the texture may have been loaded from before, so on success that file
+// isn't watched anymore: w is the texture, which watches it. LoadFromFile
+// watches the file again.
+func (t *Texture) loadImage(w fileLoader, img image.Image) error {
+	if err := t.load(img); err != nil {
+		return err
+	}
+	unwatchTexture(w)
+	return nil
+}
+
+// load uploads an image to the texture. The previous image is deleted only
+// now, so a texture, which failed to load, keeps showing it.
+func (t *Texture) load(img image.Image) error {
+	t.release()
+
 	// Convert the image to unified format - RGBA
 	rgba := image.NewRGBA(img.Bounds())
 	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
@@ -65,18 +124,15 @@ func (t *Texture) LoadFromFile(file string) error {
 	t.width, t.height = rgba.Bounds().Dx(), rgba.Bounds().Dy()
 
 	gl.GenTextures(1, &t.texture)
+	t.handle = newHandle(TextureObject, t.texture)
 
-	err = t.Bind()
-	if err != nil {
-		return err
-	}
+	// Bind and Unbind refuse to work with textures not ready yet, so the
+	// texture is bound directly
+	gl.ActiveTexture(gl.TEXTURE0 + t.unit)
+	gl.BindTexture(gl.TEXTURE_2D, t.texture)
 
-	// Set texture filtering
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
-	// Set texture parameters
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	// Set texture filtering and wrapping
+	t.options.apply()
 
 	// Create texture
 	gl.TexImage2D(
@@ -90,15 +146,63 @@ func (t *Texture) LoadFromFile(file string) error {
 		gl.UNSIGNED_BYTE,
 		gl.Ptr(rgba.Pix))
 
-	gl.GenerateMipmap(gl.TEXTURE_2D)
+	t.mipmapped = t.options.mipmapped()
+	if t.mipmapped {
+		gl.GenerateMipmap(gl.TEXTURE_2D)
+	}
 
 	// It's a good practice to unbind once we are done
-	err = t.Unbind()
+	gl.BindTexture(gl.TEXTURE_2D, 0)
 
 	// Now image is loaded and initialized, so we can use it as an OpenGL texture
 	t.ready = true
 
-	return err
+	return nil
+}
+
+// Release deletes the OpenGL texture. It may be loaded again afterwards.
+func (t *Texture) Release() {
+	unwatchTexture(t)
+	t.release()
+}
+
+// release deletes the OpenGL texture, but keeps the file watched.
+func (t *Texture) release() {
+	t.handle.release()
+	t.handle = nil
+	t.texture = 0
+	t.ready = false
+}
+
+// SetOptions changes how the texture is sampled. It may be called before or
+// after an image is loaded.
+func (t *Texture) SetOptions(o TextureOptions) {
+	t.options = o
+	if !t.ready {
+		return
+	}
+
+	// Options may be changed in the middle of a frame, so the texture's own
+	// unit is used and whatever was bound there is restored afterwards
+	var active, bound int32
+	gl.GetIntegerv(gl.ACTIVE_TEXTURE, &active)
+	gl.ActiveTexture(gl.TEXTURE0 + t.unit)
+	gl.GetIntegerv(gl.TEXTURE_BINDING_2D, &bound)
+
+	gl.BindTexture(gl.TEXTURE_2D, t.texture)
+	o.apply()
+	if o.mipmapped() && !t.mipmapped {
+		gl.GenerateMipmap(gl.TEXTURE_2D)
+		t.mipmapped = true
+	}
+
+	gl.BindTexture(gl.TEXTURE_2D, uint32(bound))
+	gl.ActiveTexture(uint32(active))
+}
+
+// Options returns how the texture is sampled.
+func (t *Texture) Options() TextureOptions {
+	return t.options
 }
 
 // Ready returns true if image is already loaded and the texture is ready to be used
@@ -114,6 +218,7 @@ func (t *Texture) Bind() error {
 
 	gl.ActiveTexture(gl.TEXTURE0 + t.unit)
 	gl.BindTexture(gl.TEXTURE_2D, t.texture)
+	stats.TextureBinds++
 	return nil
 }
 
@@ -128,6 +233,19 @@ func (t *Texture) Unbind() error {
 	return nil
 }
 
+// ID returns OpenGL name of the texture, 0 if it isn't loaded.
+func (t *Texture) ID() uint32 {
+	if !t.ready {
+		return 0
+	}
+	return t.texture
+}
+
+// Size returns size of the texture in pixels.
+func (t *Texture) Size() g.Size {
+	return g.Size{W: t.width, H: t.height}
+}
+
 // SetUnit sets a unit the texture is using while drawing.
 // Required when few textures are used in a single shader.
 func (t *Texture) SetUnit(unit uint32) {
@@ -0,0 +1,151 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"log"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Uniform returns location of a uniform. Locations are cached, so that's cheap
+// to call every frame. Unknown uniforms, including ones optimized out by the
+// driver, are -1: setting them does nothing. There is a warning about each of
+// them, but only the first time.
+func (sp *ShaderProgram) Uniform(name string) int32 {
+	if !sp.linked {
+		return -1
+	}
+	if loc, ok := sp.uniforms[name]; ok {
+		return loc
+	}
+
+	loc := gl.GetUniformLocation(sp.program, gl.Str(name+"\x00"))
+	if loc < 0 {
+		log.Printf("Uniform %q not found in shader program %d", name, sp.program)
+	}
+	if sp.uniforms == nil {
+		sp.uniforms = make(map[string]int32)
+	}
+	sp.uniforms[name] = loc
+	return loc
+}
+
+// bind makes the program used and returns location of a uniform. The program
+// is used every time: a copy of the program in use could go stale after a
+// direct gl.UseProgram call or with a new OpenGL context, and using a program
+// already in use is cheap anyway.
+func (sp *ShaderProgram) bind(name string) int32 {
+	loc := sp.Uniform(name)
+	if loc >= 0 {
+		sp.Use()
+	}
+	return loc
+}
+
+// SetInt sets an int uniform.
+func (sp *ShaderProgram) SetInt(name string, v int32) {
+	if loc := sp.bind(name); loc >= 0 {
+		gl.Uniform1i(loc, v)
+	}
+}
+
+// SetBool sets a bool uniform.
+func (sp *ShaderProgram) SetBool(name string, v bool) {
+	var i int32
+	if v {
+		i = 1
+	}
+	sp.SetInt(name, i)
+}
+
+// SetSampler makes a sampler uniform read the given texture unit.
+func (sp *ShaderProgram) SetSampler(name string, unit uint32) {
+	sp.SetInt(name, int32(unit))
+}
+
+// SetFloat sets a float uniform.
+func (sp *ShaderProgram) SetFloat(name string, v float32) {
+	if loc := sp.bind(name); loc >= 0 {
+		gl.Uniform1f(loc, v)
+	}
+}
+
+// SetVec2 sets a vec2 uniform.
+func (sp *ShaderProgram) SetVec2(name string, v mgl32.Vec2) {
+	if loc := sp.bind(name); loc >= 0 {
+		gl.Uniform2f(loc, v[0], v[1])
+	}
+}
+
+// SetVec3 sets a vec3 uniform.
+func (sp *ShaderProgram) SetVec3(name string, v mgl32.Vec3) {
+	if loc := sp.bind(name); loc >= 0 {
+		gl.Uniform3f(loc, v[0], v[1], v[2])
+	}
+}
+
+// SetVec4 sets a vec4 uniform.
+func (sp *ShaderProgram) SetVec4(name string, v mgl32.Vec4) {
+	if loc := sp.bind(name); loc >= 0 {
+		gl.Uniform4f(loc, v[0], v[1], v[2], v[3])
+	}
+}
+
+// SetMat3 sets a mat3 uniform.
+func (sp *ShaderProgram) SetMat3(name string, m mgl32.Mat3) {
+	if loc := sp.bind(name); loc >= 0 {
+		gl.UniformMatrix3fv(loc, 1, false, &m[0])
+	}
+}
+
+// SetMat4 sets a mat4 uniform.
+func (sp *ShaderProgram) SetMat4(name string, m mgl32.Mat4) {
+	if loc := sp.bind(name); loc >= 0 {
+		gl.UniformMatrix4fv(loc, 1, false, &m[0])
+	}
+}
+
+// SetInts sets elements of an int array uniform, starting from the first one.
+func (sp *ShaderProgram) SetInts(name string, v []int32) {
+	if loc := sp.bind(name); loc >= 0 && len(v) > 0 {
+		gl.Uniform1iv(loc, int32(len(v)), &v[0])
+	}
+}
+
+// SetFloats sets elements of a float array uniform, starting from the first
+// one.
+func (sp *ShaderProgram) SetFloats(name string, v []float32) {
+	if loc := sp.bind(name); loc >= 0 && len(v) > 0 {
+		gl.Uniform1fv(loc, int32(len(v)), &v[0])
+	}
+}
+
+// SetVec2s sets elements of a vec2 array uniform, starting from the first one.
+func (sp *ShaderProgram) SetVec2s(name string, v []mgl32.Vec2) {
+	if loc := sp.bind(name); loc >= 0 && len(v) > 0 {
+		gl.Uniform2fv(loc, int32(len(v)), &v[0][0])
+	}
+}
+
+// SetVec3s sets elements of a vec3 array uniform, starting from the first one.
+func (sp *ShaderProgram) SetVec3s(name string, v []mgl32.Vec3) {
+	if loc := sp.bind(name); loc >= 0 && len(v) > 0 {
+		gl.Uniform3fv(loc, int32(len(v)), &v[0][0])
+	}
+}
+
+// SetVec4s sets elements of a vec4 array uniform, starting from the first one.
+func (sp *ShaderProgram) SetVec4s(name string, v []mgl32.Vec4) {
+	if loc := sp.bind(name); loc >= 0 && len(v) > 0 {
+		gl.Uniform4fv(loc, int32(len(v)), &v[0][0])
+	}
+}
+
+// SetMat4s sets elements of a mat4 array uniform, starting from the first one.
+func (sp *ShaderProgram) SetMat4s(name string, v []mgl32.Mat4) {
+	if loc := sp.bind(name); loc >= 0 && len(v) > 0 {
+		gl.UniformMatrix4fv(loc, int32(len(v)), false, &v[0][0])
+	}
+}
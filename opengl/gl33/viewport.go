@@ -4,6 +4,7 @@ package gl33
 
 import (
 	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
 
 	g "github.com/Sergobot/Rocky/geometry"
 )
@@ -63,3 +64,21 @@ func NormalizedViewportSize() g.SizeF {
 func ViewportAspectRatio() float32 {
 	return float32(vpW) / float32(vpH)
 }
+
+// NormalizedPixelSize returns size of a single pixel in the same units as
+// NormalizedViewportSize does. Use it to convert pixel sizes, like sizes of
+// textures, to sizes suitable for widgets.
+func NormalizedPixelSize() float32 {
+	if vpW >= vpH {
+		return 2.0 / float32(vpW)
+	}
+	return 2.0 / float32(vpH)
+}
+
+// WindowProjection returns a matrix, which transforms normalized coordinates,
+// with origin at the top-left corner of the viewport and Y axis going down, to
+// OpenGL ones.
+func WindowProjection() mgl32.Mat4 {
+	vpSize := NormalizedViewportSize()
+	return mgl32.Translate3D(-1, 1, 0).Mul4(mgl32.Scale3D(2/vpSize.W, -2/vpSize.H, 1))
+}
@@ -2,11 +2,18 @@
 
 package opengl
 
-import "github.com/Sergobot/Rocky/opengl/gl33"
+import (
+	"image"
+	"io"
+	"io/fs"
+
+	g "github.com/Sergobot/Rocky/geometry"
+	"github.com/Sergobot/Rocky/opengl/gl33"
+)
 
 // Texture is an interface for all the Texture struct in gl**/ subfolders.
 // These struct help to assumed to manage single texture life:
-// - Load from file
+// - Load from file, memory or an image
 // - Check readiness for rendering
 // - Bind/Unbind before/after rendering
 // - Set texture unit to use multiple textures in one shader
@@ -15,11 +22,24 @@ import "github.com/Sergobot/Rocky/opengl/gl33"
 type Texture interface {
 	// LoadFromFile loads texture from an image file
 	LoadFromFile(string) error
+	// LoadFromFS loads texture from an image file in a file system, like one
+	// embedded with go:embed
+	LoadFromFS(fs.FS, string) error
+	// LoadFromReader and LoadFromBytes load texture from an encoded image
+	LoadFromReader(io.Reader) error
+	LoadFromBytes([]byte) error
+	// LoadFromImage uploads an already decoded or generated image
+	LoadFromImage(image.Image) error
 
 	// These two methods manage binding texture for rendering
 	Bind() error
 	Unbind() error
 
+	// SetOptions and Options manage filtering, wrapping and mipmaps. Options
+	// may be changed after an image is loaded.
+	SetOptions(gl33.TextureOptions)
+	Options() gl33.TextureOptions
+
 	// Use these two methods to set texture unit to use multiple textures in a
 	// single shader
 	SetUnit(uint32)
@@ -27,6 +47,17 @@ type Texture interface {
 
 	// If texture is successfully loaded, Ready() will return true
 	Ready() bool
+	// Release deletes the texture from video memory. Release textures not
+	// needed anymore: garbage collected textures are only deleted, if
+	// finalizers are on (see gl33.SetFinalizers), and not right away.
+	Release()
+
+	// ID returns OpenGL name of the texture, for drawing it without binding,
+	// like SpriteBatch does
+	ID() uint32
+
+	// Size returns size of a loaded image in pixels
+	Size() g.Size
 
 	// Returns OpenGL version texture is using. Usually called in widgets to check
 	// if an improper texture was passed.
@@ -0,0 +1,73 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package paint
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+)
+
+// Color is an RGBA color with components in range [0, 1]. That's what OpenGL
+// expects, so it's easier to use this instead of color.Color from image/color.
+// Colors are not premultiplied by alpha.
+type Color struct {
+	R, G, B, A float32
+}
+
+// Some common colors
+var (
+	Transparent = Color{}
+	Black       = Color{0, 0, 0, 1}
+	White       = Color{1, 1, 1, 1}
+)
+
+// FromColor converts any color.Color from image/color to Color.
+func FromColor(c color.Color) Color {
+	nrgba := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return Color{
+		R: float32(nrgba.R) / 255,
+		G: float32(nrgba.G) / 255,
+		B: float32(nrgba.B) / 255,
+		A: float32(nrgba.A) / 255,
+	}
+}
+
+// Lerp linearly interpolates between two colors. t = 0 gives c, t = 1 gives d.
+func (c Color) Lerp(d Color, t float32) Color {
+	return Color{
+		R: c.R + (d.R-c.R)*t,
+		G: c.G + (d.G-c.G)*t,
+		B: c.B + (d.B-c.B)*t,
+		A: c.A + (d.A-c.A)*t,
+	}
+}
+
+// ParseColor parses a color in hex notation: "#rgb", "#rrggbb" or "#rrggbbaa".
+func ParseColor(s string) (Color, error) {
+	if len(s) == 0 || s[0] != '#' {
+		return Color{}, fmt.Errorf("Failed to parse color %q: it must start with #", s)
+	}
+
+	hex := s[1:]
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 {
+		return Color{}, fmt.Errorf("Failed to parse color %q: wrong length", s)
+	}
+
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return Color{}, fmt.Errorf("Failed to parse color %q: %v", s, err)
+	}
+	return Color{
+		R: float32(v>>24&0xff) / 255,
+		G: float32(v>>16&0xff) / 255,
+		B: float32(v>>8&0xff) / 255,
+		A: float32(v&0xff) / 255,
+	}, nil
+}
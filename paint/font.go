@@ -0,0 +1,201 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package paint
+
+import (
+	"image"
+	"image/color"
+	"sync"
+
+	g "github.com/Sergobot/Rocky/geometry"
+)
+
+// Font is a bitmap font: glyphs are cells of an image. Images are white, with
+// coverage in alpha, so they can be tinted with any color.
+type Font struct {
+	// Image has all the glyphs
+	Image *image.NRGBA
+	// Size of a line and advance of every glyph, in pixels of the image
+	LineHeight, Advance int
+
+	glyphs map[rune]g.Rect
+}
+
+// Glyph returns where a glyph is in the image. Runes without a glyph give the
+// glyph of '?'.
+func (f *Font) Glyph(r rune) g.Rect {
+	if rect, ok := f.glyphs[r]; ok {
+		return rect
+	}
+	return f.glyphs['?']
+}
+
+// Measure returns size of a text in pixels of the font. Lines are separated
+// with '\n'.
+func (f *Font) Measure(s string) g.Size {
+	var size g.Size
+	lines, width := 1, 0
+	for _, r := range s {
+		if r == '\n' {
+			lines++
+			width = 0
+			continue
+		}
+		width += f.Advance
+		if width > size.W {
+			size.W = width
+		}
+	}
+	size.H = lines * f.LineHeight
+	return size
+}
+
+var (
+	defaultFont     *Font
+	defaultFontOnce sync.Once
+)
+
+// DefaultFont returns a tiny 5x7 pixels font with printable ASCII characters.
+// It's always available and good enough for debug info and small labels,
+// especially scaled by a whole number.
+func DefaultFont() *Font {
+	defaultFontOnce.Do(func() {
+		defaultFont = newBitmapFont(defaultGlyphs, 5, 7)
+	})
+	return defaultFont
+}
+
+// newBitmapFont makes a Font from glyphs drawn with '#'. There is a pixel of
+// padding around each glyph, so filtering doesn't mix neighbours up.
+func newBitmapFont(glyphs map[rune][]string, w, h int) *Font {
+	const perRow = 16
+	cellW, cellH := w+2, h+2
+	rows := (len(glyphs) + perRow - 1) / perRow
+
+	f := &Font{
+		Image:      image.NewNRGBA(image.Rect(0, 0, perRow*cellW, rows*cellH)),
+		LineHeight: h + 2,
+		Advance:    w + 1,
+		glyphs:     map[rune]g.Rect{},
+	}
+
+	// Glyphs are laid out in the order of runes, so the image is always the same
+	i := 0
+	for r := rune(0); r < 128; r++ {
+		art, ok := glyphs[r]
+		if !ok {
+			continue
+		}
+		x0, y0 := (i%perRow)*cellW+1, (i/perRow)*cellH+1
+		for y, line := range art {
+			for x, c := range line {
+				if c == '#' {
+					f.Image.SetNRGBA(x0+x, y0+y, color.NRGBA{255, 255, 255, 255})
+				} else {
+					f.Image.SetNRGBA(x0+x, y0+y, color.NRGBA{255, 255, 255, 0})
+				}
+			}
+		}
+		f.glyphs[r] = g.Rect{Pos: g.Pos{X: x0, Y: y0}, Size: g.Size{W: w, H: h}}
+		i++
+	}
+	return f
+}
+
+var defaultGlyphs = map[rune][]string{
+	' ':  {".....", ".....", ".....", ".....", ".....", ".....", "....."},
+	'!':  {"..#..", "..#..", "..#..", "..#..", "..#..", ".....", "..#.."},
+	'"':  {".#.#.", ".#.#.", ".....", ".....", ".....", ".....", "....."},
+	'#':  {".#.#.", ".#.#.", "#####", ".#.#.", "#####", ".#.#.", ".#.#."},
+	'$':  {"..#..", ".####", "#.#..", ".###.", "..#.#", "####.", "..#.."},
+	'%':  {"##...", "##..#", "...#.", "..#..", ".#...", "#..##", "...##"},
+	'&':  {".##..", "#..#.", "#.#..", ".#...", "#.#.#", "#..#.", ".##.#"},
+	'\'': {"..#..", "..#..", ".....", ".....", ".....", ".....", "....."},
+	'(':  {"...#.", "..#..", ".#...", ".#...", ".#...", "..#..", "...#."},
+	')':  {".#...", "..#..", "...#.", "...#.", "...#.", "..#..", ".#..."},
+	'*':  {".....", "..#..", "#.#.#", ".###.", "#.#.#", "..#..", "....."},
+	'+':  {".....", "..#..", "..#..", "#####", "..#..", "..#..", "....."},
+	',':  {".....", ".....", ".....", ".....", ".##..", "..#..", ".#..."},
+	'-':  {".....", ".....", ".....", "#####", ".....", ".....", "....."},
+	'.':  {".....", ".....", ".....", ".....", ".....", ".##..", ".##.."},
+	'/':  {".....", "....#", "...#.", "..#..", ".#...", "#....", "....."},
+	'0':  {".###.", "#...#", "#..##", "#.#.#", "##..#", "#...#", ".###."},
+	'1':  {"..#..", ".##..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'2':  {".###.", "#...#", "....#", "...#.", "..#..", ".#...", "#####"},
+	'3':  {"#####", "...#.", "..#..", "...#.", "....#", "#...#", ".###."},
+	'4':  {"...#.", "..##.", ".#.#.", "#..#.", "#####", "...#.", "...#."},
+	'5':  {"#####", "#....", "####.", "....#", "....#", "#...#", ".###."},
+	'6':  {"..##.", ".#...", "#....", "####.", "#...#", "#...#", ".###."},
+	'7':  {"#####", "....#", "...#.", "..#..", ".#...", ".#...", ".#..."},
+	'8':  {".###.", "#...#", "#...#", ".###.", "#...#", "#...#", ".###."},
+	'9':  {".###.", "#...#", "#...#", ".####", "....#", "...#.", ".##.."},
+	':':  {".....", ".##..", ".##..", ".....", ".##..", ".##..", "....."},
+	';':  {".....", ".##..", ".##..", ".....", ".##..", "..#..", ".#..."},
+	'<':  {"...#.", "..#..", ".#...", "#....", ".#...", "..#..", "...#."},
+	'=':  {".....", ".....", "#####", ".....", "#####", ".....", "....."},
+	'>':  {".#...", "..#..", "...#.", "....#", "...#.", "..#..", ".#..."},
+	'?':  {".###.", "#...#", "....#", "...#.", "..#..", ".....", "..#.."},
+	'@':  {".###.", "#...#", "....#", ".##.#", "#.#.#", "#.#.#", ".###."},
+	'A':  {".###.", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+	'B':  {"####.", "#...#", "#...#", "####.", "#...#", "#...#", "####."},
+	'C':  {".###.", "#...#", "#....", "#....", "#....", "#...#", ".###."},
+	'D':  {"###..", "#..#.", "#...#", "#...#", "#...#", "#..#.", "###.."},
+	'E':  {"#####", "#....", "#....", "####.", "#....", "#....", "#####"},
+	'F':  {"#####", "#....", "#....", "####.", "#....", "#....", "#...."},
+	'G':  {".###.", "#...#", "#....", "#.###", "#...#", "#...#", ".####"},
+	'H':  {"#...#", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+	'I':  {".###.", "..#..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'J':  {"..###", "...#.", "...#.", "...#.", "...#.", "#..#.", ".##.."},
+	'K':  {"#...#", "#..#.", "#.#..", "##...", "#.#..", "#..#.", "#...#"},
+	'L':  {"#....", "#....", "#....", "#....", "#....", "#....", "#####"},
+	'M':  {"#...#", "##.##", "#.#.#", "#.#.#", "#...#", "#...#", "#...#"},
+	'N':  {"#...#", "#...#", "##..#", "#.#.#", "#..##", "#...#", "#...#"},
+	'O':  {".###.", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'P':  {"####.", "#...#", "#...#", "####.", "#....", "#....", "#...."},
+	'Q':  {".###.", "#...#", "#...#", "#...#", "#.#.#", "#..#.", ".##.#"},
+	'R':  {"####.", "#...#", "#...#", "####.", "#.#..", "#..#.", "#...#"},
+	'S':  {".####", "#....", "#....", ".###.", "....#", "....#", "####."},
+	'T':  {"#####", "..#..", "..#..", "..#..", "..#..", "..#..", "..#.."},
+	'U':  {"#...#", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'V':  {"#...#", "#...#", "#...#", "#...#", "#...#", ".#.#.", "..#.."},
+	'W':  {"#...#", "#...#", "#...#", "#.#.#", "#.#.#", "#.#.#", ".#.#."},
+	'X':  {"#...#", "#...#", ".#.#.", "..#..", ".#.#.", "#...#", "#...#"},
+	'Y':  {"#...#", "#...#", ".#.#.", "..#..", "..#..", "..#..", "..#.."},
+	'Z':  {"#####", "....#", "...#.", "..#..", ".#...", "#....", "#####"},
+	'[':  {".###.", ".#...", ".#...", ".#...", ".#...", ".#...", ".###."},
+	'\\': {".....", "#....", ".#...", "..#..", "...#.", "....#", "....."},
+	']':  {".###.", "...#.", "...#.", "...#.", "...#.", "...#.", ".###."},
+	'^':  {"..#..", ".#.#.", "#...#", ".....", ".....", ".....", "....."},
+	'_':  {".....", ".....", ".....", ".....", ".....", ".....", "#####"},
+	'`':  {".#...", "..#..", ".....", ".....", ".....", ".....", "....."},
+	'a':  {".....", ".....", ".###.", "....#", ".####", "#...#", ".####"},
+	'b':  {"#....", "#....", "#.##.", "##..#", "#...#", "#...#", "####."},
+	'c':  {".....", ".....", ".###.", "#....", "#....", "#...#", ".###."},
+	'd':  {"....#", "....#", ".##.#", "#..##", "#...#", "#...#", ".####"},
+	'e':  {".....", ".....", ".###.", "#...#", "#####", "#....", ".###."},
+	'f':  {"..##.", ".#..#", ".#...", "###..", ".#...", ".#...", ".#..."},
+	'g':  {".....", ".####", "#...#", "#...#", ".####", "....#", ".###."},
+	'h':  {"#....", "#....", "#.##.", "##..#", "#...#", "#...#", "#...#"},
+	'i':  {"..#..", ".....", ".##..", "..#..", "..#..", "..#..", ".###."},
+	'j':  {"...#.", ".....", "..##.", "...#.", "...#.", "#..#.", ".##.."},
+	'k':  {"#....", "#....", "#..#.", "#.#..", "##...", "#.#..", "#..#."},
+	'l':  {".##..", "..#..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'm':  {".....", ".....", "##.#.", "#.#.#", "#.#.#", "#...#", "#...#"},
+	'n':  {".....", ".....", "#.##.", "##..#", "#...#", "#...#", "#...#"},
+	'o':  {".....", ".....", ".###.", "#...#", "#...#", "#...#", ".###."},
+	'p':  {".....", ".....", "####.", "#...#", "####.", "#....", "#...."},
+	'q':  {".....", ".....", ".##.#", "#..##", ".####", "....#", "....#"},
+	'r':  {".....", ".....", "#.##.", "##..#", "#....", "#....", "#...."},
+	's':  {".....", ".....", ".###.", "#....", ".###.", "....#", "####."},
+	't':  {".#...", ".#...", "###..", ".#...", ".#...", ".#..#", "..##."},
+	'u':  {".....", ".....", "#...#", "#...#", "#...#", "#..##", ".##.#"},
+	'v':  {".....", ".....", "#...#", "#...#", "#...#", ".#.#.", "..#.."},
+	'w':  {".....", ".....", "#...#", "#...#", "#.#.#", "#.#.#", ".#.#."},
+	'x':  {".....", ".....", "#...#", ".#.#.", "..#..", ".#.#.", "#...#"},
+	'y':  {".....", ".....", "#...#", "#...#", ".####", "....#", ".###."},
+	'z':  {".....", ".....", "#####", "...#.", "..#..", ".#...", "#####"},
+	'{':  {"...#.", "..#..", "..#..", ".#...", "..#..", "..#..", "...#."},
+	'|':  {"..#..", "..#..", "..#..", "..#..", "..#..", "..#..", "..#.."},
+	'}':  {".#...", "..#..", "..#..", "...#.", "..#..", "..#..", ".#..."},
+	'~':  {".....", ".....", ".#...", "#.#.#", "...#.", ".....", "....."},
+}
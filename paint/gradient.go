@@ -0,0 +1,58 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package paint
+
+import g "github.com/Sergobot/Rocky/geometry"
+
+// GradientType variables tell the shape of a gradient: linear or radial.
+type GradientType int
+
+// Supported gradient types
+const (
+	// Linear gradient changes color along the line from From to To.
+	Linear GradientType = iota
+	// Radial gradient changes color from From (center) to To (a point on the
+	// outer circle).
+	Radial GradientType = iota
+)
+
+// MaxStops is the maximum number of color stops in a gradient. Extra ones are
+// ignored while drawing.
+const MaxStops = 8
+
+// Stop is a single color stop of a gradient. Offset is in range [0, 1].
+type Stop struct {
+	Offset float32
+	Color  Color
+}
+
+// Gradient describes a color gradient. From and To are relative to the bounding
+// box of a shape: {0, 0} is its top-left corner and {1, 1} is bottom-right one.
+// Stops must be sorted by their offsets.
+type Gradient struct {
+	Type     GradientType
+	From, To g.PointF
+	Stops    []Stop
+}
+
+// At returns color of the gradient at offset t in range [0, 1].
+func (gr *Gradient) At(t float32) Color {
+	if len(gr.Stops) == 0 {
+		return Transparent
+	}
+	if t <= gr.Stops[0].Offset {
+		return gr.Stops[0].Color
+	}
+
+	for i := 1; i < len(gr.Stops); i++ {
+		prev, next := gr.Stops[i-1], gr.Stops[i]
+		if t <= next.Offset {
+			if next.Offset == prev.Offset {
+				return next.Color
+			}
+			return prev.Color.Lerp(next.Color, (t-prev.Offset)/(next.Offset-prev.Offset))
+		}
+	}
+
+	return gr.Stops[len(gr.Stops)-1].Color
+}
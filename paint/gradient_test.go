@@ -0,0 +1,29 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package paint
+
+import "testing"
+
+func TestGradientAt(t *testing.T) {
+	gr := Gradient{Stops: []Stop{
+		{0.25, Black},
+		{0.75, White},
+	}}
+
+	cases := []struct {
+		t    float32
+		want Color
+	}{
+		{0, Black},
+		{0.25, Black},
+		{0.5, Color{0.5, 0.5, 0.5, 1}},
+		{0.75, White},
+		{1, White},
+	}
+
+	for _, c := range cases {
+		if got := gr.At(c.t); got != c.want {
+			t.Errorf("At(%v) = %v, want %v", c.t, got, c.want)
+		}
+	}
+}
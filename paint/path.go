@@ -0,0 +1,179 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package paint
+
+import (
+	"math"
+
+	g "github.com/Sergobot/Rocky/geometry"
+)
+
+// Tolerance is how far, in units of a path, flattened curves may go from the
+// real ones. It's a quarter of a pixel, if a path is drawn in pixels.
+const Tolerance = 0.25
+
+// Path is a shape made of lines, curves and arcs, like in HTML canvas. It may
+// consist of several subpaths, each started with MoveTo. Curves are flattened
+// into lines as they are added, so a Path is just a set of polylines.
+type Path struct {
+	subpaths []Subpath
+}
+
+// Subpath is a polyline, closed or not.
+type Subpath struct {
+	Points []g.PointF
+	Closed bool
+}
+
+// Subpaths returns polylines of the path. Empty subpaths are skipped.
+func (p *Path) Subpaths() []Subpath {
+	var out []Subpath
+	for _, s := range p.subpaths {
+		if len(s.Points) > 0 {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// current returns the subpath being drawn, starting one at the origin if there
+// is none.
+func (p *Path) current() *Subpath {
+	if len(p.subpaths) == 0 || p.subpaths[len(p.subpaths)-1].Closed {
+		var start g.PointF
+		if n := len(p.subpaths); n > 0 && len(p.subpaths[n-1].Points) > 0 {
+			// Like in HTML canvas, a new subpath starts where the closed one did
+			start = p.subpaths[n-1].Points[0]
+		}
+		p.subpaths = append(p.subpaths, Subpath{Points: []g.PointF{start}})
+	}
+	return &p.subpaths[len(p.subpaths)-1]
+}
+
+// last returns the current point.
+func (p *Path) last() g.PointF {
+	s := p.current()
+	return s.Points[len(s.Points)-1]
+}
+
+// MoveTo starts a new subpath at (x, y).
+func (p *Path) MoveTo(x, y float32) {
+	p.subpaths = append(p.subpaths, Subpath{Points: []g.PointF{{X: x, Y: y}}})
+}
+
+// LineTo adds a line from the current point to (x, y).
+func (p *Path) LineTo(x, y float32) {
+	s := p.current()
+	s.Points = append(s.Points, g.PointF{X: x, Y: y})
+}
+
+// QuadTo adds a quadratic Bézier curve from the current point to (x, y) with
+// control point (cx, cy).
+func (p *Path) QuadTo(cx, cy, x, y float32) {
+	p0, p1, p2 := p.last(), g.PointF{X: cx, Y: cy}, g.PointF{X: x, Y: y}
+	n := segments(dist(p0, p1) + dist(p1, p2))
+
+	s := p.current()
+	for i := 1; i <= n; i++ {
+		t := float32(i) / float32(n)
+		u := 1 - t
+		s.Points = append(s.Points, g.PointF{
+			X: u*u*p0.X + 2*u*t*p1.X + t*t*p2.X,
+			Y: u*u*p0.Y + 2*u*t*p1.Y + t*t*p2.Y,
+		})
+	}
+}
+
+// CubicTo adds a cubic Bézier curve from the current point to (x, y) with
+// control points (c1x, c1y) and (c2x, c2y).
+func (p *Path) CubicTo(c1x, c1y, c2x, c2y, x, y float32) {
+	p0 := p.last()
+	p1, p2, p3 := g.PointF{X: c1x, Y: c1y}, g.PointF{X: c2x, Y: c2y}, g.PointF{X: x, Y: y}
+	n := segments(dist(p0, p1) + dist(p1, p2) + dist(p2, p3))
+
+	s := p.current()
+	for i := 1; i <= n; i++ {
+		t := float32(i) / float32(n)
+		u := 1 - t
+		a, b, c, d := u*u*u, 3*u*u*t, 3*u*t*t, t*t*t
+		s.Points = append(s.Points, g.PointF{
+			X: a*p0.X + b*p1.X + c*p2.X + d*p3.X,
+			Y: a*p0.Y + b*p1.Y + c*p2.Y + d*p3.Y,
+		})
+	}
+}
+
+// Arc adds an arc of a circle centered at (cx, cy) from angle start to end,
+// clockwise or counterclockwise. Angles are in radians, clockwise from X axis.
+// A line from the current point to the arc's start is added, if there is a
+// subpath already.
+func (p *Path) Arc(cx, cy, r, start, end float32, counterclockwise bool) {
+	sweep := float64(end - start)
+	full := 2 * math.Pi
+	if counterclockwise {
+		sweep = -sweep
+	}
+	if sweep >= full {
+		sweep = full
+	} else {
+		sweep = math.Mod(sweep, full)
+		if sweep < 0 {
+			sweep += full
+		}
+	}
+	if counterclockwise {
+		sweep = -sweep
+	}
+
+	n := segments(float32(math.Abs(sweep)) * r)
+	var s *Subpath
+	for i := 0; i <= n; i++ {
+		a := float64(start) + sweep*float64(i)/float64(n)
+		sin, cos := math.Sincos(a)
+		pt := g.PointF{X: cx + r*float32(cos), Y: cy + r*float32(sin)}
+		if i == 0 {
+			if len(p.subpaths) == 0 || p.subpaths[len(p.subpaths)-1].Closed {
+				p.MoveTo(pt.X, pt.Y)
+				s = p.current()
+				continue
+			}
+			s = p.current()
+		}
+		s.Points = append(s.Points, pt)
+	}
+}
+
+// Rect adds a closed rectangle subpath.
+func (p *Path) Rect(r g.RectF) {
+	p.MoveTo(r.X, r.Y)
+	p.LineTo(r.X+r.W, r.Y)
+	p.LineTo(r.X+r.W, r.Y+r.H)
+	p.LineTo(r.X, r.Y+r.H)
+	p.Close()
+}
+
+// Close closes the current subpath: a line goes from its last point to the
+// first one.
+func (p *Path) Close() {
+	if len(p.subpaths) > 0 {
+		p.subpaths[len(p.subpaths)-1].Closed = true
+	}
+}
+
+// segments returns the number of lines to approximate a curve of the given
+// length with. A circle of radius r is close enough with sqrt(r/Tolerance)
+// times pi segments, curves are treated alike.
+func segments(length float32) int {
+	n := int(math.Ceil(math.Sqrt(float64(length) / Tolerance)))
+	if n < 1 {
+		return 1
+	}
+	if n > 256 {
+		return 256
+	}
+	return n
+}
+
+func dist(a, b g.PointF) float32 {
+	return float32(math.Hypot(float64(b.X-a.X), float64(b.Y-a.Y)))
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package paint
+
+import (
+	"sort"
+
+	g "github.com/Sergobot/Rocky/geometry"
+)
+
+// FillRule tells which parts of a path are inside, when its subpaths overlap or
+// intersect themselves, just like fillRule of HTML canvas. Winding number of a
+// point is how many times subpaths go around it, clockwise ones counted as +1
+// and counterclockwise ones as -1.
+type FillRule int
+
+// Fill rules. NonZero is the default one.
+const (
+	// NonZero fills points with winding number other than zero, so subpaths
+	// going the opposite way make holes
+	NonZero FillRule = iota
+	// EvenOdd fills points with odd winding number, so any subpath inside of
+	// another one makes a hole
+	EvenOdd FillRule = iota
+)
+
+// inside returns true if points with the given winding number are filled.
+func (r FillRule) inside(winding int) bool {
+	if r == EvenOdd {
+		return winding%2 != 0
+	}
+	return winding != 0
+}
+
+// regionEdge is a non-horizontal edge of a path, going down: a.Y < b.Y.
+type regionEdge struct {
+	a, b g.PointF
+	// -1 if the path goes down along the edge, +1 if it goes up, so that
+	// clockwise subpaths count as +1 from left to right
+	dir int
+}
+
+// xAt returns X of the edge's line at the given Y.
+func (e *regionEdge) xAt(y float32) float32 {
+	return e.a.X + (e.b.X-e.a.X)*(y-e.a.Y)/(e.b.Y-e.a.Y)
+}
+
+// Region returns convex polygons covering the inside of a path according to
+// the fill rule. Subpaths are closed implicitly, may intersect themselves and
+// each other, and may make holes. The polygons don't overlap.
+//
+// The path is cut into horizontal slabs at every vertex and intersection of
+// edges, so edges don't cross inside of a slab. Then each slab is walked from
+// left to right, counting winding number, and filled parts of it become
+// trapezoids.
+func Region(p *Path, rule FillRule) [][]g.PointF {
+	var edges []regionEdge
+	var ys []float32
+	for _, s := range p.Subpaths() {
+		pts := cleanPolygon(s.Points)
+		if len(pts) < 3 {
+			continue
+		}
+		for i, a := range pts {
+			b := pts[(i+1)%len(pts)]
+			ys = append(ys, a.Y)
+			switch {
+			case a.Y < b.Y:
+				edges = append(edges, regionEdge{a, b, -1})
+			case a.Y > b.Y:
+				edges = append(edges, regionEdge{b, a, 1})
+			}
+		}
+	}
+	for i := range edges {
+		for j := i + 1; j < len(edges); j++ {
+			if y, ok := intersectY(&edges[i], &edges[j]); ok {
+				ys = append(ys, y)
+			}
+		}
+	}
+	ys = uniqueSorted(ys)
+
+	var out [][]g.PointF
+	var active []*regionEdge
+	for i := 0; i+1 < len(ys); i++ {
+		y0, y1 := ys[i], ys[i+1]
+		mid := (y0 + y1) / 2
+
+		active = active[:0]
+		for j := range edges {
+			if e := &edges[j]; e.a.Y < mid && e.b.Y > mid {
+				active = append(active, e)
+			}
+		}
+		sort.Sort(byXAt{active, mid})
+
+		winding := 0
+		var left *regionEdge
+		for _, e := range active {
+			was := rule.inside(winding)
+			winding += e.dir
+			now := rule.inside(winding)
+			switch {
+			case !was && now:
+				left = e
+			case was && !now:
+				out = appendTrapezoid(out, left, e, y0, y1)
+			}
+		}
+	}
+	return out
+}
+
+// FillPath returns triangles covering the inside of a path according to the
+// fill rule, three points per triangle. See Region.
+func FillPath(p *Path, rule FillRule) []g.PointF {
+	var tris []g.PointF
+	for _, poly := range Region(p, rule) {
+		for i := 1; i+1 < len(poly); i++ {
+			tris = append(tris, poly[0], poly[i], poly[i+1])
+		}
+	}
+	return tris
+}
+
+// appendTrapezoid appends the part of a slab between two edges, dropping
+// corners, where the edges meet.
+func appendTrapezoid(out [][]g.PointF, left, right *regionEdge, y0, y1 float32) [][]g.PointF {
+	poly := make([]g.PointF, 0, 4)
+	l0, r0 := left.xAt(y0), right.xAt(y0)
+	l1, r1 := left.xAt(y1), right.xAt(y1)
+	poly = append(poly, g.PointF{X: l0, Y: y0})
+	if r0 > l0 {
+		poly = append(poly, g.PointF{X: r0, Y: y0})
+	}
+	poly = append(poly, g.PointF{X: r1, Y: y1})
+	if r1 > l1 {
+		poly = append(poly, g.PointF{X: l1, Y: y1})
+	}
+	if len(poly) < 3 {
+		return out
+	}
+	return append(out, poly)
+}
+
+// intersectY returns Y of the point, where two edges cross each other, not
+// counting their ends.
+func intersectY(e, f *regionEdge) (float32, bool) {
+	if e.b.Y <= f.a.Y || f.b.Y <= e.a.Y {
+		return 0, false
+	}
+	r := g.PointF{X: e.b.X - e.a.X, Y: e.b.Y - e.a.Y}
+	s := g.PointF{X: f.b.X - f.a.X, Y: f.b.Y - f.a.Y}
+	den := r.X*s.Y - r.Y*s.X
+	if den == 0 {
+		// Parallel edges
+		return 0, false
+	}
+	q := g.PointF{X: f.a.X - e.a.X, Y: f.a.Y - e.a.Y}
+	t := (q.X*s.Y - q.Y*s.X) / den
+	u := (q.X*r.Y - q.Y*r.X) / den
+	if t <= 0 || t >= 1 || u <= 0 || u >= 1 {
+		return 0, false
+	}
+	return e.a.Y + t*r.Y, true
+}
+
+// uniqueSorted sorts values and removes duplicates.
+func uniqueSorted(v []float32) []float32 {
+	sort.Sort(float32s(v))
+	res := v[:0]
+	for i, y := range v {
+		if i == 0 || y != res[len(res)-1] {
+			res = append(res, y)
+		}
+	}
+	return res
+}
+
+type float32s []float32
+
+func (f float32s) Len() int           { return len(f) }
+func (f float32s) Swap(i, j int)      { f[i], f[j] = f[j], f[i] }
+func (f float32s) Less(i, j int) bool { return f[i] < f[j] }
+
+// byXAt sorts edges by X at the given Y.
+type byXAt struct {
+	edges []*regionEdge
+	y     float32
+}
+
+func (b byXAt) Len() int           { return len(b.edges) }
+func (b byXAt) Swap(i, j int)      { b.edges[i], b.edges[j] = b.edges[j], b.edges[i] }
+func (b byXAt) Less(i, j int) bool { return b.edges[i].xAt(b.y) < b.edges[j].xAt(b.y) }
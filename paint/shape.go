@@ -0,0 +1,40 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package paint
+
+// Cap variables tell how ends of lines are drawn.
+type Cap int
+
+// Line caps, just like in most of vector graphics APIs
+const (
+	// ButtCap ends a line exactly at its end point.
+	ButtCap Cap = iota
+	// RoundCap adds a half-circle to each end of a line.
+	RoundCap Cap = iota
+	// SquareCap adds a half-square to each end of a line.
+	SquareCap Cap = iota
+)
+
+// Join variables tell how corners of lines are drawn.
+type Join int
+
+// Line joins, just like in most of vector graphics APIs
+const (
+	// MiterJoin extends edges of lines until they meet. Too sharp corners are
+	// beveled instead, since they would go too far.
+	MiterJoin Join = iota
+	// RoundJoin rounds corners off with a circle.
+	RoundJoin Join = iota
+	// BevelJoin cuts corners off with a straight line.
+	BevelJoin Join = iota
+)
+
+// Radii holds radius of each corner of a rounded rectangle.
+type Radii struct {
+	TopLeft, TopRight, BottomRight, BottomLeft float32
+}
+
+// UniformRadii returns Radii with the same radius for all the corners.
+func UniformRadii(r float32) Radii {
+	return Radii{r, r, r, r}
+}
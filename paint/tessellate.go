@@ -0,0 +1,327 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package paint
+
+import (
+	"math"
+
+	g "github.com/Sergobot/Rocky/geometry"
+)
+
+// MiterLimit is how far, in line widths, a miter join may stick out of a
+// corner. Sharper corners are beveled.
+const MiterLimit = 4
+
+// Fill returns triangles covering a polygon, three points per triangle. The
+// polygon may be concave, but it must not intersect itself. Paths with holes
+// or intersections are filled with FillPath.
+func Fill(polygon []g.PointF) []g.PointF {
+	pts := cleanPolygon(polygon)
+	n := len(pts)
+	if n < 3 {
+		return nil
+	}
+
+	// Ear clipping expects counterclockwise polygons in Y up coordinates,
+	// which is positive area in our Y down ones
+	idx := make([]int, n)
+	if area(pts) > 0 {
+		for i := range idx {
+			idx[i] = i
+		}
+	} else {
+		for i := range idx {
+			idx[i] = n - 1 - i
+		}
+	}
+
+	tris := make([]g.PointF, 0, 3*(n-2))
+	for guard := 0; len(idx) > 3 && guard < n*n; guard++ {
+		clipped := false
+		for i := range idx {
+			prev, cur, next := idx[(i+len(idx)-1)%len(idx)], idx[i], idx[(i+1)%len(idx)]
+			if !isEar(pts, idx, prev, cur, next) {
+				continue
+			}
+			tris = append(tris, pts[prev], pts[cur], pts[next])
+			idx = append(idx[:i], idx[i+1:]...)
+			clipped = true
+			break
+		}
+		if !clipped {
+			// Self-intersecting or degenerate polygon: fill the rest with a fan,
+			// which is wrong, but better than nothing
+			break
+		}
+	}
+	for i := 1; i+1 < len(idx); i++ {
+		tris = append(tris, pts[idx[0]], pts[idx[i]], pts[idx[i+1]])
+	}
+	return tris
+}
+
+// isEar returns true if triangle prev-cur-next is convex and has no other
+// points of the polygon inside.
+func isEar(pts []g.PointF, idx []int, prev, cur, next int) bool {
+	a, b, c := pts[prev], pts[cur], pts[next]
+	if cross(a, b, c) <= 0 {
+		return false
+	}
+	for _, j := range idx {
+		if j == prev || j == cur || j == next {
+			continue
+		}
+		if inTriangle(pts[j], a, b, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// cleanPolygon drops repeated points and the closing point equal to the first.
+func cleanPolygon(polygon []g.PointF) []g.PointF {
+	pts := make([]g.PointF, 0, len(polygon))
+	for _, p := range polygon {
+		if len(pts) == 0 || pts[len(pts)-1] != p {
+			pts = append(pts, p)
+		}
+	}
+	for len(pts) > 1 && pts[0] == pts[len(pts)-1] {
+		pts = pts[:len(pts)-1]
+	}
+	return pts
+}
+
+// area returns doubled signed area of a polygon, positive for clockwise ones
+// on the screen.
+func area(pts []g.PointF) float32 {
+	var a float32
+	for i := range pts {
+		p, q := pts[i], pts[(i+1)%len(pts)]
+		a += p.X*q.Y - q.X*p.Y
+	}
+	return a
+}
+
+// cross returns z of the cross product of ab and bc, positive when the path
+// a-b-c turns clockwise on the screen.
+func cross(a, b, c g.PointF) float32 {
+	return (b.X-a.X)*(c.Y-b.Y) - (b.Y-a.Y)*(c.X-b.X)
+}
+
+func inTriangle(p, a, b, c g.PointF) bool {
+	return cross(a, b, p) >= 0 && cross(b, c, p) >= 0 && cross(c, a, p) >= 0
+}
+
+// Stroke returns triangles covering a polyline drawn with a line of the given
+// width, caps and joins. Caps are only added to open polylines.
+func Stroke(polyline []g.PointF, closed bool, width float32, cap Cap, join Join) []g.PointF {
+	pts := cleanPolygon(polyline)
+	if !closed {
+		// Only consecutive duplicates are dropped from open polylines
+		pts = pts[:0:0]
+		for _, p := range polyline {
+			if len(pts) == 0 || pts[len(pts)-1] != p {
+				pts = append(pts, p)
+			}
+		}
+	}
+	if len(pts) < 2 || width <= 0 {
+		return nil
+	}
+	hw := width / 2
+
+	var tris []g.PointF
+	quad := func(a, b, c, d g.PointF) {
+		tris = append(tris, a, b, c, a, c, d)
+	}
+
+	segs := len(pts) - 1
+	if closed {
+		segs = len(pts)
+	}
+	for i := 0; i < segs; i++ {
+		a, b := pts[i], pts[(i+1)%len(pts)]
+		dx, dy := unit(a, b)
+		nx, ny := -dy*hw, dx*hw
+
+		// Square caps make open polylines longer by half of the width
+		if !closed && cap == SquareCap {
+			if i == 0 {
+				a = g.PointF{X: a.X - dx*hw, Y: a.Y - dy*hw}
+			}
+			if i == segs-1 {
+				b = g.PointF{X: b.X + dx*hw, Y: b.Y + dy*hw}
+			}
+		}
+		quad(g.PointF{X: a.X + nx, Y: a.Y + ny}, g.PointF{X: b.X + nx, Y: b.Y + ny},
+			g.PointF{X: b.X - nx, Y: b.Y - ny}, g.PointF{X: a.X - nx, Y: a.Y - ny})
+	}
+
+	// Joins fill gaps at the corners between segments
+	first, last := 1, len(pts)-1
+	if closed {
+		first, last = 0, len(pts)
+	}
+	for i := first; i < last; i++ {
+		prev, cur, next := pts[(i+len(pts)-1)%len(pts)], pts[i], pts[(i+1)%len(pts)]
+		tris = append(tris, strokeJoin(prev, cur, next, hw, join)...)
+	}
+
+	if !closed && cap == RoundCap {
+		tris = append(tris, roundCap(pts[0], pts[1], hw)...)
+		tris = append(tris, roundCap(pts[len(pts)-1], pts[len(pts)-2], hw)...)
+	}
+	return tris
+}
+
+// strokeJoin returns triangles of a join at cur, between lines coming from
+// prev and going to next.
+func strokeJoin(prev, cur, next g.PointF, hw float32, join Join) []g.PointF {
+	turn := cross(prev, cur, next)
+	if turn == 0 {
+		return nil
+	}
+	d0x, d0y := unit(prev, cur)
+	d1x, d1y := unit(cur, next)
+
+	// The gap is on the outer side of the corner
+	side := float32(1)
+	if turn > 0 {
+		side = -1
+	}
+	n0 := g.PointF{X: cur.X - d0y*hw*side, Y: cur.Y + d0x*hw*side}
+	n1 := g.PointF{X: cur.X - d1y*hw*side, Y: cur.Y + d1x*hw*side}
+
+	switch join {
+	case RoundJoin:
+		a0 := math.Atan2(float64(n0.Y-cur.Y), float64(n0.X-cur.X))
+		a1 := math.Atan2(float64(n1.Y-cur.Y), float64(n1.X-cur.X))
+		return fan(cur, hw, a0, a1)
+	case MiterJoin:
+		// Miter tip is where the outer edges meet
+		cos := d0x*d1x + d0y*d1y
+		if miter := float32(math.Sqrt(2 / float64(1+cos))); 1+cos > 0 && miter <= MiterLimit {
+			mx, my := (n0.X+n1.X)/2-cur.X, (n0.Y+n1.Y)/2-cur.Y
+			l := float32(math.Hypot(float64(mx), float64(my)))
+			if l > 0 {
+				tip := g.PointF{X: cur.X + mx/l*hw*miter, Y: cur.Y + my/l*hw*miter}
+				return []g.PointF{cur, n0, tip, cur, tip, n1}
+			}
+		}
+	}
+	return []g.PointF{cur, n0, n1}
+}
+
+// roundCap returns a half-circle at end of a line going from other to end.
+func roundCap(end, other g.PointF, hw float32) []g.PointF {
+	a := math.Atan2(float64(end.Y-other.Y), float64(end.X-other.X))
+	return fan(end, hw, a-math.Pi/2, a+math.Pi/2)
+}
+
+// fan returns triangles of a circle sector from angle a0 to a1, the shorter
+// way around.
+func fan(c g.PointF, r float32, a0, a1 float64) []g.PointF {
+	sweep := a1 - a0
+	for sweep > math.Pi {
+		sweep -= 2 * math.Pi
+	}
+	for sweep < -math.Pi {
+		sweep += 2 * math.Pi
+	}
+
+	n := segments(float32(math.Abs(sweep)) * r)
+	tris := make([]g.PointF, 0, 3*n)
+	prev := g.PointF{X: c.X + r*float32(math.Cos(a0)), Y: c.Y + r*float32(math.Sin(a0))}
+	for i := 1; i <= n; i++ {
+		a := a0 + sweep*float64(i)/float64(n)
+		p := g.PointF{X: c.X + r*float32(math.Cos(a)), Y: c.Y + r*float32(math.Sin(a))}
+		tris = append(tris, c, prev, p)
+		prev = p
+	}
+	return tris
+}
+
+// unit returns the unit vector from a to b.
+func unit(a, b g.PointF) (float32, float32) {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	l := float32(math.Hypot(float64(dx), float64(dy)))
+	if l == 0 {
+		return 0, 0
+	}
+	return dx / l, dy / l
+}
+
+// Vertex is a point with texture coordinates.
+type Vertex struct {
+	Pos, UV g.PointF
+}
+
+// ClipTriangles cuts triangles by a convex polygon, leaving only what's inside.
+// Texture coordinates are interpolated along the cuts.
+func ClipTriangles(tris []Vertex, clip []g.PointF) []Vertex {
+	clip = clockwise(clip)
+	if len(clip) < 3 {
+		return nil
+	}
+
+	var out []Vertex
+	for i := 0; i+2 < len(tris); i += 3 {
+		poly := clipConvex(tris[i:i+3], clip)
+		for j := 1; j+1 < len(poly); j++ {
+			out = append(out, poly[0], poly[j], poly[j+1])
+		}
+	}
+	return out
+}
+
+// ClipPolygon cuts a convex polygon by another convex polygon, leaving only
+// what's inside of the latter.
+func ClipPolygon(subject []Vertex, clip []g.PointF) []Vertex {
+	clip = clockwise(clip)
+	if len(clip) < 3 {
+		return nil
+	}
+	return clipConvex(subject, clip)
+}
+
+// clockwise returns a cleaned polygon, which goes clockwise on the screen.
+func clockwise(polygon []g.PointF) []g.PointF {
+	pts := cleanPolygon(polygon)
+	if area(pts) >= 0 {
+		return pts
+	}
+	for i, j := 0, len(pts)-1; i < j; i, j = i+1, j-1 {
+		pts[i], pts[j] = pts[j], pts[i]
+	}
+	return pts
+}
+
+// clipConvex is Sutherland–Hodgman algorithm: the subject is cut by each edge
+// of the clip polygon, which goes clockwise, so the inside is on the right.
+func clipConvex(subject []Vertex, clip []g.PointF) []Vertex {
+	out := append([]Vertex(nil), subject...)
+	for i := range clip {
+		if len(out) == 0 {
+			break
+		}
+		a, b := clip[i], clip[(i+1)%len(clip)]
+		in := out
+		out = nil
+		for j := range in {
+			cur, next := in[j], in[(j+1)%len(in)]
+			dc, dn := cross(a, b, cur.Pos), cross(a, b, next.Pos)
+			if dc >= 0 {
+				out = append(out, cur)
+			}
+			if (dc >= 0) != (dn >= 0) {
+				t := dc / (dc - dn)
+				out = append(out, Vertex{
+					Pos: g.PointF{X: cur.Pos.X + (next.Pos.X-cur.Pos.X)*t, Y: cur.Pos.Y + (next.Pos.Y-cur.Pos.Y)*t},
+					UV:  g.PointF{X: cur.UV.X + (next.UV.X-cur.UV.X)*t, Y: cur.UV.Y + (next.UV.Y-cur.UV.Y)*t},
+				})
+			}
+		}
+	}
+	return out
+}
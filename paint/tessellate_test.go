@@ -0,0 +1,184 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package paint
+
+import (
+	"math"
+	"testing"
+
+	g "github.com/Sergobot/Rocky/geometry"
+)
+
+// trianglesArea returns total area of triangles.
+func trianglesArea(tris []g.PointF) float32 {
+	var a float32
+	for i := 0; i+2 < len(tris); i += 3 {
+		a += float32(math.Abs(float64(cross(tris[i], tris[i+1], tris[i+2])))) / 2
+	}
+	return a
+}
+
+func approx(a, b, eps float32) bool {
+	return math.Abs(float64(a-b)) <= float64(eps)
+}
+
+func TestFill(t *testing.T) {
+	// An L-shaped concave polygon, counterclockwise on the screen, of area 3
+	l := []g.PointF{{X: 0, Y: 0}, {X: 0, Y: 2}, {X: 2, Y: 2}, {X: 2, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 0}, {X: 0, Y: 0}}
+	tris := Fill(l)
+	if len(tris) != 3*4 {
+		t.Errorf("Got %d triangles, want 4", len(tris)/3)
+	}
+	if a := trianglesArea(tris); !approx(a, 3, 1e-4) {
+		t.Errorf("Triangles cover %v, want 3", a)
+	}
+
+	var p Path
+	p.Arc(0, 0, 10, 0, 2*math.Pi, false)
+	circle := p.Subpaths()[0].Points
+	if a := trianglesArea(Fill(circle)); !approx(a, 100*math.Pi, 10) {
+		t.Errorf("Circle area = %v, want about %v", a, 100*math.Pi)
+	}
+}
+
+func TestPath(t *testing.T) {
+	var p Path
+	p.MoveTo(0, 0)
+	p.QuadTo(5, 10, 10, 0)
+	p.Close()
+	p.LineTo(3, 3)
+
+	subpaths := p.Subpaths()
+	if len(subpaths) != 2 || !subpaths[0].Closed || subpaths[1].Closed {
+		t.Fatalf("Got subpaths %+v", subpaths)
+	}
+	curve := subpaths[0].Points
+	if last := curve[len(curve)-1]; last != (g.PointF{X: 10, Y: 0}) {
+		t.Errorf("Curve ends at %v, want {10 0}", last)
+	}
+	// The curve peaks at the middle, half way to the control point
+	if mid := curve[len(curve)/2]; !approx(mid.Y, 5, 0.5) {
+		t.Errorf("Middle of the curve at %v, want Y near 5", mid)
+	}
+	// A new subpath after Close starts where the closed one did
+	if start := subpaths[1].Points[0]; start != (g.PointF{}) {
+		t.Errorf("Subpath after Close starts at %v, want {0 0}", start)
+	}
+}
+
+func TestStroke(t *testing.T) {
+	line := []g.PointF{{X: 0, Y: 0}, {X: 10, Y: 0}}
+	if a := trianglesArea(Stroke(line, false, 2, ButtCap, MiterJoin)); !approx(a, 20, 1e-4) {
+		t.Errorf("Butt line area = %v, want 20", a)
+	}
+	if a := trianglesArea(Stroke(line, false, 2, SquareCap, MiterJoin)); !approx(a, 24, 1e-4) {
+		t.Errorf("Square capped line area = %v, want 24", a)
+	}
+
+	// A square outline 2 wide. Segments overlap at inner sides of corners, so
+	// triangles cover 4 squares 1x1 twice, and joins add a square or a half of
+	// it at each corner.
+	square := []g.PointF{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}, {X: 0, Y: 10}}
+	if a := trianglesArea(Stroke(square, true, 2, ButtCap, MiterJoin)); !approx(a, 84, 1e-3) {
+		t.Errorf("Square outline area = %v, want 84", a)
+	}
+	if a := trianglesArea(Stroke(square, true, 2, ButtCap, BevelJoin)); !approx(a, 82, 1e-3) {
+		t.Errorf("Beveled square outline area = %v, want 82", a)
+	}
+}
+
+func TestClipTriangles(t *testing.T) {
+	tri := []Vertex{
+		{Pos: g.PointF{X: 0, Y: 0}, UV: g.PointF{X: 0, Y: 0}},
+		{Pos: g.PointF{X: 4, Y: 0}, UV: g.PointF{X: 1, Y: 0}},
+		{Pos: g.PointF{X: 0, Y: 4}, UV: g.PointF{X: 0, Y: 1}},
+	}
+	// Counterclockwise clip rect works too
+	clip := []g.PointF{{X: 0, Y: 0}, {X: 0, Y: 2}, {X: 2, Y: 2}, {X: 2, Y: 0}}
+
+	out := ClipTriangles(tri, clip)
+	pts := make([]g.PointF, len(out))
+	for i, v := range out {
+		pts[i] = v.Pos
+		// Texture coordinates follow positions
+		if !approx(v.UV.X, v.Pos.X/4, 1e-5) || !approx(v.UV.Y, v.Pos.Y/4, 1e-5) {
+			t.Errorf("Vertex %v has UV %v", v.Pos, v.UV)
+		}
+	}
+	if a := trianglesArea(pts); !approx(a, 4, 1e-4) {
+		t.Errorf("Clipped area = %v, want 4", a)
+	}
+}
+
+func TestFillPath(t *testing.T) {
+	square := func(p *Path, x, y, size float32, clockwise bool) {
+		p.MoveTo(x, y)
+		if clockwise {
+			p.LineTo(x+size, y)
+			p.LineTo(x+size, y+size)
+			p.LineTo(x, y+size)
+		} else {
+			p.LineTo(x, y+size)
+			p.LineTo(x+size, y+size)
+			p.LineTo(x+size, y)
+		}
+		p.Close()
+	}
+
+	tests := []struct {
+		name             string
+		inner            bool
+		nonZero, evenOdd float32
+	}{
+		// An inner square going the same way is filled only with NonZero
+		{"same", true, 16, 12},
+		// Going the opposite way, it's a hole with both rules
+		{"opposite", false, 12, 12},
+	}
+	for _, tt := range tests {
+		var p Path
+		square(&p, 0, 0, 4, true)
+		square(&p, 1, 1, 2, tt.inner)
+		if a := trianglesArea(FillPath(&p, NonZero)); !approx(a, tt.nonZero, 1e-4) {
+			t.Errorf("%v: NonZero area = %v, want %v", tt.name, a, tt.nonZero)
+		}
+		if a := trianglesArea(FillPath(&p, EvenOdd)); !approx(a, tt.evenOdd, 1e-4) {
+			t.Errorf("%v: EvenOdd area = %v, want %v", tt.name, a, tt.evenOdd)
+		}
+	}
+
+	// A pentagram: its center has winding number 2, so it's a hole only with
+	// EvenOdd. For radius 10, the star is of area about 112.26, and the
+	// center is a pentagon of area about 34.69.
+	var star Path
+	for i := 0; i < 5; i++ {
+		a := math.Pi/2 + float64(i)*4*math.Pi/5
+		x, y := float32(10*math.Cos(a)), float32(10*math.Sin(a))
+		if i == 0 {
+			star.MoveTo(x, y)
+		} else {
+			star.LineTo(x, y)
+		}
+	}
+	star.Close()
+	if a := trianglesArea(FillPath(&star, NonZero)); !approx(a, 112.26, 0.01) {
+		t.Errorf("Pentagram NonZero area = %v, want 112.26", a)
+	}
+	if a := trianglesArea(FillPath(&star, EvenOdd)); !approx(a, 112.26-34.69, 0.01) {
+		t.Errorf("Pentagram EvenOdd area = %v, want %v", a, 112.26-34.69)
+	}
+}
+
+func TestDefaultFont(t *testing.T) {
+	f := DefaultFont()
+	if got := f.Measure("ab\nc"); got != (g.Size{W: 12, H: 18}) {
+		t.Errorf("Measure() = %v, want {12 18}", got)
+	}
+	if f.Glyph('A') == f.Glyph('B') || f.Glyph('\t') != f.Glyph('?') {
+		t.Error("Glyphs are placed wrong")
+	}
+	r := f.Glyph('I')
+	if c := f.Image.NRGBAAt(r.X+2, r.Y); c.A != 255 {
+		t.Errorf("Top of I is %v, want opaque", c)
+	}
+}
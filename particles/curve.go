@@ -0,0 +1,84 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package particles
+
+import (
+	"math/rand"
+
+	"github.com/Sergobot/Rocky/paint"
+)
+
+// Key is a single point of a Curve: value V at time T in range [0, 1] of
+// particle's lifetime.
+type Key struct {
+	T, V float32
+}
+
+// Curve is a value changing over lifetime of a particle, linearly between
+// keys. Keys must be sorted by time. An empty curve is always 1, so it changes
+// nothing when multiplied by.
+type Curve []Key
+
+// Constant returns a curve, which is v all the time.
+func Constant(v float32) Curve {
+	return Curve{{0, v}}
+}
+
+// Line returns a curve going from one value at birth to another at death.
+func Line(from, to float32) Curve {
+	return Curve{{0, from}, {1, to}}
+}
+
+// At returns value of the curve at time t in range [0, 1].
+func (c Curve) At(t float32) float32 {
+	if len(c) == 0 {
+		return 1
+	}
+	if t <= c[0].T {
+		return c[0].V
+	}
+
+	for i := 1; i < len(c); i++ {
+		prev, next := c[i-1], c[i]
+		if t <= next.T {
+			if next.T == prev.T {
+				return next.V
+			}
+			return prev.V + (next.V-prev.V)*(t-prev.T)/(next.T-prev.T)
+		}
+	}
+
+	return c[len(c)-1].V
+}
+
+// ColorCurve is a color changing over lifetime of a particle, just like color
+// stops of a gradient. An empty curve is always white.
+type ColorCurve []paint.Stop
+
+// At returns color at time t in range [0, 1].
+func (c ColorCurve) At(t float32) paint.Color {
+	if len(c) == 0 {
+		return paint.White
+	}
+	gr := paint.Gradient{Stops: c}
+	return gr.At(t)
+}
+
+// Range is a range random values are picked from. Min equal to Max gives the
+// same value every time.
+type Range struct {
+	Min, Max float32
+}
+
+// Fixed returns a range of a single value.
+func Fixed(v float32) Range {
+	return Range{v, v}
+}
+
+// pick returns a random value in the range.
+func (r Range) pick(rnd *rand.Rand) float32 {
+	if r.Min == r.Max {
+		return r.Min
+	}
+	return r.Min + (r.Max-r.Min)*rnd.Float32()
+}
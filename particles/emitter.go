@@ -0,0 +1,287 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+// Package particles simulates particles: sparks, smoke, debris and alike. An
+// Emitter spawns them as its Config tells and moves them around, drawing is
+// done by a widget, see widgets.ParticleEmitter.
+// Units of positions are up to the user, widgets use pixels.
+package particles
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/Sergobot/Rocky/animation"
+	g "github.com/Sergobot/Rocky/geometry"
+	"github.com/Sergobot/Rocky/paint"
+)
+
+// Burst spawns Count particles at once, when the emitter has been running for
+// Time.
+type Burst struct {
+	Time  time.Duration
+	Count int
+}
+
+// Config tells how particles are spawned and how they behave. Angles are in
+// radians, clockwise from X axis pointing right (Y axis points down), speeds
+// are in units per second, times in seconds unless told otherwise.
+type Config struct {
+	// Particles spawned every second
+	Rate float32
+	// Bursts fired while the emitter runs
+	Bursts []Burst
+	// Emitter spawns particles for this long after it starts. Zero means
+	// forever.
+	Duration time.Duration
+	// No more particles are spawned, while there are that many alive. Zero
+	// means there is no limit.
+	MaxParticles int
+
+	// Lifetime of particles
+	Lifetime Range
+	// Particles spawn at random points of an area centered at the emitter
+	Area g.SizeF
+
+	// Particles fly in a cone Spread radians wide around Direction
+	Direction, Spread float32
+	Speed             Range
+	// Gravity is the acceleration added to velocity every second
+	Gravity g.PointF
+	// Drag slows particles down: velocity loses Drag of itself every second
+	Drag float32
+
+	// Size of particles at birth, it's multiplied by SizeOverLife later
+	Size         Range
+	SizeOverLife Curve
+
+	// Rotation of particles at birth and their rotation speed. Spin is
+	// multiplied by SpinOverLife as particles age.
+	Rotation     Range
+	Spin         Range
+	SpinOverLife Curve
+
+	// Color of particles over their lifetime, white by default
+	Color ColorCurve
+}
+
+// Particle is a single particle. Age and Life are in seconds, Life is how long
+// the particle lives in total.
+type Particle struct {
+	Pos, Vel  g.PointF
+	Age, Life float32
+
+	Size     float32
+	Rotation float32
+	Color    paint.Color
+
+	// Size at birth and spin before applying curves
+	size, spin float32
+}
+
+// Emitter spawns and moves particles. It's an animation, so it may be played
+// with a window's animation player, or updated directly with Update.
+type Emitter struct {
+	config Config
+	pos    g.PointF
+
+	particles []Particle
+
+	rnd *rand.Rand
+
+	// Time the emitter has been running
+	clock time.Duration
+	// Particles to spawn on the next update. Spawn rate rarely gives a whole
+	// number of particles a frame, the rest is stored here.
+	pending float32
+	// Index of the next burst to fire
+	burst int
+
+	stopped bool
+}
+
+// NewEmitter returns an emitter, which spawns particles as c tells.
+func NewEmitter(c Config) *Emitter {
+	return &Emitter{
+		config: c,
+		rnd:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// SetConfig changes the config. Living particles keep their velocities and
+// sizes, curves apply to them from the next update.
+func (e *Emitter) SetConfig(c Config) {
+	e.config = c
+	e.burst = 0
+	for e.burst < len(c.Bursts) && c.Bursts[e.burst].Time < e.clock {
+		e.burst++
+	}
+}
+
+// Config returns the config.
+func (e *Emitter) Config() Config {
+	return e.config
+}
+
+// SetSeed seeds the random numbers generator, so particles are spawned the
+// same way every time.
+func (e *Emitter) SetSeed(seed int64) {
+	e.rnd.Seed(seed)
+}
+
+// SetPos moves the emitter. Living particles stay where they are.
+func (e *Emitter) SetPos(p g.PointF) {
+	e.pos = p
+}
+
+// Pos returns position of the emitter.
+func (e *Emitter) Pos() g.PointF {
+	return e.pos
+}
+
+// SetEmitting starts or stops spawning particles. Stopped emitter keeps
+// moving particles already spawned.
+func (e *Emitter) SetEmitting(emit bool) {
+	e.stopped = !emit
+	if !emit {
+		e.pending = 0
+	}
+}
+
+// Emitting returns true if the emitter spawns particles.
+func (e *Emitter) Emitting() bool {
+	return !e.stopped && (e.config.Duration <= 0 || e.clock < e.config.Duration)
+}
+
+// Finished returns true when the emitter doesn't spawn particles anymore, and
+// all the spawned ones are dead.
+func (e *Emitter) Finished() bool {
+	return !e.Emitting() && len(e.particles) == 0
+}
+
+// Particles returns living particles. The slice is reused on updates, don't
+// keep it.
+func (e *Emitter) Particles() []Particle {
+	return e.particles
+}
+
+// Burst spawns n particles right away.
+func (e *Emitter) Burst(n int) {
+	for i := 0; i < n && e.room(); i++ {
+		e.spawn()
+	}
+}
+
+// room returns true if one more particle may be spawned.
+func (e *Emitter) room() bool {
+	return e.config.MaxParticles <= 0 || len(e.particles) < e.config.MaxParticles
+}
+
+// spawn adds a new particle.
+func (e *Emitter) spawn() {
+	c := &e.config
+	rnd := e.rnd
+
+	angle := c.Direction + (rnd.Float32()-0.5)*c.Spread
+	speed := c.Speed.pick(rnd)
+	s, co := math.Sincos(float64(angle))
+
+	p := Particle{
+		Pos: g.PointF{
+			X: e.pos.X + (rnd.Float32()-0.5)*c.Area.W,
+			Y: e.pos.Y + (rnd.Float32()-0.5)*c.Area.H,
+		},
+		Vel:      g.PointF{X: speed * float32(co), Y: speed * float32(s)},
+		Life:     c.Lifetime.pick(rnd),
+		Rotation: c.Rotation.pick(rnd),
+		size:     c.Size.pick(rnd),
+		spin:     c.Spin.pick(rnd),
+	}
+	p.Size = p.size * c.SizeOverLife.At(0)
+	p.Color = c.Color.At(0)
+	e.particles = append(e.particles, p)
+}
+
+// Update moves particles dt forward, kills old ones and spawns new ones.
+func (e *Emitter) Update(dt time.Duration) {
+	if dt <= 0 {
+		return
+	}
+	c := &e.config
+	sec := float32(dt.Seconds())
+
+	// Velocity loses Drag of itself every second, integrated so it doesn't
+	// depend on the frame rate
+	drag := float32(1)
+	if c.Drag > 0 {
+		drag = float32(math.Exp(-float64(c.Drag * sec)))
+	}
+
+	alive := e.particles[:0]
+	for _, p := range e.particles {
+		p.Age += sec
+		if p.Age >= p.Life {
+			continue
+		}
+		t := p.Age / p.Life
+
+		p.Vel.X = (p.Vel.X + c.Gravity.X*sec) * drag
+		p.Vel.Y = (p.Vel.Y + c.Gravity.Y*sec) * drag
+		p.Pos.X += p.Vel.X * sec
+		p.Pos.Y += p.Vel.Y * sec
+		p.Rotation += p.spin * c.SpinOverLife.At(t) * sec
+		p.Size = p.size * c.SizeOverLife.At(t)
+		p.Color = c.Color.At(t)
+
+		alive = append(alive, p)
+	}
+	e.particles = alive
+
+	// Only the part of dt before the end of emitter's duration spawns
+	// particles
+	if e.Emitting() {
+		spawnTime := dt
+		if c.Duration > 0 && e.clock+dt > c.Duration {
+			spawnTime = c.Duration - e.clock
+		}
+		e.pending += c.Rate * float32(spawnTime.Seconds())
+		for e.pending >= 1 {
+			e.pending--
+			if e.room() {
+				e.spawn()
+			}
+		}
+	}
+	e.clock += dt
+
+	if !e.stopped {
+		for e.burst < len(c.Bursts) && c.Bursts[e.burst].Time <= e.clock {
+			e.Burst(c.Bursts[e.burst].Count)
+			e.burst++
+		}
+	}
+}
+
+// Duration returns animation.Infinite: an emitter is never removed from
+// an animation player, even when it has finished.
+func (e *Emitter) Duration() time.Duration {
+	return animation.Infinite
+}
+
+// Seek updates the emitter up to time t since its start. Particles can't be
+// simulated backwards, so seeking back restarts the emitter.
+func (e *Emitter) Seek(t time.Duration) {
+	if t < e.clock {
+		e.Rewind()
+	}
+	e.Update(t - e.clock)
+}
+
+// Rewind kills all the particles and restarts the emitter.
+func (e *Emitter) Rewind() {
+	e.particles = e.particles[:0]
+	e.clock = 0
+	e.pending = 0
+	e.burst = 0
+	e.stopped = false
+}
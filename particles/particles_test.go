@@ -0,0 +1,116 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package particles
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	g "github.com/Sergobot/Rocky/geometry"
+	"github.com/Sergobot/Rocky/paint"
+)
+
+func near(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-3
+}
+
+func TestCurves(t *testing.T) {
+	c := Curve{{0, 0}, {0.5, 10}, {1, 0}}
+	for _, k := range []Key{{-1, 0}, {0.25, 5}, {0.5, 10}, {0.75, 5}, {2, 0}} {
+		if got := c.At(k.T); !near(got, k.V) {
+			t.Errorf("At(%v) = %v, want %v", k.T, got, k.V)
+		}
+	}
+	if got := (Curve{}).At(0.3); got != 1 {
+		t.Errorf("Empty curve = %v, want 1", got)
+	}
+
+	cc := ColorCurve{{Offset: 0, Color: paint.White}, {Offset: 1, Color: paint.Transparent}}
+	if got := cc.At(0.5); !near(got.A, 0.5) {
+		t.Errorf("Color at the middle = %v, want half transparent", got)
+	}
+}
+
+func TestEmitterRate(t *testing.T) {
+	e := NewEmitter(Config{
+		Rate:     10,
+		Duration: time.Second,
+		Lifetime: Fixed(0.5),
+		Speed:    Fixed(100),
+	})
+
+	e.Update(100 * time.Millisecond)
+	if n := len(e.Particles()); n != 1 {
+		t.Errorf("Got %d particles after 0.1s, want 1", n)
+	}
+
+	// Particles live for 0.5s, so there are about 5 alive at a time
+	for i := 0; i < 8; i++ {
+		e.Update(100 * time.Millisecond)
+	}
+	if n := len(e.Particles()); n < 4 || n > 6 {
+		t.Errorf("Got %d particles after 0.9s, want about 5", n)
+	}
+
+	// Nothing is spawned after the duration, so all particles die soon
+	e.Update(time.Second)
+	e.Update(time.Second)
+	if !e.Finished() {
+		t.Errorf("Emitter hasn't finished, %d particles alive", len(e.Particles()))
+	}
+
+	e.Rewind()
+	if !e.Emitting() || len(e.Particles()) != 0 {
+		t.Error("Rewind hasn't restarted the emitter")
+	}
+}
+
+func TestEmitterBursts(t *testing.T) {
+	e := NewEmitter(Config{
+		Bursts:       []Burst{{0, 5}, {time.Second, 10}},
+		MaxParticles: 12,
+		Lifetime:     Fixed(10),
+	})
+
+	e.Seek(time.Millisecond)
+	if n := len(e.Particles()); n != 5 {
+		t.Errorf("Got %d particles after the first burst, want 5", n)
+	}
+	e.Seek(time.Second)
+	if n := len(e.Particles()); n != 12 {
+		t.Errorf("Got %d particles after the second burst, want 12 at most", n)
+	}
+}
+
+func TestParticleMotion(t *testing.T) {
+	e := NewEmitter(Config{
+		Lifetime:     Fixed(2),
+		Direction:    math.Pi / 2,
+		Speed:        Fixed(10),
+		Gravity:      g.PointF{X: 4},
+		Size:         Fixed(8),
+		SizeOverLife: Line(1, 0),
+		Spin:         Fixed(1),
+	})
+	e.SetPos(g.PointF{X: 5, Y: 5})
+	e.Burst(1)
+
+	e.Update(time.Second)
+	p := e.Particles()[0]
+	// Moving down at 10 and gaining speed to the right
+	if !near(p.Pos.X, 9) || !near(p.Pos.Y, 15) || !near(p.Vel.X, 4) {
+		t.Errorf("Particle at %v with velocity %v, want {9 15} and {4 10}", p.Pos, p.Vel)
+	}
+	if !near(p.Size, 4) || !near(p.Rotation, 1) {
+		t.Errorf("Particle size = %v and rotation = %v, want 4 and 1", p.Size, p.Rotation)
+	}
+
+	// Drag of 2 leaves 1/e of velocity after half a second
+	e.SetConfig(Config{Drag: 2})
+	e.Update(time.Second / 4)
+	e.Update(time.Second / 4)
+	if p := e.Particles(); len(p) != 1 || !near(p[0].Vel.Y, 10/float32(math.E)) {
+		t.Errorf("Particles after drag: %v", p)
+	}
+}
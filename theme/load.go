@@ -0,0 +1,191 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package theme
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	g "github.com/Sergobot/Rocky/geometry"
+	"github.com/Sergobot/Rocky/paint"
+)
+
+// Theme files are JSON documents like this one:
+//
+//	{
+//	    "colors": {"blue": "#3f8ce6"},
+//	    "styles": {
+//	        "*": {"background": "#333338", "accent": "blue", "padding": 0.01},
+//	        "Slider:focused": {"foreground": "#fff"},
+//	        ".danger": {"accent": "#e33"},
+//	        "Dropdown": {
+//	            "skin": {"image": "box.png", "border": [4, 4, 4, 4]},
+//	            "font": {"family": "Sans", "size": 18}
+//	        }
+//	    }
+//	}
+//
+// Colors are written in hex or by name from the "colors" palette. Paddings and
+// skin borders are either a single number or four of them: left, top, right
+// and bottom.
+
+type fileJSON struct {
+	Colors map[string]string    `json:"colors"`
+	Styles map[string]styleJSON `json:"styles"`
+}
+
+type styleJSON struct {
+	Background  *string   `json:"background"`
+	Foreground  *string   `json:"foreground"`
+	Accent      *string   `json:"accent"`
+	BorderColor *string   `json:"borderColor"`
+	BorderWidth *float32  `json:"borderWidth"`
+	Radius      *float32  `json:"radius"`
+	Padding     *insets   `json:"padding"`
+	Font        *fontJSON `json:"font"`
+	Skin        *skinJSON `json:"skin"`
+}
+
+type fontJSON struct {
+	Family string  `json:"family"`
+	Size   float32 `json:"size"`
+}
+
+type skinJSON struct {
+	Image  string `json:"image"`
+	Border insets `json:"border"`
+}
+
+// insets are decoded either from a number or from an array of four numbers.
+type insets g.InsetsF
+
+func (in *insets) UnmarshalJSON(data []byte) error {
+	var d float32
+	if err := json.Unmarshal(data, &d); err == nil {
+		*in = insets(g.UniformInsets(d))
+		return nil
+	}
+
+	var a [4]float32
+	if err := json.Unmarshal(data, &a); err != nil {
+		return fmt.Errorf("Failed to parse insets %s: a number or four of them expected", data)
+	}
+	*in = insets{a[0], a[1], a[2], a[3]}
+	return nil
+}
+
+// Load reads a theme from JSON. Relative paths of skin images are kept as they
+// are, use LoadFile to resolve them relative to the theme file.
+func Load(r io.Reader) (*Theme, error) {
+	return load(r, "")
+}
+
+// LoadFile reads a theme from a JSON file. Paths of skin images are relative to
+// the directory of the file.
+func LoadFile(file string) (*Theme, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load theme: %v", err)
+	}
+	defer f.Close()
+
+	return load(f, filepath.Dir(file))
+}
+
+func load(r io.Reader, dir string) (*Theme, error) {
+	var file fileJSON
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("Failed to load theme: %v", err)
+	}
+
+	palette := make(map[string]paint.Color, len(file.Colors))
+	for name, s := range file.Colors {
+		c, err := paint.ParseColor(s)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load theme: color %q: %v", name, err)
+		}
+		palette[name] = c
+	}
+
+	// JSON objects have no order, so styles are added sorted by selector to
+	// make the result predictable
+	selectors := make([]string, 0, len(file.Styles))
+	for sel := range file.Styles {
+		selectors = append(selectors, sel)
+	}
+	sort.Strings(selectors)
+
+	t := New()
+	for _, sel := range selectors {
+		sj := file.Styles[sel]
+		s, err := sj.style(palette, dir)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load theme: style %q: %v", sel, err)
+		}
+		if err = t.Set(sel, s); err != nil {
+			return nil, fmt.Errorf("Failed to load theme: %v", err)
+		}
+	}
+
+	return t, nil
+}
+
+func (sj *styleJSON) style(palette map[string]paint.Color, dir string) (Style, error) {
+	var (
+		s   Style
+		err error
+	)
+
+	colors := []struct {
+		src *string
+		dst **paint.Color
+	}{
+		{sj.Background, &s.Background},
+		{sj.Foreground, &s.Foreground},
+		{sj.Accent, &s.Accent},
+		{sj.BorderColor, &s.BorderColor},
+	}
+	for _, c := range colors {
+		if c.src == nil {
+			continue
+		}
+		if *c.dst, err = lookupColor(*c.src, palette); err != nil {
+			return s, err
+		}
+	}
+
+	s.BorderWidth = sj.BorderWidth
+	s.Radius = sj.Radius
+	if sj.Padding != nil {
+		p := g.InsetsF(*sj.Padding)
+		s.Padding = &p
+	}
+	if sj.Font != nil {
+		s.Font = &Font{Family: sj.Font.Family, Size: sj.Font.Size}
+	}
+	if sj.Skin != nil {
+		img := sj.Skin.Image
+		if img != "" && dir != "" && !filepath.IsAbs(img) {
+			img = filepath.Join(dir, img)
+		}
+		s.Skin = &Skin{Image: img, Border: g.InsetsF(sj.Skin.Border)}
+	}
+
+	return s, nil
+}
+
+// lookupColor returns a color from the palette or parses it from hex.
+func lookupColor(s string, palette map[string]paint.Color) (*paint.Color, error) {
+	if c, ok := palette[s]; ok {
+		return &c, nil
+	}
+	c, err := paint.ParseColor(s)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
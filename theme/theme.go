@@ -0,0 +1,338 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+// Package theme describes the look of widgets: colors, fonts, paddings and
+// skins. Widgets consult the current theme every time they are drawn, so
+// swapping the theme restyles all of them at once.
+package theme
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	g "github.com/Sergobot/Rocky/geometry"
+	"github.com/Sergobot/Rocky/paint"
+)
+
+// State is a bit set describing what's going on with a widget. Styles may have
+// variants for some states, like a brighter border for a focused widget.
+type State int
+
+// Widget states. Normal means none of the others.
+const (
+	Normal   State = 0
+	Focused  State = 1 << 0
+	Pressed  State = 1 << 1
+	Checked  State = 1 << 2
+	Disabled State = 1 << 3
+)
+
+// stateNames are names of states used in selectors, like "Slider:focused".
+var stateNames = map[string]State{
+	"focused":  Focused,
+	"pressed":  Pressed,
+	"checked":  Checked,
+	"disabled": Disabled,
+}
+
+// Font describes a font for widgets showing text. Size is in pixels.
+type Font struct {
+	Family string
+	Size   float32
+}
+
+// Skin is an image drawn as a nine-patch instead of a plain background: its
+// corners are kept intact, while edges and the center are stretched. Border
+// is the size of corners in pixels of the image.
+type Skin struct {
+	Image  string
+	Border g.InsetsF
+}
+
+// Style is a set of properties used to draw a widget. Nil properties are not
+// set, so styles may be merged: a more specific style overrides only what it
+// sets. Styles returned by Theme.Style have everything set.
+type Style struct {
+	// Background fills tracks and boxes, Accent marks values and selection,
+	// Foreground is used for knobs, marks and text.
+	Background *paint.Color
+	Foreground *paint.Color
+	Accent     *paint.Color
+
+	// Border is drawn inside widget's background. Its width is in pixels.
+	BorderColor *paint.Color
+	BorderWidth *float32
+
+	// Radius of rounded corners as a fraction of the smaller side of the
+	// rounded rect, 0.5 makes it fully round.
+	Radius *float32
+
+	// Padding is the space between the edges of a widget and its contents, in
+	// the same units as geometry.
+	Padding *g.InsetsF
+
+	Font *Font
+
+	// Skin replaces the background, if it's set.
+	Skin *Skin
+}
+
+// Merge returns s with all the properties set in o replaced.
+func (s Style) Merge(o Style) Style {
+	if o.Background != nil {
+		s.Background = o.Background
+	}
+	if o.Foreground != nil {
+		s.Foreground = o.Foreground
+	}
+	if o.Accent != nil {
+		s.Accent = o.Accent
+	}
+	if o.BorderColor != nil {
+		s.BorderColor = o.BorderColor
+	}
+	if o.BorderWidth != nil {
+		s.BorderWidth = o.BorderWidth
+	}
+	if o.Radius != nil {
+		s.Radius = o.Radius
+	}
+	if o.Padding != nil {
+		s.Padding = o.Padding
+	}
+	if o.Font != nil {
+		s.Font = o.Font
+	}
+	if o.Skin != nil {
+		s.Skin = o.Skin
+	}
+	return s
+}
+
+// Color returns a pointer to c, handy to fill Style literals.
+func Color(c paint.Color) *paint.Color {
+	return &c
+}
+
+// Float returns a pointer to f, handy to fill Style literals.
+func Float(f float32) *float32 {
+	return &f
+}
+
+// rule is a style applied to widgets matching a selector.
+type rule struct {
+	typ, class string
+	states     State
+
+	style Style
+
+	// Rules added later win over rules of the same specificity
+	order int
+}
+
+// specificity works like in CSS: a class or a state is worth more than a type.
+func (r *rule) specificity() int {
+	s := 10 * bitCount(int(r.states))
+	if r.typ != "" {
+		s++
+	}
+	if r.class != "" {
+		s += 10
+	}
+	return s
+}
+
+func (r *rule) matches(typ, class string, state State) bool {
+	return (r.typ == "" || r.typ == typ) &&
+		(r.class == "" || r.class == class) &&
+		r.states&state == r.states
+}
+
+// Theme is a set of styles with selectors, something like a CSS style sheet.
+// Selectors consist of a widget type, a class and states, all optional:
+//
+//	"*"                every widget
+//	"Slider"           every slider
+//	".volume"          widgets with class "volume"
+//	"Slider.volume"    sliders with class "volume"
+//	"CheckBox:checked" checked check boxes
+//
+// Widget types are names of widget structs, classes are set with SetStyleClass
+// of a widget. When a widget is drawn, all the matching styles are merged,
+// more specific ones overriding less specific ones.
+type Theme struct {
+	rules []*rule
+}
+
+// New returns an empty theme. Everything it doesn't set is taken from the
+// default theme.
+func New() *Theme {
+	return new(Theme)
+}
+
+// Set sets the style for a selector, replacing the old one, if there was any.
+func (t *Theme) Set(selector string, s Style) error {
+	r, err := parseSelector(selector)
+	if err != nil {
+		return err
+	}
+	r.style = s
+
+	for i, old := range t.rules {
+		if old.typ == r.typ && old.class == r.class && old.states == r.states {
+			r.order = old.order
+			t.rules[i] = r
+			return nil
+		}
+	}
+	r.order = len(t.rules)
+	t.rules = append(t.rules, r)
+	return nil
+}
+
+// Style returns the style of a widget of the given type and class, which is
+// in the given state. The result has every property set: whatever the theme
+// doesn't set is taken from the default theme.
+func (t *Theme) Style(typ, class string, state State) Style {
+	s := fallback
+	if t != defaultTheme {
+		s = defaultTheme.Style(typ, class, state)
+	}
+	return s.Merge(t.match(typ, class, state))
+}
+
+// match merges the styles of all the rules matching a widget.
+func (t *Theme) match(typ, class string, state State) Style {
+	var matched []*rule
+	for _, r := range t.rules {
+		if r.matches(typ, class, state) {
+			matched = append(matched, r)
+		}
+	}
+	sort.Sort(bySpecificity(matched))
+
+	var s Style
+	for _, r := range matched {
+		s = s.Merge(r.style)
+	}
+	return s
+}
+
+type bySpecificity []*rule
+
+func (b bySpecificity) Len() int      { return len(b) }
+func (b bySpecificity) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b bySpecificity) Less(i, j int) bool {
+	si, sj := b[i].specificity(), b[j].specificity()
+	if si != sj {
+		return si < sj
+	}
+	return b[i].order < b[j].order
+}
+
+// parseSelector parses a selector like "Type.class:state:state".
+func parseSelector(sel string) (*rule, error) {
+	r := new(rule)
+
+	parts := strings.Split(sel, ":")
+	for _, name := range parts[1:] {
+		st, ok := stateNames[name]
+		if !ok {
+			return nil, fmt.Errorf("Failed to parse selector %q: unknown state %q", sel, name)
+		}
+		r.states |= st
+	}
+
+	head := parts[0]
+	if head == "*" {
+		return r, nil
+	}
+	if i := strings.Index(head, "."); i >= 0 {
+		r.typ, r.class = head[:i], head[i+1:]
+		if r.class == "" {
+			return nil, fmt.Errorf("Failed to parse selector %q: empty class", sel)
+		}
+	} else {
+		r.typ = head
+	}
+	return r, nil
+}
+
+func bitCount(x int) int {
+	n := 0
+	for ; x != 0; x &= x - 1 {
+		n++
+	}
+	return n
+}
+
+// fallback has every property set, so resolved styles never have nil ones.
+var fallback = Style{
+	Background:  Color(paint.Color{R: 0.2, G: 0.2, B: 0.22, A: 1}),
+	Foreground:  Color(paint.Color{R: 0.9, G: 0.9, B: 0.9, A: 1}),
+	Accent:      Color(paint.Color{R: 0.25, G: 0.55, B: 0.9, A: 1}),
+	BorderColor: Color(paint.Color{R: 0.25, G: 0.55, B: 0.9, A: 1}),
+	BorderWidth: Float(0),
+	Radius:      Float(0.5),
+	Padding:     &g.InsetsF{},
+	Font:        &Font{Family: "sans-serif", Size: 16},
+	Skin:        &Skin{},
+}
+
+// defaultTheme is the built-in look of widgets.
+var defaultTheme = func() *Theme {
+	t := New()
+	t.Set("*:disabled", Style{
+		Accent:      Color(paint.Color{R: 0.4, G: 0.4, B: 0.42, A: 1}),
+		BorderColor: Color(paint.Color{R: 0.4, G: 0.4, B: 0.42, A: 1}),
+		Foreground:  Color(paint.Color{R: 0.55, G: 0.55, B: 0.55, A: 1}),
+	})
+	t.Set("CheckBox", Style{BorderWidth: Float(2), Radius: Float(0.15)})
+	t.Set("Dropdown", Style{Radius: Float(0.15)})
+	t.Set("DropdownList", Style{BorderWidth: Float(1), Radius: Float(0)})
+	t.Set("RadioGroup", Style{BorderWidth: Float(2)})
+	return t
+}()
+
+// Default returns the built-in theme.
+func Default() *Theme {
+	return defaultTheme
+}
+
+// Widgets are drawn in a single thread, so there are no locks here
+var (
+	current   = defaultTheme
+	listeners = map[int]func(*Theme){}
+	nextID    int
+)
+
+// Current returns the theme widgets are drawn with.
+func Current() *Theme {
+	return current
+}
+
+// SetCurrent swaps the theme widgets are drawn with. Widgets pick it up the
+// next time they are drawn, functions passed to OnChange are called right
+// away. Pass nil to get back to the default theme.
+func SetCurrent(t *Theme) {
+	if t == nil {
+		t = defaultTheme
+	}
+
+	current = t
+	for _, f := range listeners {
+		f(t)
+	}
+}
+
+// OnChange registers a function called every time the current theme is
+// swapped. It's useful for widgets caching something derived from the theme.
+// Call the returned function to unregister it.
+func OnChange(f func(*Theme)) (cancel func()) {
+	id := nextID
+	nextID++
+	listeners[id] = f
+	return func() {
+		delete(listeners, id)
+	}
+}
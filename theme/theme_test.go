@@ -0,0 +1,102 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package theme
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Sergobot/Rocky/paint"
+)
+
+func TestStyle(t *testing.T) {
+	red := paint.Color{R: 1, A: 1}
+	green := paint.Color{G: 1, A: 1}
+	blue := paint.Color{B: 1, A: 1}
+
+	th := New()
+	th.Set(".danger", Style{Accent: Color(red)})
+	th.Set("Slider", Style{Accent: Color(green), Foreground: Color(green)})
+	th.Set("Slider:focused", Style{Foreground: Color(blue)})
+
+	cases := []struct {
+		name               string
+		typ, class         string
+		state              State
+		accent, foreground paint.Color
+	}{
+		{"type", "Slider", "", Normal, green, green},
+		{"state", "Slider", "", Focused | Pressed, green, blue},
+		{"class beats type", "Slider", "danger", Normal, red, green},
+		{"other type", "CheckBox", "", Normal, *fallback.Accent, *fallback.Foreground},
+	}
+	for _, c := range cases {
+		s := th.Style(c.typ, c.class, c.state)
+		if *s.Accent != c.accent || *s.Foreground != c.foreground {
+			t.Errorf("%s: accent = %v, foreground = %v, want %v and %v",
+				c.name, *s.Accent, *s.Foreground, c.accent, c.foreground)
+		}
+	}
+
+	// Every property is set, even if the theme doesn't set it
+	if s := th.Style("Slider", "", Normal); s.Padding == nil || s.Skin == nil || s.Font == nil {
+		t.Errorf("Style() left some properties unset: %+v", s)
+	}
+
+	if err := th.Set("Slider:hovered", Style{}); err == nil {
+		t.Errorf("Set() accepted an unknown state")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	src := `{
+		"colors": {"blue": "#00f"},
+		"styles": {
+			"*": {"accent": "blue", "padding": 0.5},
+			"Dropdown": {"skin": {"image": "box.png", "border": [1, 2, 3, 4]}},
+			"CheckBox:checked": {"background": "#ff000080"}
+		}
+	}`
+
+	th, err := Load(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := th.Style("Dropdown", "", Normal)
+	if *s.Accent != (paint.Color{B: 1, A: 1}) {
+		t.Errorf("accent = %v, want blue", *s.Accent)
+	}
+	if s.Padding.Left != 0.5 || s.Padding.Bottom != 0.5 {
+		t.Errorf("padding = %v, want 0.5 everywhere", *s.Padding)
+	}
+	if s.Skin.Image != "box.png" || s.Skin.Border.Top != 2 || s.Skin.Border.Bottom != 4 {
+		t.Errorf("skin = %v", *s.Skin)
+	}
+
+	s = th.Style("CheckBox", "", Checked)
+	if want := (paint.Color{R: 1, A: 128.0 / 255}); *s.Background != want {
+		t.Errorf("background = %v, want %v", *s.Background, want)
+	}
+
+	if _, err = Load(strings.NewReader(`{"styles": {"*": {"accent": "nope"}}}`)); err == nil {
+		t.Errorf("Load() accepted a wrong color")
+	}
+}
+
+func TestSetCurrent(t *testing.T) {
+	var got *Theme
+	cancel := OnChange(func(th *Theme) { got = th })
+
+	th := New()
+	SetCurrent(th)
+	if Current() != th || got != th {
+		t.Errorf("theme wasn't swapped")
+	}
+
+	cancel()
+	SetCurrent(nil)
+	if Current() != Default() || got != th {
+		t.Errorf("theme wasn't reset or a cancelled listener was called")
+	}
+}
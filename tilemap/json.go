@@ -0,0 +1,289 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package tilemap
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	g "github.com/Sergobot/Rocky/geometry"
+)
+
+type jsonMap struct {
+	Orientation string `json:"orientation"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	TileWidth   int    `json:"tilewidth"`
+	TileHeight  int    `json:"tileheight"`
+	Infinite    bool   `json:"infinite"`
+
+	Properties jsonProperties `json:"properties"`
+	Tilesets   []jsonTileset  `json:"tilesets"`
+	Layers     []jsonLayer    `json:"layers"`
+}
+
+type jsonProperties []struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+}
+
+type jsonTileset struct {
+	FirstGID    GID    `json:"firstgid"`
+	Source      string `json:"source"`
+	Name        string `json:"name"`
+	Image       string `json:"image"`
+	ImageWidth  int    `json:"imagewidth"`
+	ImageHeight int    `json:"imageheight"`
+	TileWidth   int    `json:"tilewidth"`
+	TileHeight  int    `json:"tileheight"`
+	Spacing     int    `json:"spacing"`
+	Margin      int    `json:"margin"`
+	TileCount   int    `json:"tilecount"`
+	Columns     int    `json:"columns"`
+
+	Tiles []struct {
+		ID        int    `json:"id"`
+		Type      string `json:"type"`
+		Class     string `json:"class"`
+		Animation []struct {
+			TileID   int `json:"tileid"`
+			Duration int `json:"duration"`
+		} `json:"animation"`
+		Properties jsonProperties `json:"properties"`
+	} `json:"tiles"`
+
+	Properties jsonProperties `json:"properties"`
+}
+
+type jsonLayer struct {
+	Type    string   `json:"type"`
+	Name    string   `json:"name"`
+	Visible *bool    `json:"visible"`
+	Opacity *float32 `json:"opacity"`
+	OffsetX float32  `json:"offsetx"`
+	OffsetY float32  `json:"offsety"`
+
+	Properties jsonProperties `json:"properties"`
+
+	// Tile layers: data is an array of GIDs or a base64 string
+	Data        json.RawMessage `json:"data"`
+	Encoding    string          `json:"encoding"`
+	Compression string          `json:"compression"`
+
+	Objects []jsonObject `json:"objects"`
+
+	Layers []jsonLayer `json:"layers"`
+}
+
+type jsonObject struct {
+	ID       int        `json:"id"`
+	Name     string     `json:"name"`
+	Type     string     `json:"type"`
+	Class    string     `json:"class"`
+	X        float32    `json:"x"`
+	Y        float32    `json:"y"`
+	Width    float32    `json:"width"`
+	Height   float32    `json:"height"`
+	Rotation float32    `json:"rotation"`
+	GID      GID        `json:"gid"`
+	Visible  *bool      `json:"visible"`
+	Ellipse  bool       `json:"ellipse"`
+	Point    bool       `json:"point"`
+	Polygon  []g.PointF `json:"polygon"`
+	Polyline []g.PointF `json:"polyline"`
+
+	Properties jsonProperties `json:"properties"`
+}
+
+func loadJSON(r io.Reader, dir string) (*Map, error) {
+	var jm jsonMap
+	if err := json.NewDecoder(r).Decode(&jm); err != nil {
+		return nil, fmt.Errorf("Failed to load map: %v", err)
+	}
+
+	m := &Map{
+		Width:      jm.Width,
+		Height:     jm.Height,
+		TileWidth:  jm.TileWidth,
+		TileHeight: jm.TileHeight,
+		Properties: jm.Properties.convert(),
+	}
+	if err := m.check(jm.Orientation, jm.Infinite); err != nil {
+		return nil, err
+	}
+
+	for i := range jm.Tilesets {
+		t := &jm.Tilesets[i]
+		var (
+			ts  *Tileset
+			err error
+		)
+		if t.Source != "" {
+			ts, err = loadTileset(t.Source, dir)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			ts = t.convert(dir)
+		}
+		ts.FirstGID = t.FirstGID
+		m.Tilesets = append(m.Tilesets, ts)
+	}
+
+	if err := m.addJSONLayers(jm.Layers, layerState{visible: true, opacity: 1}); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func loadTilesetJSON(r io.Reader, dir string) (*Tileset, error) {
+	var t jsonTileset
+	if err := json.NewDecoder(r).Decode(&t); err != nil {
+		return nil, fmt.Errorf("Failed to load tileset: %v", err)
+	}
+	return t.convert(dir), nil
+}
+
+func (t *jsonTileset) convert(dir string) *Tileset {
+	ts := &Tileset{
+		Name:        t.Name,
+		Image:       resolve(t.Image, dir),
+		ImageWidth:  t.ImageWidth,
+		ImageHeight: t.ImageHeight,
+		TileWidth:   t.TileWidth,
+		TileHeight:  t.TileHeight,
+		Spacing:     t.Spacing,
+		Margin:      t.Margin,
+		TileCount:   t.TileCount,
+		Columns:     t.Columns,
+		Tiles:       map[int]*Tile{},
+		Properties:  t.Properties.convert(),
+	}
+
+	for _, tt := range t.Tiles {
+		tile := &Tile{Type: tt.Type, Properties: tt.Properties.convert()}
+		if tt.Class != "" {
+			tile.Type = tt.Class
+		}
+		for _, f := range tt.Animation {
+			tile.Animation = append(tile.Animation, Frame{
+				TileID:   f.TileID,
+				Duration: time.Duration(f.Duration) * time.Millisecond,
+			})
+		}
+		ts.Tiles[tt.ID] = tile
+	}
+
+	ts.fix()
+	return ts
+}
+
+func (jp jsonProperties) convert() Properties {
+	p := Properties{}
+	for _, prop := range jp {
+		p[prop.Name] = fmt.Sprint(prop.Value)
+	}
+	return p
+}
+
+func (jl *jsonLayer) state(parent layerState) layerState {
+	s := parent
+	if jl.Visible != nil && !*jl.Visible {
+		s.visible = false
+	}
+	if jl.Opacity != nil {
+		s.opacity *= *jl.Opacity
+	}
+	s.offset[0] += jl.OffsetX
+	s.offset[1] += jl.OffsetY
+	return s
+}
+
+// addJSONLayers converts layers and adds them to the map. Groups are
+// flattened.
+func (m *Map) addJSONLayers(layers []jsonLayer, parent layerState) error {
+	for i := range layers {
+		jl := &layers[i]
+		s := jl.state(parent)
+		props := jl.Properties.convert()
+
+		switch jl.Type {
+		case "tilelayer":
+			l := s.layer(TileLayer, jl.Name, props)
+
+			tiles, err := jl.tiles(m.Width * m.Height)
+			if err != nil {
+				return fmt.Errorf("Failed to load layer %q: %v", jl.Name, err)
+			}
+			l.Tiles = tiles
+			m.Layers = append(m.Layers, l)
+
+		case "objectgroup":
+			l := s.layer(ObjectLayer, jl.Name, props)
+			for j := range jl.Objects {
+				l.Objects = append(l.Objects, jl.Objects[j].convert())
+			}
+			m.Layers = append(m.Layers, l)
+
+		case "group":
+			if err := m.addJSONLayers(jl.Layers, s); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// tiles decodes GIDs of a tile layer.
+func (jl *jsonLayer) tiles(count int) ([]GID, error) {
+	if jl.Encoding == "base64" {
+		var s string
+		if err := json.Unmarshal(jl.Data, &s); err != nil {
+			return nil, fmt.Errorf("Failed to decode tiles: %v", err)
+		}
+		return decodeTiles(s, jl.Encoding, jl.Compression, count)
+	}
+
+	var tiles []GID
+	if err := json.Unmarshal(jl.Data, &tiles); err != nil {
+		return nil, fmt.Errorf("Failed to decode tiles: %v", err)
+	}
+	if len(tiles) != count {
+		return nil, fmt.Errorf("Failed to decode tiles: got %d tiles, want %d", len(tiles), count)
+	}
+	return tiles, nil
+}
+
+func (jo *jsonObject) convert() *Object {
+	o := &Object{
+		ID:         jo.ID,
+		Name:       jo.Name,
+		Type:       jo.Type,
+		Rotation:   jo.Rotation,
+		GID:        jo.GID,
+		Visible:    jo.Visible == nil || *jo.Visible,
+		Properties: jo.Properties.convert(),
+	}
+	if jo.Class != "" {
+		o.Type = jo.Class
+	}
+
+	var points []g.PointF
+	switch {
+	case jo.GID != 0:
+		o.Shape = TileShape
+	case jo.Ellipse:
+		o.Shape = Ellipse
+	case jo.Point:
+		o.Shape = Point
+	case jo.Polygon != nil:
+		o.Shape, points = Polygon, jo.Polygon
+	case jo.Polyline != nil:
+		o.Shape, points = Polyline, jo.Polyline
+	}
+
+	o.place(jo.X, jo.Y, jo.Width, jo.Height, points)
+	return o
+}
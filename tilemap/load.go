@@ -0,0 +1,238 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package tilemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	g "github.com/Sergobot/Rocky/geometry"
+)
+
+// LoadFile loads a map from a TMX or JSON file exported by Tiled, depending on
+// the extension (.tmx, .json or .tmj). Paths of images and external tilesets
+// are relative to the file referencing them.
+func LoadFile(file string) (*Map, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load map: %v", err)
+	}
+	defer f.Close()
+
+	dir := filepath.Dir(file)
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".tmx":
+		return loadTMX(f, dir)
+	case ".json", ".tmj":
+		return loadJSON(f, dir)
+	}
+	return nil, fmt.Errorf("Failed to load map from %q: unknown format", file)
+}
+
+// LoadTMX reads a map in TMX format. Paths of images and external tilesets
+// are relative to the current directory, use LoadFile to resolve them
+// relative to the map file.
+func LoadTMX(r io.Reader) (*Map, error) {
+	return loadTMX(r, "")
+}
+
+// LoadJSON reads a map in Tiled's JSON format. Paths of images and external
+// tilesets are relative to the current directory, use LoadFile to resolve
+// them relative to the map file.
+func LoadJSON(r io.Reader) (*Map, error) {
+	return loadJSON(r, "")
+}
+
+// loadTileset loads an external tileset from a TSX or JSON file.
+func loadTileset(source, dir string) (*Tileset, error) {
+	file := resolve(source, dir)
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load tileset: %v", err)
+	}
+	defer f.Close()
+
+	if strings.ToLower(filepath.Ext(file)) == ".tsx" {
+		return loadTSX(f, filepath.Dir(file))
+	}
+	return loadTilesetJSON(f, filepath.Dir(file))
+}
+
+// resolve makes a path relative to dir, unless it's absolute.
+func resolve(path, dir string) string {
+	if path == "" || dir == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// decodeTiles decodes tile data of a layer. Encoding is "csv" or "base64",
+// the latter may be compressed with "zlib" or "gzip".
+func decodeTiles(data, encoding, compression string, count int) ([]GID, error) {
+	var tiles []GID
+
+	switch encoding {
+	case "csv":
+		for _, s := range strings.Split(data, ",") {
+			s = strings.TrimSpace(s)
+			if s == "" {
+				continue
+			}
+			id, err := strconv.ParseUint(s, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to decode tiles: %v", err)
+			}
+			tiles = append(tiles, GID(id))
+		}
+	case "base64":
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+		if err != nil {
+			return nil, fmt.Errorf("Failed to decode tiles: %v", err)
+		}
+		if raw, err = decompress(raw, compression); err != nil {
+			return nil, err
+		}
+		if len(raw)%4 != 0 {
+			return nil, fmt.Errorf("Failed to decode tiles: %d bytes is not a whole number of tiles", len(raw))
+		}
+		tiles = make([]GID, len(raw)/4)
+		for i := range tiles {
+			tiles[i] = GID(binary.LittleEndian.Uint32(raw[4*i:]))
+		}
+	default:
+		return nil, fmt.Errorf("Failed to decode tiles: unknown encoding %q", encoding)
+	}
+
+	if len(tiles) != count {
+		return nil, fmt.Errorf("Failed to decode tiles: got %d tiles, want %d", len(tiles), count)
+	}
+	return tiles, nil
+}
+
+func decompress(raw []byte, compression string) ([]byte, error) {
+	var (
+		r   io.Reader
+		err error
+	)
+	switch compression {
+	case "":
+		return raw, nil
+	case "zlib":
+		r, err = zlib.NewReader(bytes.NewReader(raw))
+	case "gzip":
+		r, err = gzip.NewReader(bytes.NewReader(raw))
+	default:
+		return nil, fmt.Errorf("Failed to decode tiles: unsupported compression %q", compression)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode tiles: %v", err)
+	}
+
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode tiles: %v", err)
+	}
+	return out, nil
+}
+
+// parsePoints parses points of a polygon in TMX format: "x,y x,y ...".
+func parsePoints(s string) ([]g.PointF, error) {
+	var points []g.PointF
+	for _, pair := range strings.Fields(s) {
+		xy := strings.Split(pair, ",")
+		if len(xy) != 2 {
+			return nil, fmt.Errorf("Failed to parse point %q", pair)
+		}
+		x, err := strconv.ParseFloat(xy[0], 32)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse point %q: %v", pair, err)
+		}
+		y, err := strconv.ParseFloat(xy[1], 32)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse point %q: %v", pair, err)
+		}
+		points = append(points, g.PointF{X: float32(x), Y: float32(y)})
+	}
+	return points, nil
+}
+
+// place sets bounds and absolute points of an object from its position, size
+// and points relative to the position.
+func (o *Object) place(x, y, w, h float32, points []g.PointF) {
+	switch o.Shape {
+	case Polygon, Polyline:
+		o.Points = make([]g.PointF, len(points))
+		for i, p := range points {
+			o.Points[i] = g.PointF{X: x + p.X, Y: y + p.Y}
+		}
+		o.Bounds = boundingBox(o.Points)
+	case TileShape:
+		// Tile objects are placed by their bottom-left corner
+		o.Bounds = g.RectF{PosF: g.PosF{X: x, Y: y - h}, SizeF: g.SizeF{W: w, H: h}}
+	case Point:
+		o.Bounds = g.RectF{PosF: g.PosF{X: x, Y: y}}
+	default:
+		o.Bounds = g.RectF{PosF: g.PosF{X: x, Y: y}, SizeF: g.SizeF{W: w, H: h}}
+	}
+}
+
+func boundingBox(points []g.PointF) g.RectF {
+	if len(points) == 0 {
+		return g.RectF{}
+	}
+	minX, minY := points[0].X, points[0].Y
+	maxX, maxY := minX, minY
+	for _, p := range points[1:] {
+		if p.X < minX {
+			minX = p.X
+		}
+		if p.X > maxX {
+			maxX = p.X
+		}
+		if p.Y < minY {
+			minY = p.Y
+		}
+		if p.Y > maxY {
+			maxY = p.Y
+		}
+	}
+	return g.RectF{PosF: g.PosF{X: minX, Y: minY}, SizeF: g.SizeF{W: maxX - minX, H: maxY - minY}}
+}
+
+// check makes sure the map is something we can draw.
+func (m *Map) check(orientation string, infinite bool) error {
+	if orientation != "" && orientation != "orthogonal" {
+		return fmt.Errorf("Failed to load map: %s maps are not supported", orientation)
+	}
+	if infinite {
+		return fmt.Errorf("Failed to load map: infinite maps are not supported")
+	}
+	if m.Width <= 0 || m.Height <= 0 || m.TileWidth <= 0 || m.TileHeight <= 0 {
+		return fmt.Errorf("Failed to load map: invalid size")
+	}
+	return nil
+}
+
+// fix fills what may be missing in a tileset.
+func (ts *Tileset) fix() {
+	if ts.Columns <= 0 && ts.TileWidth > 0 {
+		ts.Columns = (ts.ImageWidth - 2*ts.Margin + ts.Spacing) / (ts.TileWidth + ts.Spacing)
+	}
+	if ts.TileCount <= 0 && ts.Columns > 0 && ts.TileHeight > 0 {
+		rows := (ts.ImageHeight - 2*ts.Margin + ts.Spacing) / (ts.TileHeight + ts.Spacing)
+		ts.TileCount = rows * ts.Columns
+	}
+	if ts.Tiles == nil {
+		ts.Tiles = map[int]*Tile{}
+	}
+}
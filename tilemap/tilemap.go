@@ -0,0 +1,342 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+// Package tilemap describes tile maps: grids of tiles cut from tileset images,
+// arranged in layers, with object layers marking spawn points, triggers and
+// anything else a level needs. Maps are usually made in the Tiled editor
+// (mapeditor.org) and loaded from TMX or JSON files it exports. Only
+// orthogonal, finite maps are supported. Maps are drawn by TileMap widget.
+package tilemap
+
+import (
+	"strconv"
+	"time"
+
+	g "github.com/Sergobot/Rocky/geometry"
+)
+
+// GID is a global tile ID: an index of a tile among all the tilesets of a map.
+// Zero means there is no tile. The highest bits are flip flags.
+type GID uint32
+
+// Flip flags of a GID, the same as in Tiled.
+const (
+	FlipHorizontal GID = 1 << 31
+	FlipVertical   GID = 1 << 30
+	FlipDiagonal   GID = 1 << 29
+
+	flipMask = FlipHorizontal | FlipVertical | FlipDiagonal
+)
+
+// ID returns the GID without flip flags.
+func (id GID) ID() GID {
+	return id &^ flipMask
+}
+
+// Flips returns the flip flags of the GID.
+func (id GID) Flips() GID {
+	return id & flipMask
+}
+
+// Properties are custom properties set in the editor. Values are kept as
+// strings, use the getters to convert them.
+type Properties map[string]string
+
+// String returns a property or def, if there is none.
+func (p Properties) String(name, def string) string {
+	if v, ok := p[name]; ok {
+		return v
+	}
+	return def
+}
+
+// Int returns a property as an integer or def, if there is none or it isn't
+// an integer.
+func (p Properties) Int(name string, def int) int {
+	if v, err := strconv.Atoi(p[name]); err == nil {
+		return v
+	}
+	return def
+}
+
+// Float returns a property as a number or def, if there is none or it isn't
+// a number.
+func (p Properties) Float(name string, def float32) float32 {
+	if v, err := strconv.ParseFloat(p[name], 32); err == nil {
+		return float32(v)
+	}
+	return def
+}
+
+// Bool returns a property as a boolean or def, if there is none or it isn't
+// a boolean.
+func (p Properties) Bool(name string, def bool) bool {
+	if v, err := strconv.ParseBool(p[name]); err == nil {
+		return v
+	}
+	return def
+}
+
+// Frame is a frame of an animated tile: a tile of the same tileset shown for
+// some time.
+type Frame struct {
+	TileID   int
+	Duration time.Duration
+}
+
+// Tile holds what's known about a single tile of a tileset, if there is
+// anything: its animation and properties.
+type Tile struct {
+	Type       string
+	Animation  []Frame
+	Properties Properties
+}
+
+// Tileset is an image cut into tiles of the same size. Tiles are numbered
+// from zero, left to right and top to bottom.
+type Tileset struct {
+	Name string
+
+	// GID of the first tile of the tileset in a map
+	FirstGID GID
+
+	// Path of the image. Sizes are in pixels of the image.
+	Image                   string
+	ImageWidth, ImageHeight int
+
+	TileWidth, TileHeight int
+
+	// Spacing is the gap between tiles, margin is the gap around all of them.
+	Spacing, Margin int
+
+	TileCount, Columns int
+
+	// Tiles with animations or properties, by tile ID
+	Tiles map[int]*Tile
+
+	Properties Properties
+}
+
+// TileRect returns the rect of a tile in pixels of the image.
+func (ts *Tileset) TileRect(id int) g.Rect {
+	columns := ts.Columns
+	if columns <= 0 {
+		columns = 1
+	}
+	return g.Rect{
+		Pos: g.Pos{
+			X: ts.Margin + (id%columns)*(ts.TileWidth+ts.Spacing),
+			Y: ts.Margin + (id/columns)*(ts.TileHeight+ts.Spacing),
+		},
+		Size: g.Size{W: ts.TileWidth, H: ts.TileHeight},
+	}
+}
+
+// Frame returns the tile shown instead of the given one at moment t, counting
+// from the start of the map's animations. Tiles without animations are shown
+// as they are.
+func (ts *Tileset) Frame(id int, t time.Duration) int {
+	tile := ts.Tiles[id]
+	if tile == nil || len(tile.Animation) == 0 {
+		return id
+	}
+
+	var total time.Duration
+	for _, f := range tile.Animation {
+		total += f.Duration
+	}
+	if total <= 0 {
+		return tile.Animation[0].TileID
+	}
+
+	t %= total
+	for _, f := range tile.Animation {
+		if t < f.Duration {
+			return f.TileID
+		}
+		t -= f.Duration
+	}
+	return tile.Animation[len(tile.Animation)-1].TileID
+}
+
+// LayerKind tells what a layer holds.
+type LayerKind int
+
+// Kinds of layers. Image layers of Tiled are not supported, group layers are
+// flattened.
+const (
+	TileLayer   LayerKind = iota
+	ObjectLayer LayerKind = iota
+)
+
+// Layer is a grid of tiles, or a set of objects. Layers are drawn in order,
+// the last one on top.
+type Layer struct {
+	Kind LayerKind
+	Name string
+
+	Visible bool
+	Opacity float32
+
+	// Offset of the layer in pixels
+	Offset g.PointF
+
+	// GIDs of tiles of a tile layer, row by row. It's Width * Height of the
+	// map long.
+	Tiles []GID
+
+	// Objects of an object layer
+	Objects []*Object
+
+	Properties Properties
+}
+
+// ObjectShape is the shape of an object.
+type ObjectShape int
+
+// Shapes of objects. Tile objects are rects showing a tile.
+const (
+	Rectangle ObjectShape = iota
+	Ellipse   ObjectShape = iota
+	Point     ObjectShape = iota
+	Polygon   ObjectShape = iota
+	Polyline  ObjectShape = iota
+	TileShape ObjectShape = iota
+)
+
+// Object is a shape placed on a map. Coordinates are in pixels of the map.
+type Object struct {
+	ID         int
+	Name, Type string
+	Shape      ObjectShape
+
+	// Bounds of the object: the rect itself for rectangles, ellipses and tile
+	// objects, the bounding box for polygons and polylines, an empty rect for
+	// points. Rotation, if any, is not applied.
+	Bounds g.RectF
+
+	// Points of polygons and polylines in map coordinates
+	Points []g.PointF
+
+	// Rotation in degrees clockwise around the top-left corner, or around the
+	// bottom-left one for tile objects
+	Rotation float32
+
+	// Tile of a tile object
+	GID GID
+
+	Visible    bool
+	Properties Properties
+}
+
+// Map is a tile map: layers of tiles of the same size, cut from tilesets.
+type Map struct {
+	// Size in tiles
+	Width, Height int
+
+	// Size of a tile in pixels
+	TileWidth, TileHeight int
+
+	Tilesets []*Tileset
+	Layers   []*Layer
+
+	Properties Properties
+}
+
+// Tileset returns the tileset a tile belongs to and the ID of the tile in it,
+// or nil if the GID is empty or not in any tileset.
+func (m *Map) Tileset(gid GID) (*Tileset, int) {
+	id := gid.ID()
+	if id == 0 {
+		return nil, 0
+	}
+
+	var found *Tileset
+	for _, ts := range m.Tilesets {
+		if ts.FirstGID <= id && (found == nil || ts.FirstGID > found.FirstGID) {
+			found = ts
+		}
+	}
+	if found == nil {
+		return nil, 0
+	}
+
+	local := int(id - found.FirstGID)
+	if found.TileCount > 0 && local >= found.TileCount {
+		return nil, 0
+	}
+	return found, local
+}
+
+// Layer returns the first layer with the given name or nil.
+func (m *Map) Layer(name string) *Layer {
+	for _, l := range m.Layers {
+		if l.Name == name {
+			return l
+		}
+	}
+	return nil
+}
+
+// At returns the GID of the tile in a tile layer at the given cell, or zero if
+// the cell is outside of the map.
+func (m *Map) At(l *Layer, x, y int) GID {
+	if x < 0 || y < 0 || x >= m.Width || y >= m.Height || l.Kind != TileLayer {
+		return 0
+	}
+	return l.Tiles[y*m.Width+x]
+}
+
+// Set sets the GID of the tile in a tile layer at the given cell. Cells
+// outside of the map are ignored.
+func (m *Map) Set(l *Layer, x, y int, gid GID) {
+	if x < 0 || y < 0 || x >= m.Width || y >= m.Height || l.Kind != TileLayer {
+		return
+	}
+	l.Tiles[y*m.Width+x] = gid
+}
+
+// VisibleCells returns the range of cells [x0, x1) x [y0, y1) intersecting
+// view, which is a rect in pixels of the map. Tiles of tilesets bigger than
+// the map's tiles stick out of their cells up and to the right, just like
+// in Tiled, so the range is extended for them.
+func (m *Map) VisibleCells(view g.RectF) (x0, y0, x1, y1 int) {
+	if m.TileWidth <= 0 || m.TileHeight <= 0 {
+		return 0, 0, 0, 0
+	}
+
+	// How far tiles may stick out of their cells
+	var extraW, extraH int
+	for _, ts := range m.Tilesets {
+		if d := ts.TileWidth - m.TileWidth; d > extraW {
+			extraW = d
+		}
+		if d := ts.TileHeight - m.TileHeight; d > extraH {
+			extraH = d
+		}
+	}
+
+	tw, th := float32(m.TileWidth), float32(m.TileHeight)
+	x0 = clampInt(floor((view.X-float32(extraW))/tw), 0, m.Width)
+	y0 = clampInt(floor(view.Y/th), 0, m.Height)
+	x1 = clampInt(floor((view.X+view.W)/tw)+1, 0, m.Width)
+	y1 = clampInt(floor((view.Y+view.H+float32(extraH))/th)+1, 0, m.Height)
+	return
+}
+
+func floor(f float32) int {
+	i := int(f)
+	if float32(i) > f {
+		i--
+	}
+	return i
+}
+
+func clampInt(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
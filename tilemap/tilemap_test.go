@@ -0,0 +1,175 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package tilemap
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	g "github.com/Sergobot/Rocky/geometry"
+)
+
+const testTMX = `<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.2" orientation="orthogonal" width="2" height="2" tilewidth="16" tileheight="16" infinite="0">
+ <properties>
+  <property name="music" value="cave.ogg"/>
+ </properties>
+ <tileset firstgid="1" name="terrain" tilewidth="16" tileheight="16" spacing="2" margin="1" tilecount="8" columns="4">
+  <image source="terrain.png" width="72" height="36"/>
+  <tile id="2">
+   <animation>
+    <frame tileid="2" duration="100"/>
+    <frame tileid="3" duration="300"/>
+   </animation>
+  </tile>
+ </tileset>
+ <layer id="1" name="ground" width="2" height="2">
+  <data encoding="csv">
+1,2,
+3,0
+</data>
+ </layer>
+ <group name="top" opacity="0.5" offsetx="4">
+  <layer id="2" name="decor" width="2" height="2" visible="0">
+   <data encoding="base64" compression="zlib">eJxjZGBgYAJiZgaGBhYgDQAC4ACL</data>
+  </layer>
+ </group>
+ <objectgroup id="3" name="objects">
+  <object id="1" name="spawn" type="player" x="8" y="24">
+   <properties>
+    <property name="health" type="int" value="3"/>
+   </properties>
+   <point/>
+  </object>
+  <object id="2" x="10" y="10">
+   <polygon points="0,0 6,-4 8,2"/>
+  </object>
+ </objectgroup>
+</map>`
+
+func TestLoadTMX(t *testing.T) {
+	m, err := LoadTMX(strings.NewReader(testTMX))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m.Width != 2 || m.Height != 2 || m.TileWidth != 16 || m.Properties["music"] != "cave.ogg" {
+		t.Errorf("Map loaded wrong: %+v", m)
+	}
+	if len(m.Layers) != 3 {
+		t.Fatalf("Got %d layers, want 3", len(m.Layers))
+	}
+
+	ground := m.Layer("ground")
+	if ground == nil || ground.Tiles[1] != 2 || ground.Tiles[3] != 0 {
+		t.Errorf("Ground layer loaded wrong: %+v", ground)
+	}
+
+	decor := m.Layers[1]
+	if decor.Name != "decor" || decor.Visible || decor.Opacity != 0.5 || decor.Offset.X != 4 {
+		t.Errorf("Layer in a group loaded wrong: %+v", decor)
+	}
+	gid := decor.Tiles[2]
+	if gid.ID() != 3 || gid.Flips() != FlipHorizontal {
+		t.Errorf("Compressed tile = %d with flips %x, want 3 flipped horizontally", gid.ID(), gid.Flips())
+	}
+
+	objects := m.Layer("objects").Objects
+	if len(objects) != 2 {
+		t.Fatalf("Got %d objects, want 2", len(objects))
+	}
+	spawn := objects[0]
+	if spawn.Shape != Point || spawn.Type != "player" || spawn.Properties.Int("health", 0) != 3 {
+		t.Errorf("Point object loaded wrong: %+v", spawn)
+	}
+	poly := objects[1]
+	if want := (g.RectF{PosF: g.PosF{X: 10, Y: 6}, SizeF: g.SizeF{W: 8, H: 6}}); poly.Shape != Polygon || poly.Bounds != want {
+		t.Errorf("Polygon bounds = %v, want %v", poly.Bounds, want)
+	}
+}
+
+const testJSON = `{
+ "orientation": "orthogonal", "width": 2, "height": 1, "tilewidth": 8, "tileheight": 8,
+ "tilesets": [
+  {"firstgid": 1, "name": "a", "image": "a.png", "imagewidth": 16, "imageheight": 8, "tilewidth": 8, "tileheight": 8},
+  {"firstgid": 3, "name": "b", "image": "b.png", "imagewidth": 8, "imageheight": 8, "tilewidth": 8, "tileheight": 8}
+ ],
+ "layers": [
+  {"type": "tilelayer", "name": "ground", "data": [2, 3], "visible": true, "opacity": 1},
+  {"type": "objectgroup", "name": "triggers", "objects": [
+   {"id": 1, "class": "door", "x": 1, "y": 2, "width": 3, "height": 4, "ellipse": true,
+    "properties": [{"name": "locked", "type": "bool", "value": true}]}
+  ]}
+ ]
+}`
+
+func TestLoadJSON(t *testing.T) {
+	m, err := LoadJSON(strings.NewReader(testJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Columns and tile count are calculated, when they are missing
+	if ts := m.Tilesets[0]; ts.Columns != 2 || ts.TileCount != 2 {
+		t.Errorf("Tileset has %d columns and %d tiles, want 2 and 2", ts.Columns, ts.TileCount)
+	}
+
+	ground := m.Layer("ground")
+	ts, id := m.Tileset(ground.Tiles[1])
+	if ts == nil || ts.Name != "b" || id != 0 {
+		t.Errorf("Tileset(3) = %v, %d, want b, 0", ts, id)
+	}
+
+	door := m.Layer("triggers").Objects[0]
+	if door.Shape != Ellipse || door.Type != "door" || !door.Properties.Bool("locked", false) {
+		t.Errorf("Object loaded wrong: %+v", door)
+	}
+}
+
+func TestTileset(t *testing.T) {
+	m, err := LoadTMX(strings.NewReader(testTMX))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := m.Tilesets[0]
+
+	want := g.Rect{Pos: g.Pos{X: 19, Y: 19}, Size: g.Size{W: 16, H: 16}}
+	if got := ts.TileRect(5); got != want {
+		t.Errorf("TileRect(5) = %v, want %v", got, want)
+	}
+
+	frames := []struct {
+		t    time.Duration
+		want int
+	}{
+		{50 * time.Millisecond, 2},
+		{150 * time.Millisecond, 3},
+		{450 * time.Millisecond, 2},
+	}
+	for _, f := range frames {
+		if got := ts.Frame(2, f.t); got != f.want {
+			t.Errorf("Frame(2, %v) = %d, want %d", f.t, got, f.want)
+		}
+	}
+	if got := ts.Frame(1, time.Second); got != 1 {
+		t.Errorf("Frame of a still tile = %d, want 1", got)
+	}
+}
+
+func TestVisibleCells(t *testing.T) {
+	m := &Map{Width: 10, Height: 10, TileWidth: 16, TileHeight: 16}
+
+	view := g.RectF{PosF: g.PosF{X: 20, Y: -5}, SizeF: g.SizeF{W: 40, H: 20}}
+	x0, y0, x1, y1 := m.VisibleCells(view)
+	if x0 != 1 || y0 != 0 || x1 != 4 || y1 != 1 {
+		t.Errorf("VisibleCells() = %d, %d, %d, %d, want 1, 0, 4, 1", x0, y0, x1, y1)
+	}
+
+	// Big tiles stick out of their cells, so more cells are visible
+	m.Tilesets = []*Tileset{{TileWidth: 32, TileHeight: 32}}
+	x0, y0, x1, y1 = m.VisibleCells(view)
+	if x0 != 0 || y0 != 0 || x1 != 4 || y1 != 2 {
+		t.Errorf("VisibleCells() with big tiles = %d, %d, %d, %d, want 0, 0, 4, 2", x0, y0, x1, y1)
+	}
+}
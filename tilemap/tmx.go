@@ -0,0 +1,339 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package tilemap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type tmxMap struct {
+	Orientation string `xml:"orientation,attr"`
+	Width       int    `xml:"width,attr"`
+	Height      int    `xml:"height,attr"`
+	TileWidth   int    `xml:"tilewidth,attr"`
+	TileHeight  int    `xml:"tileheight,attr"`
+	Infinite    int    `xml:"infinite,attr"`
+
+	Properties tmxProperties `xml:"properties"`
+	Tilesets   []tmxTileset  `xml:"tileset"`
+
+	// Layers of all kinds, in order
+	Layers []tmxLayer `xml:",any"`
+}
+
+type tmxProperties struct {
+	Properties []struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:"value,attr"`
+		// Multiline strings are stored as text
+		Text string `xml:",chardata"`
+	} `xml:"property"`
+}
+
+type tmxTileset struct {
+	FirstGID   GID    `xml:"firstgid,attr"`
+	Source     string `xml:"source,attr"`
+	Name       string `xml:"name,attr"`
+	TileWidth  int    `xml:"tilewidth,attr"`
+	TileHeight int    `xml:"tileheight,attr"`
+	Spacing    int    `xml:"spacing,attr"`
+	Margin     int    `xml:"margin,attr"`
+	TileCount  int    `xml:"tilecount,attr"`
+	Columns    int    `xml:"columns,attr"`
+
+	Image struct {
+		Source string `xml:"source,attr"`
+		Width  int    `xml:"width,attr"`
+		Height int    `xml:"height,attr"`
+	} `xml:"image"`
+
+	Tiles []struct {
+		ID int `xml:"id,attr"`
+		// Type was renamed to class in Tiled 1.9
+		Type      string        `xml:"type,attr"`
+		Class     string        `xml:"class,attr"`
+		Animation []tmxFrame    `xml:"animation>frame"`
+		Props     tmxProperties `xml:"properties"`
+	} `xml:"tile"`
+
+	Properties tmxProperties `xml:"properties"`
+}
+
+type tmxFrame struct {
+	TileID   int `xml:"tileid,attr"`
+	Duration int `xml:"duration,attr"`
+}
+
+// tmxLayer is any of layer, objectgroup, group and imagelayer elements.
+type tmxLayer struct {
+	XMLName xml.Name
+
+	Name    string   `xml:"name,attr"`
+	Visible *int     `xml:"visible,attr"`
+	Opacity *float32 `xml:"opacity,attr"`
+	OffsetX float32  `xml:"offsetx,attr"`
+	OffsetY float32  `xml:"offsety,attr"`
+
+	Properties tmxProperties `xml:"properties"`
+
+	// Tile layers
+	Data *struct {
+		Encoding    string `xml:"encoding,attr"`
+		Compression string `xml:"compression,attr"`
+		Text        string `xml:",chardata"`
+		Tiles       []struct {
+			GID GID `xml:"gid,attr"`
+		} `xml:"tile"`
+		Chunks []struct{} `xml:"chunk"`
+	} `xml:"data"`
+
+	// Object layers
+	Objects []tmxObject `xml:"object"`
+
+	// Group layers
+	Layers []tmxLayer `xml:",any"`
+}
+
+type tmxObject struct {
+	ID       int       `xml:"id,attr"`
+	Name     string    `xml:"name,attr"`
+	Type     string    `xml:"type,attr"`
+	Class    string    `xml:"class,attr"`
+	X        float32   `xml:"x,attr"`
+	Y        float32   `xml:"y,attr"`
+	Width    float32   `xml:"width,attr"`
+	Height   float32   `xml:"height,attr"`
+	Rotation float32   `xml:"rotation,attr"`
+	GID      GID       `xml:"gid,attr"`
+	Visible  *int      `xml:"visible,attr"`
+	Ellipse  *struct{} `xml:"ellipse"`
+	Point    *struct{} `xml:"point"`
+	Polygon  *struct {
+		Points string `xml:"points,attr"`
+	} `xml:"polygon"`
+	Polyline *struct {
+		Points string `xml:"points,attr"`
+	} `xml:"polyline"`
+
+	Properties tmxProperties `xml:"properties"`
+}
+
+func loadTMX(r io.Reader, dir string) (*Map, error) {
+	var tm tmxMap
+	if err := xml.NewDecoder(r).Decode(&tm); err != nil {
+		return nil, fmt.Errorf("Failed to load map: %v", err)
+	}
+
+	m := &Map{
+		Width:      tm.Width,
+		Height:     tm.Height,
+		TileWidth:  tm.TileWidth,
+		TileHeight: tm.TileHeight,
+		Properties: tm.Properties.convert(),
+	}
+	if err := m.check(tm.Orientation, tm.Infinite != 0); err != nil {
+		return nil, err
+	}
+
+	for i := range tm.Tilesets {
+		t := &tm.Tilesets[i]
+		var (
+			ts  *Tileset
+			err error
+		)
+		if t.Source != "" {
+			ts, err = loadTileset(t.Source, dir)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			ts = t.convert(dir)
+		}
+		ts.FirstGID = t.FirstGID
+		m.Tilesets = append(m.Tilesets, ts)
+	}
+
+	if err := m.addTMXLayers(tm.Layers, layerState{visible: true, opacity: 1}); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func loadTSX(r io.Reader, dir string) (*Tileset, error) {
+	var t tmxTileset
+	if err := xml.NewDecoder(r).Decode(&t); err != nil {
+		return nil, fmt.Errorf("Failed to load tileset: %v", err)
+	}
+	return t.convert(dir), nil
+}
+
+func (t *tmxTileset) convert(dir string) *Tileset {
+	ts := &Tileset{
+		Name:        t.Name,
+		Image:       resolve(t.Image.Source, dir),
+		ImageWidth:  t.Image.Width,
+		ImageHeight: t.Image.Height,
+		TileWidth:   t.TileWidth,
+		TileHeight:  t.TileHeight,
+		Spacing:     t.Spacing,
+		Margin:      t.Margin,
+		TileCount:   t.TileCount,
+		Columns:     t.Columns,
+		Tiles:       map[int]*Tile{},
+		Properties:  t.Properties.convert(),
+	}
+
+	for _, tt := range t.Tiles {
+		tile := &Tile{Type: tt.Type, Properties: tt.Props.convert()}
+		if tt.Class != "" {
+			tile.Type = tt.Class
+		}
+		for _, f := range tt.Animation {
+			tile.Animation = append(tile.Animation, Frame{
+				TileID:   f.TileID,
+				Duration: time.Duration(f.Duration) * time.Millisecond,
+			})
+		}
+		ts.Tiles[tt.ID] = tile
+	}
+
+	ts.fix()
+	return ts
+}
+
+func (tp tmxProperties) convert() Properties {
+	p := Properties{}
+	for _, prop := range tp.Properties {
+		v := prop.Value
+		if v == "" {
+			v = strings.TrimSpace(prop.Text)
+		}
+		p[prop.Name] = v
+	}
+	return p
+}
+
+// layerState is inherited by layers from the groups they are in.
+type layerState struct {
+	visible bool
+	opacity float32
+	offset  [2]float32
+}
+
+func (tl *tmxLayer) state(parent layerState) layerState {
+	s := parent
+	if tl.Visible != nil && *tl.Visible == 0 {
+		s.visible = false
+	}
+	if tl.Opacity != nil {
+		s.opacity *= *tl.Opacity
+	}
+	s.offset[0] += tl.OffsetX
+	s.offset[1] += tl.OffsetY
+	return s
+}
+
+func (s layerState) layer(kind LayerKind, name string, props Properties) *Layer {
+	l := &Layer{
+		Kind:       kind,
+		Name:       name,
+		Visible:    s.visible,
+		Opacity:    s.opacity,
+		Properties: props,
+	}
+	l.Offset.X, l.Offset.Y = s.offset[0], s.offset[1]
+	return l
+}
+
+// addTMXLayers converts layers and adds them to the map. Groups are flattened.
+func (m *Map) addTMXLayers(layers []tmxLayer, parent layerState) error {
+	for i := range layers {
+		tl := &layers[i]
+		s := tl.state(parent)
+
+		switch tl.XMLName.Local {
+		case "layer":
+			l := s.layer(TileLayer, tl.Name, tl.Properties.convert())
+			if tl.Data == nil {
+				return fmt.Errorf("Failed to load layer %q: no data", tl.Name)
+			}
+			if len(tl.Data.Chunks) > 0 {
+				return fmt.Errorf("Failed to load layer %q: infinite maps are not supported", tl.Name)
+			}
+
+			if tl.Data.Encoding == "" {
+				for _, t := range tl.Data.Tiles {
+					l.Tiles = append(l.Tiles, t.GID)
+				}
+				if len(l.Tiles) != m.Width*m.Height {
+					return fmt.Errorf("Failed to load layer %q: got %d tiles, want %d",
+						tl.Name, len(l.Tiles), m.Width*m.Height)
+				}
+			} else {
+				var err error
+				l.Tiles, err = decodeTiles(tl.Data.Text, tl.Data.Encoding, tl.Data.Compression, m.Width*m.Height)
+				if err != nil {
+					return fmt.Errorf("Failed to load layer %q: %v", tl.Name, err)
+				}
+			}
+			m.Layers = append(m.Layers, l)
+
+		case "objectgroup":
+			l := s.layer(ObjectLayer, tl.Name, tl.Properties.convert())
+			for j := range tl.Objects {
+				o, err := tl.Objects[j].convert()
+				if err != nil {
+					return fmt.Errorf("Failed to load layer %q: %v", tl.Name, err)
+				}
+				l.Objects = append(l.Objects, o)
+			}
+			m.Layers = append(m.Layers, l)
+
+		case "group":
+			if err := m.addTMXLayers(tl.Layers, s); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (to *tmxObject) convert() (*Object, error) {
+	o := &Object{
+		ID:         to.ID,
+		Name:       to.Name,
+		Type:       to.Type,
+		Rotation:   to.Rotation,
+		GID:        to.GID,
+		Visible:    to.Visible == nil || *to.Visible != 0,
+		Properties: to.Properties.convert(),
+	}
+	if to.Class != "" {
+		o.Type = to.Class
+	}
+
+	var points string
+	switch {
+	case to.GID != 0:
+		o.Shape = TileShape
+	case to.Ellipse != nil:
+		o.Shape = Ellipse
+	case to.Point != nil:
+		o.Shape = Point
+	case to.Polygon != nil:
+		o.Shape, points = Polygon, to.Polygon.Points
+	case to.Polyline != nil:
+		o.Shape, points = Polyline, to.Polyline.Points
+	}
+
+	pts, err := parsePoints(points)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load object %d: %v", to.ID, err)
+	}
+	o.place(to.X, to.Y, to.Width, to.Height, pts)
+	return o, nil
+}
@@ -0,0 +1,232 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package basic
+
+import (
+	"github.com/Sergobot/Rocky/events"
+	g "github.com/Sergobot/Rocky/geometry"
+)
+
+// DragThreshold is the distance cursor has to travel with a button held down
+// before a widget should start a drag. Otherwise every click would be a drag.
+const DragThreshold float32 = 0.01
+
+// Drag describes a drag and drop operation. Fill it and pass to Start of the
+// DragManager of the window. There may be only one drag per window at a time,
+// since there is only one mouse.
+//
+// Widgets under the cursor get DragEnter, DragMove, DragLeave and Drop events
+// with Payload in them. A widget accepting DragEnter becomes the drop target,
+// and the drop happens only if the target accepts the last DragMove too.
+type Drag struct {
+	// Payload is the dragged data. It's passed to widgets in events' Payload.
+	Payload interface{}
+
+	// Source is the widget the drag was started from. It's optional.
+	Source Node
+
+	// Visual is drawn under the cursor during the drag, for example a
+	// semi-transparent copy of a Pixmap. It's optional, mustn't be a part of
+	// any widget tree and is destroyed when the drag ends.
+	Visual Node
+
+	// Hotspot is cursor position relative to Visual's top-left corner.
+	Hotspot g.PointF
+
+	// Finished is called when the drag ends. dropped is true if some widget
+	// accepted the drop.
+	Finished func(dropped bool)
+
+	pos     g.PointF
+	hovered Node
+	target  Node
+	accepts bool
+
+	// Manager the drag was started with
+	manager *DragManager
+}
+
+// DragManager keeps track of the drag in progress in a widget tree. There is
+// one per window, so a drag started in one window doesn't get events of the
+// others.
+type DragManager struct {
+	root    Node
+	current *Drag
+}
+
+// SetRoot sets the widget tree drops go to. The drag in progress, if any, is
+// cancelled.
+func (m *DragManager) SetRoot(root Node) {
+	m.Cancel()
+	m.root = root
+}
+
+// Root returns the widget tree drops go to.
+func (m *DragManager) Root() Node {
+	return m.root
+}
+
+// Start starts a drag from the given point in window coordinates, which is
+// usually the cursor position. If there already is a drag, it's cancelled.
+// The widget under the point gets DragEnter right away, so the drag may be
+// dropped without moving the mouse.
+func (m *DragManager) Start(d *Drag, pos g.PointF) {
+	if m.current != nil {
+		m.Cancel()
+	}
+
+	m.current = d
+	d.manager = m
+	if d.Visual != nil {
+		d.Visual.GetReady()
+	}
+	d.moveTo(pos)
+	if m.root != nil {
+		d.hover(m.root)
+	}
+}
+
+// Current returns the drag in progress or nil.
+func (m *DragManager) Current() *Drag {
+	return m.current
+}
+
+// Cancel cancels the drag in progress, if there is one. The drop target, if
+// any, gets DragLeave.
+func (m *DragManager) Cancel() {
+	if m.current != nil {
+		m.current.finish(false)
+	}
+}
+
+// Pos returns current cursor position in window coordinates.
+func (d *Drag) Pos() g.PointF {
+	return d.pos
+}
+
+// Target returns the widget, which accepted DragEnter, or nil.
+func (d *Drag) Target() Node {
+	return d.target
+}
+
+// Accepted returns true if the drop is possible at the current position.
+func (d *Drag) Accepted() bool {
+	return d.target != nil && d.accepts
+}
+
+// Handle processes an event, if there is a drag in progress. Windows call it
+// before delivering events to their widgets, and if it returns true, the event
+// is used by the drag and mustn't be delivered.
+// While dragging, mouse moves and releases control the drag, Escape key
+// cancels it and other mouse events are swallowed.
+func (m *DragManager) Handle(e *events.Event) bool {
+	d := m.current
+	if d == nil || m.root == nil {
+		return false
+	}
+
+	switch e.Type {
+	case events.MouseMove:
+		d.moveTo(e.Pos)
+		d.hover(m.root)
+	case events.MouseRelease:
+		if e.Pos != d.pos {
+			d.moveTo(e.Pos)
+			d.hover(m.root)
+		}
+		d.drop()
+	case events.MousePress, events.Scroll:
+		// Swallowed
+	case events.KeyPress:
+		if e.Key != events.KeyEscape {
+			return false
+		}
+		m.Cancel()
+	default:
+		return false
+	}
+
+	e.Accept()
+	return true
+}
+
+// moveTo moves the drag and its visual to a new position.
+func (d *Drag) moveTo(pos g.PointF) {
+	d.pos = pos
+	if d.Visual != nil {
+		r := d.Visual.Geometry()
+		r.X = pos.X - d.Hotspot.X
+		r.Y = pos.Y - d.Hotspot.Y
+		d.Visual.SetGeometry(r)
+	}
+}
+
+// hover finds the drop target under the cursor and sends it DragMove.
+func (d *Drag) hover(root Node) {
+	n := NodeAt(root, d.pos)
+	if n != d.hovered {
+		d.hovered = n
+
+		// Go up from the hovered node until someone accepts DragEnter. The
+		// current target doesn't need to enter again, disabled nodes are
+		// skipped.
+		var target Node
+		for m := n; m != nil; m = m.Parent() {
+			if m == d.target {
+				target = m
+				break
+			}
+			if IsEnabled(m) && d.send(m, events.DragEnter) {
+				target = m
+				break
+			}
+		}
+
+		if target != d.target {
+			if d.target != nil {
+				d.send(d.target, events.DragLeave)
+			}
+			d.target = target
+		}
+	}
+
+	d.accepts = d.target != nil && d.send(d.target, events.DragMove)
+}
+
+// drop finishes the drag, dropping the payload to the target if it accepts.
+func (d *Drag) drop() {
+	if d.target == nil || !d.accepts {
+		d.finish(false)
+		return
+	}
+
+	dropped := d.send(d.target, events.Drop)
+	// Target has already got the Drop, so it mustn't get DragLeave
+	d.target = nil
+	d.finish(dropped)
+}
+
+// finish ends the drag: the target leaves, the visual is destroyed.
+func (d *Drag) finish(dropped bool) {
+	if d.target != nil {
+		d.send(d.target, events.DragLeave)
+		d.target = nil
+	}
+	if d.Visual != nil {
+		d.Visual.Destroy()
+	}
+	if m := d.manager; m != nil && m.current == d {
+		m.current = nil
+	}
+
+	if d.Finished != nil {
+		d.Finished(dropped)
+	}
+}
+
+// send sends a drag event to a node and returns true, if the node accepted it.
+func (d *Drag) send(n Node, t events.Type) bool {
+	e := &events.Event{Type: t, Pos: d.pos, Payload: d.Payload}
+	n.HandleEvent(e)
+	return e.Accepted()
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package basic
+
+import (
+	"testing"
+
+	"github.com/Sergobot/Rocky/events"
+	g "github.com/Sergobot/Rocky/geometry"
+)
+
+func TestDragAndDrop(t *testing.T) {
+	root, a, _ := testTree()
+
+	// a accepts strings only, b accepts nothing
+	var got []events.Type
+	a.SetEventHandler(func(e *events.Event) {
+		if _, ok := e.Payload.(string); ok {
+			got = append(got, e.Type)
+			e.Accept()
+		}
+	})
+
+	var m, other DragManager
+	m.SetRoot(root)
+	other.SetRoot(root)
+	var dropped, finished bool
+	m.Start(&Drag{
+		Payload:  "item",
+		Finished: func(d bool) { dropped, finished = d, true },
+	}, g.PointF{X: 0.1, Y: 0.1})
+
+	move := func(x, y float32, t events.Type) {
+		m.Handle(&events.Event{Type: t, Pos: g.PointF{X: x, Y: y}})
+	}
+
+	// Drags of one window don't get events of others
+	if other.Current() != nil || other.Handle(&events.Event{Type: events.MouseMove}) {
+		t.Fatalf("Drag is seen by another manager")
+	}
+
+	move(1, 1, events.MouseMove) // Over b, which doesn't accept, so a does
+	if m.Current().Target() != a || !m.Current().Accepted() {
+		t.Fatalf("Drop target is %v, want a", m.Current().Target())
+	}
+	move(0.1, 0.1, events.MouseMove) // Over root, a leaves
+	move(0.6, 0.6, events.MouseMove) // Over a again
+	move(0.6, 0.6, events.MouseRelease)
+
+	if !finished || !dropped {
+		t.Errorf("Drag finished: %v, dropped: %v, want both true", finished, dropped)
+	}
+	if m.Current() != nil {
+		t.Errorf("Drag is still in progress after drop")
+	}
+
+	want := []events.Type{
+		events.DragEnter, events.DragMove, events.DragLeave,
+		events.DragEnter, events.DragMove, events.Drop,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("a got %d drag events, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Drag event #%d is %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestDragCancel(t *testing.T) {
+	root, _, _ := testTree()
+
+	var m DragManager
+	m.SetRoot(root)
+	var finished, dropped bool
+	m.Start(&Drag{Finished: func(d bool) { dropped, finished = d, true }}, g.PointF{})
+
+	e := &events.Event{Type: events.KeyPress, Key: events.KeyEscape}
+	if !m.Handle(e) {
+		t.Errorf("Escape wasn't used by the drag")
+	}
+	if !finished || dropped || m.Current() != nil {
+		t.Errorf("Drag wasn't cancelled by Escape")
+	}
+}
+
+// A drag may be dropped right where it started, without moving the mouse.
+func TestDragWithoutMove(t *testing.T) {
+	root, a, _ := testTree()
+	a.SetEventHandler(func(e *events.Event) { e.Accept() })
+
+	var m DragManager
+	m.SetRoot(root)
+	var dropped bool
+	m.Start(&Drag{Finished: func(d bool) { dropped = d }}, g.PointF{X: 0.6, Y: 0.6})
+	m.Handle(&events.Event{Type: events.MouseRelease, Pos: g.PointF{X: 0.6, Y: 0.6}})
+
+	if !dropped {
+		t.Errorf("Drag released over an accepting widget wasn't dropped")
+	}
+}
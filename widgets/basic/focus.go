@@ -0,0 +1,255 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package basic
+
+import (
+	"math"
+
+	"github.com/Sergobot/Rocky/events"
+	g "github.com/Sergobot/Rocky/geometry"
+)
+
+// Direction variables tell where to move focus during spatial navigation.
+type Direction int
+
+// Directions of spatial navigation, usually mapped to arrow keys or D-pad.
+const (
+	Up    Direction = iota
+	Down  Direction = iota
+	Left  Direction = iota
+	Right Direction = iota
+)
+
+// FocusManager keeps track of the only focused widget of a widget tree. There
+// is one per window. Focus can be moved in tab order, which is just the order
+// of widgets in the tree, or spatially to the nearest widget in some direction.
+// Only focusable widgets (see Widget.SetFocusable) are taken into account.
+type FocusManager struct {
+	root    Node
+	focused Node
+}
+
+// SetRoot sets the widget tree managed. Focus is cleared.
+func (f *FocusManager) SetRoot(root Node) {
+	f.SetFocus(nil)
+	f.root = root
+}
+
+// Root returns the widget tree managed.
+func (f *FocusManager) Root() Node {
+	return f.root
+}
+
+// Focused returns the focused widget or nil, if there is none. If the focused
+// widget was removed from the tree, hidden or disabled, focus is cleared.
+func (f *FocusManager) Focused() Node {
+	if f.focused != nil && !(InTree(f.root, f.focused) && canFocus(f.focused)) {
+		f.SetFocus(nil)
+	}
+	return f.focused
+}
+
+// SetFocus moves focus to n. The widget losing focus gets FocusOut event and
+// the one gaining it gets FocusIn. Pass nil to clear focus. Widgets which are
+// not focusable, hidden or disabled are ignored.
+func (f *FocusManager) SetFocus(n Node) {
+	if n != nil && !canFocus(n) {
+		return
+	}
+	if f.focused == n {
+		return
+	}
+
+	if old := f.focused; old != nil {
+		old.base().focused = false
+		old.HandleEvent(&events.Event{Type: events.FocusOut})
+	}
+
+	f.focused = n
+	if n != nil {
+		n.base().focused = true
+		n.HandleEvent(&events.Event{Type: events.FocusIn})
+	}
+}
+
+// FocusAt focuses the deepest focusable widget under a point in window
+// coordinates. If there is none, focus stays where it was.
+func (f *FocusManager) FocusAt(p g.PointF) {
+	if f.root == nil {
+		return
+	}
+	for n := NodeAt(f.root, p); n != nil; n = n.Parent() {
+		if n.base().focusable && IsEnabled(n) {
+			f.SetFocus(n)
+			return
+		}
+	}
+}
+
+// Focusable returns all the focusable widgets of the tree in tab order.
+// Hidden and disabled widgets are skipped with all their children.
+func (f *FocusManager) Focusable() []Node {
+	var res []Node
+	if f.root == nil {
+		return res
+	}
+
+	Walk(f.root, func(n Node) bool {
+		w := n.base()
+		if w.hidden || w.disabled {
+			return false
+		}
+		if w.focusable {
+			res = append(res, n)
+		}
+		return true
+	})
+	return res
+}
+
+// canFocus returns true if n is focusable, visible and enabled.
+func canFocus(n Node) bool {
+	return n.base().focusable && IsVisible(n) && IsEnabled(n)
+}
+
+// Next moves focus to the next widget in tab order, wrapping around.
+func (f *FocusManager) Next() {
+	f.step(1)
+}
+
+// Previous moves focus to the previous widget in tab order, wrapping around.
+func (f *FocusManager) Previous() {
+	f.step(-1)
+}
+
+func (f *FocusManager) step(delta int) {
+	nodes := f.Focusable()
+	if len(nodes) == 0 {
+		return
+	}
+
+	cur := f.Focused()
+	i := -1
+	for j, n := range nodes {
+		if n == cur {
+			i = j
+			break
+		}
+	}
+
+	if i < 0 {
+		// Nothing is focused: Next focuses the first widget, Previous - the last
+		if delta > 0 {
+			f.SetFocus(nodes[0])
+		} else {
+			f.SetFocus(nodes[len(nodes)-1])
+		}
+		return
+	}
+
+	f.SetFocus(nodes[(i+delta+len(nodes))%len(nodes)])
+}
+
+// Move moves focus to the nearest focusable widget in the given direction.
+// It returns false if there is no such widget. If nothing is focused, the first
+// widget in tab order is focused.
+func (f *FocusManager) Move(d Direction) bool {
+	cur := f.Focused()
+	if cur == nil {
+		f.Next()
+		return f.focused != nil
+	}
+
+	var (
+		nodes []Node
+		rects []g.RectF
+	)
+	for _, n := range f.Focusable() {
+		if n != cur {
+			nodes = append(nodes, n)
+			rects = append(rects, n.base().GlobalGeometry())
+		}
+	}
+
+	i := Nearest(cur.base().GlobalGeometry(), d, rects)
+	if i < 0 {
+		return false
+	}
+	f.SetFocus(nodes[i])
+	return true
+}
+
+// HandleKey implements default keyboard navigation: Tab and Shift+Tab move
+// focus in tab order, arrow keys move it spatially. If the key is used, the
+// event is accepted. Window calls it for key events nobody has accepted.
+func (f *FocusManager) HandleKey(e *events.Event) {
+	if e.Type != events.KeyPress && e.Type != events.KeyRepeat {
+		return
+	}
+
+	switch e.Key {
+	case events.KeyTab:
+		if e.Mods&events.ModShift != 0 {
+			f.Previous()
+		} else {
+			f.Next()
+		}
+		e.Accept()
+	case events.KeyUp:
+		f.moveAccept(Up, e)
+	case events.KeyDown:
+		f.moveAccept(Down, e)
+	case events.KeyLeft:
+		f.moveAccept(Left, e)
+	case events.KeyRight:
+		f.moveAccept(Right, e)
+	}
+}
+
+func (f *FocusManager) moveAccept(d Direction, e *events.Event) {
+	if f.Move(d) {
+		e.Accept()
+	}
+}
+
+// Nearest returns index of a rect in candidates, which is the nearest to from
+// in the given direction, or -1 if there is none. Only rects with centers lying
+// in that direction are considered. Distance across the direction costs more
+// than distance along it, so widgets in the same row (or column) are preferred.
+func Nearest(from g.RectF, d Direction, candidates []g.RectF) int {
+	cx, cy := center(from)
+
+	best, bestScore := -1, float32(math.MaxFloat32)
+	for i, r := range candidates {
+		x, y := center(r)
+		dx, dy := x-cx, y-cy
+
+		var along, across float32
+		switch d {
+		case Up:
+			along, across = -dy, dx
+		case Down:
+			along, across = dy, dx
+		case Left:
+			along, across = -dx, dy
+		case Right:
+			along, across = dx, dy
+		}
+		if along <= 0 {
+			continue
+		}
+		if across < 0 {
+			across = -across
+		}
+
+		if score := along + 2*across; score < bestScore {
+			best, bestScore = i, score
+		}
+	}
+
+	return best
+}
+
+func center(r g.RectF) (float32, float32) {
+	return r.X + r.W/2, r.Y + r.H/2
+}
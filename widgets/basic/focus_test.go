@@ -0,0 +1,89 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package basic
+
+import (
+	"testing"
+
+	"github.com/Sergobot/Rocky/events"
+	g "github.com/Sergobot/Rocky/geometry"
+)
+
+func TestNearest(t *testing.T) {
+	// A 3x3 grid of rects without the center one:
+	//	0 1 2
+	//	3 . 4
+	//	5 6 7
+	var rects []g.RectF
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 3; x++ {
+			if x != 1 || y != 1 {
+				rects = append(rects, rect(float32(x), float32(y), 0.5, 0.5))
+			}
+		}
+	}
+	from := rect(1, 1, 0.5, 0.5)
+
+	cases := []struct {
+		d    Direction
+		want int
+	}{
+		{Up, 1},
+		{Down, 6},
+		{Left, 3},
+		{Right, 4},
+	}
+	for _, c := range cases {
+		if got := Nearest(from, c.d, rects); got != c.want {
+			t.Errorf("Nearest(%v) = %v, want %v", c.d, got, c.want)
+		}
+	}
+
+	if got := Nearest(rects[0], Up, rects[1:]); got != -1 {
+		t.Errorf("Nearest() = %v for nothing above, want -1", got)
+	}
+}
+
+func TestFocusOrder(t *testing.T) {
+	root, a, b := testTree()
+	a.SetFocusable(true)
+	b.SetFocusable(true)
+
+	var f FocusManager
+	f.SetRoot(root)
+
+	var got []events.Type
+	a.SetEventHandler(func(e *events.Event) { got = append(got, e.Type) })
+
+	f.Next()
+	if f.Focused() != a || !a.HasFocus() {
+		t.Fatalf("Next() focused %v, want a", f.Focused())
+	}
+	f.Next()
+	if f.Focused() != b || a.HasFocus() {
+		t.Fatalf("Next() focused %v, want b", f.Focused())
+	}
+	f.Next()
+	if f.Focused() != a {
+		t.Fatalf("Next() didn't wrap around")
+	}
+	f.Previous()
+	if f.Focused() != b {
+		t.Fatalf("Previous() focused %v, want b", f.Focused())
+	}
+
+	want := []events.Type{events.FocusIn, events.FocusOut, events.FocusIn, events.FocusOut}
+	if len(got) != len(want) {
+		t.Fatalf("a got %d focus events, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Focus event #%d is %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	b.Destroy()
+	if f.Focused() != nil {
+		t.Errorf("Destroyed widget still has focus")
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package basic
+
+import (
+	"sort"
+
+	"github.com/Sergobot/Rocky/events"
+	g "github.com/Sergobot/Rocky/geometry"
+)
+
+// Walk calls fn for root and all its descendants, parents before children.
+// If fn returns false, children of that node are skipped.
+func Walk(root Node, fn func(Node) bool) {
+	if !fn(root) {
+		return
+	}
+	for _, c := range root.Children() {
+		Walk(c, fn)
+	}
+}
+
+// DrawOrder returns children of a node in the order they are drawn: sorted by
+// z-index, siblings with the same z in the order they were attached.
+func DrawOrder(n Node) []Node {
+	children := n.Children()
+
+	sorted := true
+	for i := 1; i < len(children); i++ {
+		if children[i].base().z < children[i-1].base().z {
+			sorted = false
+			break
+		}
+	}
+	if sorted {
+		return children
+	}
+
+	res := append([]Node(nil), children...)
+	sort.Stable(byZ(res))
+	return res
+}
+
+type byZ []Node
+
+func (b byZ) Len() int           { return len(b) }
+func (b byZ) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byZ) Less(i, j int) bool { return b[i].base().z < b[j].base().z }
+
+// drawTransform is the transform of the node being drawn right now
+var drawTransform = g.Identity()
+
+// DrawTransform returns the transform shapes being drawn right now should be
+// transformed with. It's set by DrawTree to the global transform of each node
+// before drawing it, so whatever a widget draws in its Draw is rotated along
+// with the widget.
+func DrawTransform() g.Transform {
+	return drawTransform
+}
+
+// WithTransform calls draw with DrawTransform set to t. It's useful to draw
+// something outside of DrawTree, like a focus ring, rotated with a widget.
+func WithTransform(t g.Transform, draw func()) {
+	saved := drawTransform
+	drawTransform = t
+	draw()
+	drawTransform = saved
+}
+
+// DrawTree draws root and all its visible descendants, parents before
+// children, so children are drawn on top of their parents. Siblings are drawn
+// in DrawOrder. Nodes not attached anywhere, which are drawn by another
+// widget, get the transform of that widget.
+func DrawTree(root Node) {
+	saved := drawTransform
+	t := saved
+	if p := root.Parent(); p != nil {
+		t = GlobalTransform(p)
+	}
+	drawSubtree(root, t)
+	drawTransform = saved
+}
+
+func drawSubtree(n Node, parent g.Transform) {
+	w := n.base()
+	if w.hidden {
+		return
+	}
+
+	drawTransform = parent.Mul(w.localTransform())
+	n.Draw()
+
+	t := drawTransform
+	for _, c := range DrawOrder(n) {
+		drawSubtree(c, t)
+	}
+}
+
+// GlobalGeometry returns geometry of a node in window coordinates, without
+// rotation.
+func GlobalGeometry(n Node) g.RectF {
+	return n.base().GlobalGeometry()
+}
+
+// GlobalTransform returns the rotation of a node and its ancestors, see
+// Widget.GlobalTransform.
+func GlobalTransform(n Node) g.Transform {
+	return n.base().GlobalTransform()
+}
+
+// Contains returns true if a point in window coordinates is inside the node.
+// The node's rotation is taken into account.
+func Contains(n Node, p g.PointF) bool {
+	w := n.base()
+	p = w.MapFromGlobal(p)
+	s := w.Size()
+	return p.X >= 0 && p.X < s.W && p.Y >= 0 && p.Y < s.H
+}
+
+// IsVisible returns true if neither the node nor any of its ancestors is
+// hidden.
+func IsVisible(n Node) bool {
+	for ; n != nil; n = n.Parent() {
+		if n.base().hidden {
+			return false
+		}
+	}
+	return true
+}
+
+// IsEnabled returns true if neither the node nor any of its ancestors is
+// disabled.
+func IsEnabled(n Node) bool {
+	for ; n != nil; n = n.Parent() {
+		if n.base().disabled {
+			return false
+		}
+	}
+	return true
+}
+
+// NodeAt returns the deepest node under a point in window coordinates, or nil
+// if the point is outside of root. Children drawn on top are checked first.
+// Hidden and mouse transparent nodes are skipped with all their children.
+func NodeAt(root Node, p g.PointF) Node {
+	w := root.base()
+	if w.hidden || w.mouseTransparent || !Contains(root, p) {
+		return nil
+	}
+
+	children := DrawOrder(root)
+	for i := len(children) - 1; i >= 0; i-- {
+		if n := NodeAt(children[i], p); n != nil {
+			return n
+		}
+	}
+
+	return root
+}
+
+// Propagate delivers an event to target and then to its ancestors, until some
+// node accepts it. It returns the node, which accepted the event, or nil.
+// Disabled nodes don't get mouse and key events, their ancestors get them
+// instead.
+func Propagate(target Node, e *events.Event) Node {
+	input := e.IsMouse() || e.IsKey()
+	for n := target; n != nil; n = n.Parent() {
+		if input && !IsEnabled(n) {
+			continue
+		}
+		n.HandleEvent(e)
+		if e.Accepted() {
+			return n
+		}
+	}
+	return nil
+}
+
+// Dispatch delivers an event to a widget tree. Mouse events go to the deepest
+// node under the cursor, others go to root. In both cases the event is
+// propagated up the tree until it's accepted. It returns the node, which
+// accepted the event, or nil.
+func Dispatch(root Node, e *events.Event) Node {
+	target := root
+	if e.IsMouse() {
+		target = NodeAt(root, e.Pos)
+	}
+	if target == nil {
+		return nil
+	}
+	return Propagate(target, e)
+}
+
+// InTree returns true if n is root or one of its descendants.
+func InTree(root, n Node) bool {
+	if root == nil {
+		return false
+	}
+	for ; n != nil; n = n.Parent() {
+		if n.base() == root.base() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package basic
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Sergobot/Rocky/events"
+	g "github.com/Sergobot/Rocky/geometry"
+)
+
+func rect(x, y, w, h float32) g.RectF {
+	return g.RectF{PosF: g.PosF{X: x, Y: y}, SizeF: g.SizeF{W: w, H: h}}
+}
+
+// Builds a tree like that:
+//
+//	root {0, 0, 2, 2}
+//	|- a {0.5, 0.5, 1, 1}
+//	   |- b {0.25, 0.25, 0.5, 0.5}
+func testTree() (root, a, b *Widget) {
+	root, a, b = new(Widget), new(Widget), new(Widget)
+	root.SetGeometry(rect(0, 0, 2, 2))
+	a.SetGeometry(rect(0.5, 0.5, 1, 1))
+	b.SetGeometry(rect(0.25, 0.25, 0.5, 0.5))
+	Attach(root, a)
+	Attach(a, b)
+	return
+}
+
+func TestGlobalGeometry(t *testing.T) {
+	_, _, b := testTree()
+	if got, want := b.GlobalGeometry(), rect(0.75, 0.75, 0.5, 0.5); got != want {
+		t.Errorf("GlobalGeometry() = %v, want %v", got, want)
+	}
+	if got, want := b.MapFromGlobal(g.PointF{X: 1, Y: 1}), (g.PointF{X: 0.25, Y: 0.25}); got != want {
+		t.Errorf("MapFromGlobal() = %v, want %v", got, want)
+	}
+}
+
+func TestNodeAt(t *testing.T) {
+	root, a, b := testTree()
+	cases := []struct {
+		p    g.PointF
+		want Node
+	}{
+		{g.PointF{X: 0.1, Y: 0.1}, root},
+		{g.PointF{X: 0.6, Y: 0.6}, a},
+		{g.PointF{X: 1, Y: 1}, b},
+		{g.PointF{X: 3, Y: 3}, nil},
+	}
+	for _, c := range cases {
+		if got := NodeAt(root, c.p); got != c.want {
+			t.Errorf("NodeAt(%v) = %v, want %v", c.p, got, c.want)
+		}
+	}
+}
+
+func TestDispatchPropagation(t *testing.T) {
+	root, a, b := testTree()
+
+	var got []string
+	root.SetEventHandler(func(e *events.Event) { got = append(got, "root") })
+	a.SetEventHandler(func(e *events.Event) { got = append(got, "a"); e.Accept() })
+	b.SetEventHandler(func(e *events.Event) { got = append(got, "b") })
+
+	Dispatch(root, &events.Event{Type: events.MousePress, Pos: g.PointF{X: 1, Y: 1}})
+	if len(got) != 2 || got[0] != "b" || got[1] != "a" {
+		t.Errorf("Event delivered to %v, want [b a]", got)
+	}
+}
+
+func TestDestroy(t *testing.T) {
+	root, a, b := testTree()
+	a.Destroy()
+	if len(root.Children()) != 0 {
+		t.Errorf("Destroyed child is still attached to its parent")
+	}
+	if b.Parent() != nil || len(a.Children()) != 0 {
+		t.Errorf("Children of a destroyed widget are still attached")
+	}
+}
+
+func TestVisibleAndEnabled(t *testing.T) {
+	root, a, b := testTree()
+
+	a.SetVisible(false)
+	if got := NodeAt(root, g.PointF{X: 1, Y: 1}); got != root {
+		t.Errorf("NodeAt() over a hidden widget = %v, want root", got)
+	}
+	if IsVisible(b) {
+		t.Errorf("IsVisible() = true for a child of a hidden widget")
+	}
+	a.SetVisible(true)
+
+	var got []string
+	root.SetEventHandler(func(e *events.Event) { got = append(got, "root") })
+	a.SetEventHandler(func(e *events.Event) { got = append(got, "a") })
+	b.SetEventHandler(func(e *events.Event) { got = append(got, "b") })
+
+	a.SetEnabled(false)
+	Dispatch(root, &events.Event{Type: events.MousePress, Pos: g.PointF{X: 1, Y: 1}})
+	if len(got) != 1 || got[0] != "root" {
+		t.Errorf("Event delivered to %v, want [root]", got)
+	}
+}
+
+func TestZOrder(t *testing.T) {
+	root := new(Widget)
+	root.SetGeometry(rect(0, 0, 2, 2))
+	a, b := new(Widget), new(Widget)
+	a.SetGeometry(rect(0, 0, 1, 1))
+	b.SetGeometry(rect(0, 0, 1, 1))
+	Attach(root, a)
+	Attach(root, b)
+
+	if got := NodeAt(root, g.PointF{X: 0.5, Y: 0.5}); got != b {
+		t.Errorf("NodeAt() = %v, want the widget attached last", got)
+	}
+
+	a.SetZ(1)
+	if got := DrawOrder(root); got[0] != b || got[1] != a {
+		t.Errorf("DrawOrder() = %v, want [b a]", got)
+	}
+	if got := NodeAt(root, g.PointF{X: 0.5, Y: 0.5}); got != a {
+		t.Errorf("NodeAt() = %v, want the widget with bigger z", got)
+	}
+}
+
+func TestRotation(t *testing.T) {
+	_, a, b := testTree()
+
+	// A quarter turn around the center of a, {1, 1}, moves b's top-left corner
+	// {0.75, 0.75} to {1.25, 0.75}
+	a.SetRotation(math.Pi / 2)
+	got := b.MapToGlobal(g.PointF{})
+	if want := (g.PointF{X: 1.25, Y: 0.75}); !near(got, want) {
+		t.Errorf("MapToGlobal() = %v, want %v", got, want)
+	}
+	if p := b.MapFromGlobal(got); !near(p, g.PointF{}) {
+		t.Errorf("MapFromGlobal() = %v, want {0 0}", p)
+	}
+
+	// Pivot at the top-left corner turns a out of its unrotated place
+	a.SetPivot(g.PointF{})
+	if Contains(a, g.PointF{X: 1, Y: 1}) {
+		t.Errorf("Contains() = true for a point outside of a rotated widget")
+	}
+	if !Contains(a, g.PointF{X: 0, Y: 1}) {
+		t.Errorf("Contains() = false for a point inside of a rotated widget")
+	}
+}
+
+func near(p, q g.PointF) bool {
+	const eps = 1e-5
+	return math.Abs(float64(p.X-q.X)) < eps && math.Abs(float64(p.Y-q.Y)) < eps
+}
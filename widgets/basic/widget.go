@@ -0,0 +1,418 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package basic
+
+import (
+	"github.com/Sergobot/Rocky/events"
+	g "github.com/Sergobot/Rocky/geometry"
+	"github.com/Sergobot/Rocky/theme"
+)
+
+// Node is anything that can be placed in a widget tree. It's implemented by
+// Widget struct below, so every struct embedding it is a Node too. Since Node
+// has an unexported method, there is no other way to implement it.
+type Node interface {
+	GetReady()
+	Draw()
+
+	// Geometry of a node is relative to its parent. Use GlobalGeometry of Widget
+	// to get window coordinates.
+	SetGeometry(g.RectF)
+	Geometry() g.RectF
+
+	// Tree structure. Nodes are attached to each other with Attach().
+	Parent() Node
+	Children() []Node
+	// RemoveChild detaches a child from the node without destroying it.
+	RemoveChild(Node)
+
+	// HandleEvent is called when an event is delivered to the node.
+	HandleEvent(*events.Event)
+
+	// Destroy destroys a node with all its children and frees resources they hold.
+	Destroy()
+
+	base() *Widget
+}
+
+// Widget is the basic struct used to be embedded in more specific widgets.
+// It deals with geometry and widget tree: parent, children and events. Just
+// like basic.Window, it doesn't draw anything.
+type Widget struct {
+	// Geometry relative to the parent
+	geometry g.RectF
+
+	parent   Node
+	children []Node
+
+	// Outer struct, which embeds this Widget. It's known only after the widget
+	// is attached to a parent.
+	self Node
+
+	handler func(*events.Event)
+
+	// Only focusable widgets may get keyboard focus. focused is set by
+	// FocusManager.
+	focusable, focused bool
+
+	// Mouse transparent widgets are skipped by hit testing with all their
+	// children.
+	mouseTransparent bool
+
+	// Shown near the cursor when it hovers the widget for a while
+	tooltip Node
+
+	// Class used in theme selectors and properties overriding the theme
+	styleClass string
+	style      *theme.Style
+
+	// Hidden widgets aren't drawn and don't get mouse events. Layouts give
+	// them no space, unless reserveSpace is set.
+	hidden, reserveSpace bool
+
+	// Disabled widgets and their children ignore input
+	disabled bool
+
+	// Siblings with bigger z are drawn on top of the others
+	z int
+
+	// Rotation in radians around pivot, which is relative to widget's size.
+	// Pivot is the center, unless it's set.
+	rotation float32
+	pivot    g.PointF
+	pivotSet bool
+}
+
+func (w *Widget) base() *Widget {
+	return w
+}
+
+// SetGeometry sets the rectangle (or bounding box, if you want) of a widget.
+// That means, widget will have same coordinates and size as a given rect.
+func (w *Widget) SetGeometry(r g.RectF) {
+	w.SetSize(r.SizeF)
+	w.SetPos(r.PosF)
+}
+
+// Geometry returns current bounding box of a widget relative to its parent.
+func (w *Widget) Geometry() g.RectF {
+	return w.geometry
+}
+
+// SetSize sets the widget's size. You can call it manually or through SetGeometry.
+func (w *Widget) SetSize(s g.SizeF) {
+	w.geometry.SizeF = s
+}
+
+// Size returns current widget's size.
+func (w *Widget) Size() g.SizeF {
+	return w.geometry.SizeF
+}
+
+// SetPos sets position of a widget relative to its parent. It's used in
+// SetGeometry but you can call it manually.
+func (w *Widget) SetPos(p g.PosF) {
+	w.geometry.PosF = p
+}
+
+// Pos returns current widget's position relative to its parent.
+func (w *Widget) Pos() g.PosF {
+	return w.geometry.PosF
+}
+
+// GlobalGeometry returns widget's geometry in window coordinates. If a widget
+// has no parent, that's the same as Geometry. Rotation is not applied here:
+// that's where the widget would be, if neither it nor its ancestors were
+// rotated. Widgets draw themselves there and GlobalTransform takes care of
+// the rest.
+func (w *Widget) GlobalGeometry() g.RectF {
+	r := w.geometry
+	for n := w.parent; n != nil; n = n.Parent() {
+		pos := n.Geometry().PosF
+		r.X += pos.X
+		r.Y += pos.Y
+	}
+	return r
+}
+
+// MapToGlobal converts a point relative to the widget to window coordinates.
+// Rotation of the widget and its ancestors is taken into account.
+func (w *Widget) MapToGlobal(p g.PointF) g.PointF {
+	origin := w.GlobalGeometry().PosF
+	p.X += origin.X
+	p.Y += origin.Y
+	return w.GlobalTransform().Apply(p)
+}
+
+// MapFromGlobal converts a point in window coordinates to a point relative to
+// the widget. Rotation of the widget and its ancestors is taken into account.
+func (w *Widget) MapFromGlobal(p g.PointF) g.PointF {
+	p = w.GlobalTransform().Invert().Apply(p)
+	origin := w.GlobalGeometry().PosF
+	return g.PointF{X: p.X - origin.X, Y: p.Y - origin.Y}
+}
+
+// SetVisible shows or hides the widget with all its children. Hidden widgets
+// are not drawn, don't get mouse events and can't be focused. Widgets are
+// visible by default.
+func (w *Widget) SetVisible(v bool) {
+	if w.hidden == !v {
+		return
+	}
+	w.hidden = !v
+	w.relayout()
+}
+
+// Visible returns false if the widget itself is hidden. Use IsVisible to take
+// its ancestors into account.
+func (w *Widget) Visible() bool {
+	return !w.hidden
+}
+
+// SetReserveSpace tells layouts to keep space for the widget, while it's
+// hidden, so the widgets around it stay where they are.
+func (w *Widget) SetReserveSpace(r bool) {
+	if w.reserveSpace == r {
+		return
+	}
+	w.reserveSpace = r
+	if w.hidden {
+		w.relayout()
+	}
+}
+
+// ReserveSpace returns true if layouts keep space for the widget, while it's
+// hidden.
+func (w *Widget) ReserveSpace() bool {
+	return w.reserveSpace
+}
+
+// relayout asks the parent to rearrange its children, if it has a layout.
+func (w *Widget) relayout() {
+	if l, ok := w.parent.(interface {
+		UpdateLayout()
+	}); ok {
+		l.UpdateLayout()
+	}
+}
+
+// SetEnabled enables or disables the widget with all its children. Disabled
+// widgets ignore mouse and keys, can't be focused and are drawn greyed out.
+// Widgets are enabled by default.
+func (w *Widget) SetEnabled(e bool) {
+	w.disabled = !e
+}
+
+// Enabled returns false if the widget itself is disabled. Use IsEnabled to
+// take its ancestors into account.
+func (w *Widget) Enabled() bool {
+	return !w.disabled
+}
+
+// SetZ sets the z-index of the widget. Siblings with bigger z are drawn on top
+// of the others and get mouse events first. Siblings with the same z keep
+// the order they were attached in. Default z is zero.
+func (w *Widget) SetZ(z int) {
+	w.z = z
+}
+
+// Z returns the z-index of the widget.
+func (w *Widget) Z() int {
+	return w.z
+}
+
+// SetRotation sets the angle (in radians) the widget is rotated by around its
+// pivot. Children are rotated together with it. Positive angles rotate
+// clockwise. Layouts don't care about rotation, they arrange widgets as if
+// there were none.
+func (w *Widget) SetRotation(angle float32) {
+	w.rotation = angle
+}
+
+// Rotation returns the angle the widget is rotated by.
+func (w *Widget) Rotation() float32 {
+	return w.rotation
+}
+
+// SetPivot sets the point the widget is rotated around. It's relative to the
+// widget's size: {0, 0} is the top-left corner, {1, 1} is the bottom-right
+// one. Default pivot is the center.
+func (w *Widget) SetPivot(p g.PointF) {
+	w.pivot = p
+	w.pivotSet = true
+}
+
+// Pivot returns the point the widget is rotated around, relative to its size.
+func (w *Widget) Pivot() g.PointF {
+	if !w.pivotSet {
+		return g.PointF{X: 0.5, Y: 0.5}
+	}
+	return w.pivot
+}
+
+// localTransform returns the rotation of the widget itself around its pivot
+// in window coordinates.
+func (w *Widget) localTransform() g.Transform {
+	if w.rotation == 0 {
+		return g.Identity()
+	}
+	r := w.GlobalGeometry()
+	p := w.Pivot()
+	return g.Rotation(w.rotation, g.PointF{X: r.X + p.X*r.W, Y: r.Y + p.Y*r.H})
+}
+
+// GlobalTransform returns the transform, which moves points from where they
+// would be without any rotation (see GlobalGeometry) to where they are on the
+// screen. It combines rotations of the widget and all its ancestors.
+func (w *Widget) GlobalTransform() g.Transform {
+	t := w.localTransform()
+	for n := w.parent; n != nil; n = n.Parent() {
+		t = n.base().localTransform().Mul(t)
+	}
+	return t
+}
+
+// Parent returns a node the widget is attached to or nil.
+func (w *Widget) Parent() Node {
+	return w.parent
+}
+
+// Children returns nodes attached to the widget.
+func (w *Widget) Children() []Node {
+	return w.children
+}
+
+// RemoveChild detaches a child from the widget. The child isn't destroyed and
+// may be attached somewhere else.
+func (w *Widget) RemoveChild(n Node) {
+	for i, c := range w.children {
+		if c.base() == n.base() {
+			w.children = append(w.children[:i], w.children[i+1:]...)
+			n.base().parent = nil
+			return
+		}
+	}
+}
+
+// SetEventHandler sets a function called for every event delivered to the
+// widget. Call Accept() on the event to stop its propagation to the parent.
+func (w *Widget) SetEventHandler(h func(*events.Event)) {
+	w.handler = h
+}
+
+// HandleEvent passes an event to the event handler, if there is one. Widgets
+// reacting to events on their own reimplement this method.
+func (w *Widget) HandleEvent(e *events.Event) {
+	if w.handler != nil {
+		w.handler(e)
+	}
+}
+
+// SetFocusable allows or forbids the widget to get keyboard focus. Widgets are
+// not focusable by default.
+func (w *Widget) SetFocusable(f bool) {
+	w.focusable = f
+}
+
+// Focusable returns true if the widget may get keyboard focus.
+func (w *Widget) Focusable() bool {
+	return w.focusable
+}
+
+// HasFocus returns true if the widget has keyboard focus right now.
+func (w *Widget) HasFocus() bool {
+	return w.focused
+}
+
+// SetMouseTransparent makes the widget and its children invisible for the
+// mouse: events go to whatever is under them.
+func (w *Widget) SetMouseTransparent(t bool) {
+	w.mouseTransparent = t
+}
+
+// MouseTransparent returns true if the widget is invisible for the mouse.
+func (w *Widget) MouseTransparent() bool {
+	return w.mouseTransparent
+}
+
+// SetTooltip sets a widget shown near the cursor, when it hovers this widget
+// for a while. Pass nil to remove the tooltip. Tooltips are shown by window's
+// overlay.
+func (w *Widget) SetTooltip(t Node) {
+	w.tooltip = t
+}
+
+// Tooltip returns widget's tooltip or nil.
+func (w *Widget) Tooltip() Node {
+	return w.tooltip
+}
+
+// SetStyleClass sets the class of the widget used in theme selectors, like
+// "volume" in "Slider.volume".
+func (w *Widget) SetStyleClass(class string) {
+	w.styleClass = class
+}
+
+// StyleClass returns the class of the widget used in theme selectors.
+func (w *Widget) StyleClass() string {
+	return w.styleClass
+}
+
+// SetStyle sets properties overriding the theme for this widget only. Pass nil
+// to use just the theme.
+func (w *Widget) SetStyle(s *theme.Style) {
+	w.style = s
+}
+
+// Style returns properties overriding the theme for this widget or nil.
+func (w *Widget) Style() *theme.Style {
+	return w.style
+}
+
+// ResolveStyle returns the style a widget of the given type is drawn with:
+// the current theme's style for the widget's class and state, merged with the
+// widget's own overrides. Widgets call it every time they are drawn, so they
+// follow theme changes.
+func (w *Widget) ResolveStyle(typ string, state theme.State) theme.Style {
+	s := theme.Current().Style(typ, w.styleClass, state)
+	if w.style != nil {
+		s = s.Merge(*w.style)
+	}
+	return s
+}
+
+// GetReady does nothing: Widget has nothing to initialize.
+func (w *Widget) GetReady() {}
+
+// Draw does nothing: Widget's always blank, we don't draw anything on it
+func (w *Widget) Draw() {}
+
+// Destroy destroys all the children and detaches the widget from its parent.
+// Widgets holding any resources should free them and call this method, when
+// reimplementing it.
+func (w *Widget) Destroy() {
+	// Children remove themselves from w.children, so iterate over a copy
+	children := append([]Node(nil), w.children...)
+	for _, c := range children {
+		c.Destroy()
+	}
+
+	if w.parent != nil {
+		w.parent.RemoveChild(w.self)
+	}
+}
+
+// Attach makes child a child of parent. If child already has a parent, it's
+// removed from it first.
+func Attach(parent, child Node) {
+	if old := child.Parent(); old != nil {
+		old.RemoveChild(child)
+	}
+
+	cb := child.base()
+	cb.parent = parent
+	cb.self = child
+
+	pb := parent.base()
+	pb.children = append(pb.children, child)
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package widgets
+
+import (
+	g "github.com/Sergobot/Rocky/geometry"
+	"github.com/Sergobot/Rocky/opengl"
+	ogl33 "github.com/Sergobot/Rocky/opengl/gl33"
+	"github.com/Sergobot/Rocky/paint"
+	wgts33 "github.com/Sergobot/Rocky/widgets/gl33"
+)
+
+// Canvas is a widget to draw on with an API modeled on HTML canvas. Whatever is
+// drawn is kept and shown every frame, until Clear is called, so static
+// pictures are drawn once, and changing ones are cleared and redrawn.
+// Coordinates are in pixels, with origin at the top-left corner of the widget.
+type Canvas interface {
+	Widget
+
+	// Release frees video memory of the Canvas, but leaves it in the widget
+	// tree, unlike Destroy.
+	Release()
+
+	// Clear erases everything drawn
+	Clear()
+
+	// Save and Restore push and pop drawing state: transform, clip, colors,
+	// line and font settings.
+	Save()
+	Restore()
+
+	// Transform of everything drawn afterwards
+	Translate(x, y float32)
+	Scale(x, y float32)
+	Rotate(angle float32)
+	SetTransform(g.Transform)
+	Transform() g.Transform
+
+	// Clip and ClipPath limit drawing to a (transformed) rect or the inside of
+	// a path inside of the current clip.
+	Clip(g.RectF)
+	ClipPath(*paint.Path)
+
+	SetFillColor(paint.Color)
+	FillColor() paint.Color
+	// SetFillRule sets how FillPath and ClipPath tell the inside of paths,
+	// which overlap or intersect themselves. paint.NonZero is the default.
+	SetFillRule(paint.FillRule)
+	FillRule() paint.FillRule
+	SetStrokeColor(paint.Color)
+	StrokeColor() paint.Color
+	SetLineWidth(float32)
+	LineWidth() float32
+	SetLineCap(paint.Cap)
+	LineCap() paint.Cap
+	SetLineJoin(paint.Join)
+	LineJoin() paint.Join
+	SetGlobalAlpha(float32)
+	GlobalAlpha() float32
+
+	// SetFont sets a font and height of a line of text in pixels. Nil is
+	// paint.DefaultFont().
+	SetFont(*paint.Font, float32)
+	Font() (*paint.Font, float32)
+
+	FillRect(g.RectF)
+	StrokeRect(g.RectF)
+	FillPath(*paint.Path)
+	StrokePath(*paint.Path)
+
+	// DrawImage draws a texture into a rect, DrawImagePart draws its part
+	// (in pixels of the texture)
+	DrawImage(opengl.Texture, g.RectF)
+	DrawImagePart(opengl.Texture, g.Rect, g.RectF)
+
+	// DrawText writes text with its top-left corner at the given point
+	DrawText(string, g.PointF)
+	MeasureText(string) g.SizeF
+}
+
+// NewCanvas returns a struct, which implements Canvas interface defined above.
+func NewCanvas() Canvas {
+	if ogl33.Initialized() {
+		return wgts33.NewCanvas()
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package container
+
+import (
+	g "github.com/Sergobot/Rocky/geometry"
+	"github.com/Sergobot/Rocky/layouts"
+	"github.com/Sergobot/Rocky/widgets"
+	"github.com/Sergobot/Rocky/widgets/basic"
+)
+
+// Container is a widget holding other widgets. It owns a layout, which arranges
+// its children inside the container. Children's geometry is relative to the
+// container, so moving a container moves all its children too.
+// Containers may be nested to build complex widget trees:
+//
+//	root (Vertical)
+//	|- header (Horizontal)
+//	|  |- logo
+//	|  |- title
+//	|- content
+type Container struct {
+	basic.Widget
+
+	layout layouts.Layout
+}
+
+// New returns a container arranging its children with the given layout.
+func New(l layouts.Layout) *Container {
+	c := new(Container)
+	c.SetLayout(l)
+	return c
+}
+
+// SetLayout sets the layout of a container. Widgets of the layout become
+// children of the container, widgets of the old layout, which are not in the
+// new one, are detached. Widgets added to the layout later are attached too.
+// A nil layout leaves the container empty.
+func (c *Container) SetLayout(l layouts.Layout) {
+	old := c.layout
+	c.layout = l
+
+	if old != nil {
+		old.SetOwner(nil)
+		for _, w := range old.Widgets() {
+			if !inLayout(l, w) {
+				c.Widget.RemoveChild(w)
+			}
+		}
+	}
+	if l != nil {
+		l.SetOwner(c)
+		for _, w := range l.Widgets() {
+			c.attach(w)
+		}
+	}
+	c.UpdateLayout()
+}
+
+// attach makes a widget a child of the container, if it isn't yet.
+func (c *Container) attach(w widgets.Widget) {
+	if w.Parent() != basic.Node(c) {
+		basic.Attach(c, w)
+	}
+}
+
+// WidgetAdded is called by the layout, when a widget is added to it. The
+// widget becomes a child of the container.
+func (c *Container) WidgetAdded(w widgets.Widget) {
+	c.attach(w)
+	c.UpdateLayout()
+}
+
+// WidgetRemoved is called by the layout, when a widget is removed from it. The
+// widget is detached from the container.
+func (c *Container) WidgetRemoved(w widgets.Widget) {
+	c.Widget.RemoveChild(w)
+	c.UpdateLayout()
+}
+
+// inLayout returns true if a widget is in a layout, which may be nil.
+func inLayout(l layouts.Layout, w widgets.Widget) bool {
+	if l == nil {
+		return false
+	}
+	for _, v := range l.Widgets() {
+		if v == w {
+			return true
+		}
+	}
+	return false
+}
+
+// Layout returns the layout of a container.
+func (c *Container) Layout() layouts.Layout {
+	return c.layout
+}
+
+// AddWidget adds a widget to the container and its layout.
+func (c *Container) AddWidget(w widgets.Widget) {
+	if c.layout == nil {
+		c.attach(w)
+		return
+	}
+	// The layout attaches it, see WidgetAdded
+	c.layout.AddWidget(w)
+}
+
+// RemoveWidget removes a widget from the container without destroying it.
+func (c *Container) RemoveWidget(w widgets.Widget) {
+	c.RemoveChild(w)
+}
+
+// RemoveChild detaches a child from the container and its layout.
+func (c *Container) RemoveChild(n basic.Node) {
+	if w, ok := n.(widgets.Widget); ok && c.layout != nil {
+		// Error only means the widget wasn't in the layout, that's fine
+		c.layout.RemoveWidget(w)
+	}
+	c.Widget.RemoveChild(n)
+	c.UpdateLayout()
+}
+
+// SetGeometry sets geometry of a container and rearranges its children.
+func (c *Container) SetGeometry(r g.RectF) {
+	c.Widget.SetGeometry(r)
+	c.UpdateLayout()
+}
+
+// SetSize sets size of a container and rearranges its children.
+func (c *Container) SetSize(s g.SizeF) {
+	c.Widget.SetSize(s)
+	c.UpdateLayout()
+}
+
+// UpdateLayout makes the layout cover the whole container and rearranges the
+// children. Coordinates are relative to the container itself, so layout's
+// position is always zero. Children call it when they are shown or hidden.
+func (c *Container) UpdateLayout() {
+	if c.layout != nil {
+		c.layout.SetGeometry(g.RectF{SizeF: c.Size()})
+		// SetGeometry of BasicLayout calls its own Activate, not the one of
+		// the embedding layout, so children are arranged explicitly
+		c.layout.Activate()
+	}
+}
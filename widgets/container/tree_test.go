@@ -0,0 +1,76 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package container
+
+import (
+	"testing"
+
+	g "github.com/Sergobot/Rocky/geometry"
+	"github.com/Sergobot/Rocky/layouts"
+	"github.com/Sergobot/Rocky/widgets/basic"
+)
+
+// Builds a layout first, just like windows get it in SetLayout, and checks
+// its widgets are reachable through the container.
+func TestNodeAtLayout(t *testing.T) {
+	top, bottom := new(basic.Widget), new(basic.Widget)
+	l := new(layouts.Vertical)
+	l.AddWidget(top)
+	l.AddWidget(bottom)
+
+	c := New(l)
+	c.SetGeometry(g.RectF{SizeF: g.SizeF{W: 2, H: 2}})
+
+	cases := []struct {
+		p    g.PointF
+		want basic.Node
+	}{
+		{g.PointF{X: 1, Y: 0.5}, top},
+		{g.PointF{X: 1, Y: 1.5}, bottom},
+		{g.PointF{X: 3, Y: 3}, nil},
+	}
+	for _, cs := range cases {
+		if got := basic.NodeAt(c, cs.p); got != cs.want {
+			t.Errorf("NodeAt(%v) = %v, want %v", cs.p, got, cs.want)
+		}
+	}
+
+	// Widgets not in the new layout are detached
+	other := new(layouts.Vertical)
+	other.AddWidget(bottom)
+	c.SetLayout(other)
+	if top.Parent() != nil || bottom.Parent() != basic.Node(c) {
+		t.Errorf("Parents after SetLayout are %v and %v", top.Parent(), bottom.Parent())
+	}
+	if got := basic.NodeAt(c, g.PointF{X: 1, Y: 0.5}); got != basic.Node(bottom) {
+		t.Errorf("NodeAt() after SetLayout = %v, want the bottom widget", got)
+	}
+
+	c.SetLayout(nil)
+	if len(c.Children()) != 0 {
+		t.Errorf("Container has %d children with no layout", len(c.Children()))
+	}
+}
+
+// Widgets added to the layout after it's set are attached to the container
+// too, just like windows get them after SetLayout.
+func TestLayoutAddWidget(t *testing.T) {
+	c := New(nil)
+	c.SetGeometry(g.RectF{SizeF: g.SizeF{W: 2, H: 2}})
+
+	l := new(layouts.Vertical)
+	c.SetLayout(l)
+	w := new(basic.Widget)
+	l.AddWidget(w)
+
+	if got := basic.NodeAt(c, g.PointF{X: 1, Y: 1}); got != basic.Node(w) {
+		t.Errorf("NodeAt() = %v, want the widget added to the layout", got)
+	}
+
+	if err := l.RemoveWidget(w); err != nil {
+		t.Fatal(err)
+	}
+	if w.Parent() != nil {
+		t.Errorf("Widget removed from the layout is still attached")
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package widgets
+
+import (
+	ogl33 "github.com/Sergobot/Rocky/opengl/gl33"
+	"github.com/Sergobot/Rocky/widgets/basic"
+	wgts33 "github.com/Sergobot/Rocky/widgets/gl33"
+)
+
+// Controls are interactive widgets used in settings menus and alike. All of
+// them call a function when their value changes, either by user or by code,
+// and all the interactive ones are focusable and controlled with keyboard or
+// gamepad too.
+
+// Slider selects a value in a range by dragging a knob along a track. Left and
+// Right keys move the knob by a step.
+type Slider interface {
+	Widget
+
+	SetRange(min, max float32)
+	Range() (float32, float32)
+
+	// SetStep sets the step values are rounded to. Zero means no rounding.
+	SetStep(float32)
+	Step() float32
+
+	SetValue(float32)
+	Value() float32
+
+	SetOnChanged(func(float32))
+}
+
+// CheckBox is a box, which may be checked and unchecked with a click, Space or
+// Enter.
+type CheckBox interface {
+	Widget
+
+	SetChecked(bool)
+	Checked() bool
+	Toggle()
+
+	SetOnChanged(func(bool))
+}
+
+// RadioGroup is a column of options, only one of which may be selected. Up
+// and Down keys move the selection.
+type RadioGroup interface {
+	Widget
+
+	// AddOption adds an option and returns its index. Label is any widget shown
+	// next to option's button, it may be nil.
+	AddOption(label basic.Node) int
+	Options() int
+
+	// Index -1 means nothing is selected
+	SetSelected(int)
+	Selected() int
+
+	SetOnChanged(func(int))
+}
+
+// ProgressBar shows progress of some long operation, like loading a level.
+// Its value is between 0 and 1.
+type ProgressBar interface {
+	Widget
+
+	SetValue(float32)
+	Value() float32
+
+	SetOnChanged(func(float32))
+}
+
+// Dropdown shows the selected item of a list, and the whole list in a popup,
+// when it's clicked or activated with Space or Enter. Items are any widgets,
+// for example Pixmaps with some text.
+type Dropdown interface {
+	Widget
+
+	// AddItem adds an item and returns its index. The dropdown owns its items
+	// and destroys them with itself.
+	AddItem(basic.Node) int
+	Items() []basic.Node
+
+	// Index -1 means nothing is selected
+	SetSelected(int)
+	Selected() int
+
+	SetOnChanged(func(int))
+
+	// Open and Close show and hide the list.
+	Open()
+	Close()
+	IsOpen() bool
+}
+
+// NewSlider returns a struct, which implements Slider interface defined above.
+func NewSlider() Slider {
+	if ogl33.Initialized() {
+		return wgts33.NewSlider()
+	}
+	return nil
+}
+
+// NewCheckBox returns a struct, which implements CheckBox interface defined above.
+func NewCheckBox() CheckBox {
+	if ogl33.Initialized() {
+		return wgts33.NewCheckBox()
+	}
+	return nil
+}
+
+// NewRadioGroup returns a struct, which implements RadioGroup interface defined above.
+func NewRadioGroup() RadioGroup {
+	if ogl33.Initialized() {
+		return wgts33.NewRadioGroup()
+	}
+	return nil
+}
+
+// NewProgressBar returns a struct, which implements ProgressBar interface defined above.
+func NewProgressBar() ProgressBar {
+	if ogl33.Initialized() {
+		return wgts33.NewProgressBar()
+	}
+	return nil
+}
+
+// NewDropdown returns a struct, which implements Dropdown interface defined above.
+func NewDropdown() Dropdown {
+	if ogl33.Initialized() {
+		return wgts33.NewDropdown()
+	}
+	return nil
+}
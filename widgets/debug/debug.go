@@ -0,0 +1,386 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+// Package debug implements an overlay drawn on top of a window to see what's
+// going on inside of it: frame rate, frame times, OpenGL counters and bounds of
+// every widget. Windows create one and toggle it with F12.
+package debug
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Sergobot/Rocky/events"
+	g "github.com/Sergobot/Rocky/geometry"
+	"github.com/Sergobot/Rocky/opengl/gl33"
+	"github.com/Sergobot/Rocky/paint"
+	"github.com/Sergobot/Rocky/widgets"
+	"github.com/Sergobot/Rocky/widgets/basic"
+)
+
+// FrameHistory is the number of frames shown on the frame time graph.
+const FrameHistory = 120
+
+// Sizes of the graph in pixels. Bars of frames twice as long as a 60 FPS frame
+// reach the top.
+const (
+	graphBar    float32 = 2
+	graphHeight float32 = 60
+	graphMax            = 2 * targetFrame
+	targetFrame         = time.Second / 60
+)
+
+var (
+	panelColor    = paint.Color{R: 0, G: 0, B: 0, A: 0.7}
+	textColor     = paint.Color{R: 1, G: 1, B: 1, A: 1}
+	boundsColor   = paint.Color{R: 0.2, G: 0.9, B: 1, A: 0.8}
+	selectedColor = paint.Color{R: 1, G: 0.8, B: 0.2, A: 1}
+	barColor      = paint.Color{R: 0.3, G: 0.9, B: 0.3, A: 1}
+	slowBarColor  = paint.Color{R: 1, G: 0.3, B: 0.2, A: 1}
+	errorColor    = paint.Color{R: 0.5, G: 0, B: 0, A: 0.85}
+)
+
+// Overlay shows debug information on top of a window. While it's visible,
+// clicking a widget selects it, and properties of the selected widget are
+// shown. The overlay isn't a part of the widget tree: it's drawn by the window
+// after everything else.
+type Overlay struct {
+	visible bool
+
+	// Ring buffer of frame times, next is where the next one goes
+	times       [FrameHistory]time.Duration
+	next, count int
+
+	selected basic.Node
+
+	// Error shown at the bottom, even if the overlay is hidden
+	err error
+
+	// Everything is drawn on a canvas covering the window. It's made, when the
+	// overlay is drawn for the first time.
+	canvas widgets.Canvas
+}
+
+// New returns a hidden overlay.
+func New() *Overlay {
+	return new(Overlay)
+}
+
+// SetVisible shows or hides the overlay.
+func (o *Overlay) SetVisible(v bool) {
+	o.visible = v
+}
+
+// Visible returns true if the overlay is shown.
+func (o *Overlay) Visible() bool {
+	return o.visible
+}
+
+// Toggle shows the overlay, if it's hidden, and hides it otherwise.
+func (o *Overlay) Toggle() {
+	o.visible = !o.visible
+}
+
+// SetError sets an error to show at the bottom of the window, like one of
+// reloading a shader. The error is shown even if the overlay is hidden, until
+// it's set to nil.
+func (o *Overlay) SetError(err error) {
+	o.err = err
+}
+
+// Error returns the error shown.
+func (o *Overlay) Error() error {
+	return o.err
+}
+
+// Frame records time a frame took. Windows call it every frame, even when the
+// overlay is hidden, so the graph is full as soon as it's shown.
+func (o *Overlay) Frame(dt time.Duration) {
+	o.times[o.next] = dt
+	o.next = (o.next + 1) % FrameHistory
+	if o.count < FrameHistory {
+		o.count++
+	}
+}
+
+// FrameTimes returns times of recorded frames, the oldest first.
+func (o *Overlay) FrameTimes() []time.Duration {
+	res := make([]time.Duration, 0, o.count)
+	start := (o.next - o.count + FrameHistory) % FrameHistory
+	for i := 0; i < o.count; i++ {
+		res = append(res, o.times[(start+i)%FrameHistory])
+	}
+	return res
+}
+
+// FPS returns average frame rate over the recorded frames.
+func (o *Overlay) FPS() float64 {
+	var total time.Duration
+	for _, t := range o.FrameTimes() {
+		total += t
+	}
+	if total <= 0 {
+		return 0
+	}
+	return float64(o.count) / total.Seconds()
+}
+
+// Select makes properties of a node shown. Nil clears the selection.
+func (o *Overlay) Select(n basic.Node) {
+	o.selected = n
+}
+
+// Selected returns the selected node or nil.
+func (o *Overlay) Selected() basic.Node {
+	return o.selected
+}
+
+// HandleEvent lets the overlay take mouse clicks, while it's visible: pressing
+// the left button selects the widget under the cursor in root's tree. It
+// returns true if the event was taken, then it shouldn't go to widgets.
+func (o *Overlay) HandleEvent(root basic.Node, e *events.Event) bool {
+	if !o.visible || e.Button != events.MouseLeft {
+		return false
+	}
+
+	switch e.Type {
+	case events.MousePress:
+		o.selected = nil
+		if root != nil {
+			o.selected = basic.NodeAt(root, e.Pos)
+		}
+		e.Accept()
+		return true
+	case events.MouseRelease:
+		e.Accept()
+		return true
+	}
+	return false
+}
+
+// Describe returns properties of a node as text, a property per line.
+func Describe(n basic.Node) string {
+	if n == nil {
+		return ""
+	}
+
+	r, gr := n.Geometry(), basic.GlobalGeometry(n)
+	s := fmt.Sprintf("%T\n", n)
+	s += fmt.Sprintf("Geometry: %.3f, %.3f  %.3f x %.3f\n", r.X, r.Y, r.W, r.H)
+	s += fmt.Sprintf("Global: %.3f, %.3f  %.3f x %.3f\n", gr.X, gr.Y, gr.W, gr.H)
+	s += fmt.Sprintf("Visible: %v  Enabled: %v\n", basic.IsVisible(n), basic.IsEnabled(n))
+
+	// Every node embeds basic.Widget, but these are checked anyway, so nodes
+	// overriding them are described right
+	if z, ok := n.(interface {
+		Z() int
+	}); ok {
+		s += fmt.Sprintf("Z: %d\n", z.Z())
+	}
+	if rot, ok := n.(interface {
+		Rotation() float32
+	}); ok {
+		s += fmt.Sprintf("Rotation: %.2f\n", rot.Rotation())
+	}
+	if f, ok := n.(interface {
+		Focusable() bool
+	}); ok {
+		s += fmt.Sprintf("Focusable: %v\n", f.Focusable())
+	}
+	if c, ok := n.(interface {
+		StyleClass() string
+	}); ok && c.StyleClass() != "" {
+		s += fmt.Sprintf("Style class: %s\n", c.StyleClass())
+	}
+
+	s += fmt.Sprintf("Children: %d", len(n.Children()))
+	return s
+}
+
+// Draw draws the overlay, if it's visible: bounds of every visible node in
+// root's tree, OpenGL counters from st, frame time graph and properties of the
+// selected node. The error, if there is one, is drawn anyway.
+func (o *Overlay) Draw(root basic.Node, st gl33.Stats) {
+	if !o.visible && o.err == nil {
+		return
+	}
+	if o.canvas == nil {
+		o.canvas = widgets.NewCanvas()
+		if o.canvas == nil {
+			return
+		}
+		o.canvas.GetReady()
+	}
+
+	// Selected node may have been removed from the tree
+	if o.selected != nil && !basic.InTree(root, o.selected) {
+		o.selected = nil
+	}
+
+	c := o.canvas
+	c.SetGeometry(g.RectF{SizeF: gl33.NormalizedViewportSize()})
+	c.Clear()
+
+	if o.visible {
+		if root != nil {
+			o.drawBounds(root)
+		}
+		o.drawStats(st)
+		o.drawSelected()
+	}
+	o.drawError()
+
+	c.Draw()
+}
+
+// drawBounds outlines every visible node and writes its type.
+func (o *Overlay) drawBounds(root basic.Node) {
+	c := o.canvas
+	px := gl33.NormalizedPixelSize()
+	c.SetLineWidth(1)
+
+	basic.Walk(root, func(n basic.Node) bool {
+		if !basic.IsVisible(n) {
+			return false
+		}
+
+		r := basic.GlobalGeometry(n)
+		r = g.RectF{
+			PosF:  g.PosF{X: r.X / px, Y: r.Y / px},
+			SizeF: g.SizeF{W: r.W / px, H: r.H / px},
+		}
+
+		// Global transform works with normalized coordinates, canvas with
+		// pixels
+		c.Save()
+		c.SetTransform(g.Scaling(1/px, 1/px).Mul(basic.GlobalTransform(n)).Mul(g.Scaling(px, px)))
+		col := boundsColor
+		if n == o.selected {
+			col = selectedColor
+			c.SetLineWidth(2)
+		}
+		c.SetStrokeColor(col)
+		c.StrokeRect(r)
+		c.SetFillColor(col)
+		c.DrawText(fmt.Sprintf("%T", n), g.PointF{X: r.X + 2, Y: r.Y + 2})
+		c.Restore()
+		return true
+	})
+}
+
+// drawStats draws the panel with counters and the frame time graph in the
+// top-left corner.
+func (o *Overlay) drawStats(st gl33.Stats) {
+	c := o.canvas
+	const margin, padding = 8, 6
+
+	fps := o.FPS()
+	var ms float64
+	if fps > 0 {
+		ms = 1000 / fps
+	}
+	text := fmt.Sprintf("FPS: %.1f (%.2f ms)\nDraw calls: %d\nTexture binds: %d\nTextures: %d",
+		fps, ms, st.DrawCalls, st.TextureBinds, st.Textures)
+	size := c.MeasureText(text)
+
+	graphWidth := graphBar * FrameHistory
+	panel := g.RectF{
+		PosF: g.PosF{X: margin, Y: margin},
+		SizeF: g.SizeF{
+			W: 2*padding + max(size.W, graphWidth),
+			H: 3*padding + size.H + graphHeight,
+		},
+	}
+	c.SetFillColor(panelColor)
+	c.FillRect(panel)
+
+	c.SetFillColor(textColor)
+	c.DrawText(text, g.PointF{X: panel.X + padding, Y: panel.Y + padding})
+
+	// Frame time graph, the newest frame on the right
+	graph := g.RectF{
+		PosF:  g.PosF{X: panel.X + padding, Y: panel.Y + 2*padding + size.H},
+		SizeF: g.SizeF{W: graphWidth, H: graphHeight},
+	}
+	times := o.FrameTimes()
+	x := graph.X + graph.W - graphBar*float32(len(times))
+	for _, t := range times {
+		h := graph.H * float32(t) / float32(graphMax)
+		if h > graph.H {
+			h = graph.H
+		}
+		col := barColor
+		if t > targetFrame+time.Millisecond {
+			col = slowBarColor
+		}
+		c.SetFillColor(col)
+		c.FillRect(g.RectF{PosF: g.PosF{X: x, Y: graph.Y + graph.H - h}, SizeF: g.SizeF{W: graphBar, H: h}})
+		x += graphBar
+	}
+
+	// Line of a 60 FPS frame
+	y := graph.Y + graph.H*(1-float32(targetFrame)/float32(graphMax))
+	var line paint.Path
+	line.MoveTo(graph.X, y)
+	line.LineTo(graph.X+graph.W, y)
+	c.SetStrokeColor(textColor)
+	c.SetLineWidth(1)
+	c.StrokePath(&line)
+}
+
+// drawSelected draws properties of the selected node in the top-right corner.
+func (o *Overlay) drawSelected() {
+	if o.selected == nil {
+		return
+	}
+	c := o.canvas
+	const margin, padding = 8, 6
+
+	text := Describe(o.selected)
+	size := c.MeasureText(text)
+	width := c.Geometry().W / gl33.NormalizedPixelSize()
+	panel := g.RectF{
+		PosF:  g.PosF{X: width - margin - size.W - 2*padding, Y: margin},
+		SizeF: g.SizeF{W: size.W + 2*padding, H: size.H + 2*padding},
+	}
+	c.SetFillColor(panelColor)
+	c.FillRect(panel)
+	c.SetFillColor(textColor)
+	c.DrawText(text, g.PointF{X: panel.X + padding, Y: panel.Y + padding})
+}
+
+// drawError draws the error across the bottom of the window.
+func (o *Overlay) drawError() {
+	if o.err == nil {
+		return
+	}
+	c := o.canvas
+	const padding = 6
+
+	text := o.err.Error()
+	size := c.MeasureText(text)
+	px := gl33.NormalizedPixelSize()
+	width, height := c.Geometry().W/px, c.Geometry().H/px
+	panel := g.RectF{
+		PosF:  g.PosF{X: 0, Y: height - size.H - 2*padding},
+		SizeF: g.SizeF{W: width, H: size.H + 2*padding},
+	}
+	c.SetFillColor(errorColor)
+	c.FillRect(panel)
+	c.SetFillColor(textColor)
+	c.DrawText(text, g.PointF{X: panel.X + padding, Y: panel.Y + padding})
+}
+
+// Destroy frees OpenGL resources of the overlay.
+func (o *Overlay) Destroy() {
+	if o.canvas != nil {
+		o.canvas.Destroy()
+		o.canvas = nil
+	}
+}
+
+func max(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
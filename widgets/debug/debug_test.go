@@ -0,0 +1,63 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package debug
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sergobot/Rocky/events"
+	g "github.com/Sergobot/Rocky/geometry"
+	"github.com/Sergobot/Rocky/widgets/basic"
+)
+
+func TestFrameTimes(t *testing.T) {
+	o := New()
+	if o.FPS() != 0 {
+		t.Errorf("FPS() = %v without frames, want 0", o.FPS())
+	}
+
+	for i := 0; i < FrameHistory; i++ {
+		o.Frame(10 * time.Millisecond)
+	}
+	o.Frame(40 * time.Millisecond)
+
+	times := o.FrameTimes()
+	if len(times) != FrameHistory || times[len(times)-1] != 40*time.Millisecond {
+		t.Fatalf("Got %d frames ending with %v, want %d ending with 40ms", len(times), times[len(times)-1], FrameHistory)
+	}
+	// The oldest frame is dropped
+	want := float64(FrameHistory) / (float64(FrameHistory-1)*0.01 + 0.04)
+	if fps := o.FPS(); math.Abs(fps-want) > 1e-6 {
+		t.Errorf("FPS() = %v, want %v", fps, want)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	root, child := new(basic.Widget), new(basic.Widget)
+	root.SetGeometry(g.RectF{SizeF: g.SizeF{W: 2, H: 2}})
+	child.SetGeometry(g.RectF{PosF: g.PosF{X: 0.5, Y: 0.5}, SizeF: g.SizeF{W: 1, H: 0.5}})
+	child.SetZ(3)
+	basic.Attach(root, child)
+
+	o := New()
+	press := &events.Event{Type: events.MousePress, Pos: g.PointF{X: 1, Y: 0.75}}
+	if o.HandleEvent(root, press) {
+		t.Error("Hidden overlay has taken a click")
+	}
+
+	o.Toggle()
+	press = &events.Event{Type: events.MousePress, Pos: g.PointF{X: 1, Y: 0.75}}
+	if !o.HandleEvent(root, press) || o.Selected() != child {
+		t.Fatalf("Click has selected %v, want the child", o.Selected())
+	}
+
+	desc := Describe(o.Selected())
+	for _, s := range []string{"*basic.Widget", "Global: 0.500, 0.500  1.000 x 0.500", "Z: 3", "Children: 0"} {
+		if !strings.Contains(desc, s) {
+			t.Errorf("Description %q doesn't contain %q", desc, s)
+		}
+	}
+}
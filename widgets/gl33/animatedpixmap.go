@@ -0,0 +1,166 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"log"
+	"time"
+
+	"github.com/Sergobot/Rocky/animated"
+	"github.com/Sergobot/Rocky/animation"
+	"github.com/Sergobot/Rocky/opengl/gl33"
+)
+
+// AnimatedPixmap is a Pixmap playing an animated GIF or APNG. It plays, when
+// updated with Update, or played as an animation with a window's animation
+// player. Pausing stops it either way.
+type AnimatedPixmap struct {
+	Pixmap
+
+	anim *gl33.AnimatedTexture
+
+	// Time since the start of the animation
+	position time.Duration
+	playing  bool
+
+	// Last time the player has passed to Seek
+	last time.Duration
+
+	onFinished func()
+}
+
+// NewAnimatedPixmap returns an empty AnimatedPixmap, which plays as soon as an
+// image is loaded.
+func NewAnimatedPixmap() *AnimatedPixmap {
+	p := &AnimatedPixmap{anim: new(gl33.AnimatedTexture), playing: true}
+	p.Pixmap.init()
+	p.texture = p.anim
+	p.own = p.anim
+	return p
+}
+
+// LoadFromFile loads an animated image and starts it from the beginning.
+func (p *AnimatedPixmap) LoadFromFile(file string) {
+	err := p.anim.LoadFromFile(file)
+	if err != nil {
+		log.Println("Failed to load image to an AnimatedPixmap:", err)
+		return
+	}
+	p.texture = p.anim
+	p.position = 0
+}
+
+// SetImage sets an animated image, already decoded, and starts it from the
+// beginning.
+func (p *AnimatedPixmap) SetImage(img *animated.Image) {
+	err := p.anim.SetImage(img)
+	if err != nil {
+		log.Println("Failed to set image of an AnimatedPixmap:", err)
+		return
+	}
+	p.texture = p.anim
+	p.position = 0
+}
+
+// Image returns the animated image or nil.
+func (p *AnimatedPixmap) Image() *animated.Image {
+	return p.anim.Image()
+}
+
+// Play continues playing the animation.
+func (p *AnimatedPixmap) Play() {
+	p.playing = true
+}
+
+// Pause stops the animation at the current frame.
+func (p *AnimatedPixmap) Pause() {
+	p.playing = false
+}
+
+// Playing returns true if the animation isn't paused.
+func (p *AnimatedPixmap) Playing() bool {
+	return p.playing
+}
+
+// SetPosition jumps to the given time since the start of the animation.
+func (p *AnimatedPixmap) SetPosition(t time.Duration) {
+	if t < 0 {
+		t = 0
+	}
+	p.position = t
+	p.showFrame()
+}
+
+// Position returns time since the start of the animation.
+func (p *AnimatedPixmap) Position() time.Duration {
+	return p.position
+}
+
+// Length returns how long a single loop of the animation takes.
+func (p *AnimatedPixmap) Length() time.Duration {
+	if img := p.anim.Image(); img != nil {
+		return img.Length()
+	}
+	return 0
+}
+
+// Frame returns index of the frame shown.
+func (p *AnimatedPixmap) Frame() int {
+	return p.anim.Frame()
+}
+
+// SetOnFinished sets a function called, when the animation has played all of
+// its loops. Endless animations never finish.
+func (p *AnimatedPixmap) SetOnFinished(f func()) {
+	p.onFinished = f
+}
+
+// Update moves the animation dt forward, unless it's paused.
+func (p *AnimatedPixmap) Update(dt time.Duration) {
+	img := p.anim.Image()
+	if !p.playing || img == nil || dt <= 0 {
+		return
+	}
+
+	finished := img.Finished(p.position)
+	p.position += dt
+	p.showFrame()
+	if !finished && img.Finished(p.position) && p.onFinished != nil {
+		p.onFinished()
+	}
+}
+
+// showFrame shows the frame at the current position.
+func (p *AnimatedPixmap) showFrame() {
+	img := p.anim.Image()
+	if img == nil {
+		return
+	}
+	if err := p.anim.SetFrame(img.FrameAt(p.position)); err != nil {
+		log.Println("Failed to show a frame of an AnimatedPixmap:", err)
+	}
+}
+
+// Duration returns animation.Infinite: AnimatedPixmap may be paused for any
+// time, so a player never removes it.
+func (p *AnimatedPixmap) Duration() time.Duration {
+	return animation.Infinite
+}
+
+// Seek is called by an animation player with time since it started playing
+// the AnimatedPixmap. Only the time passed since the previous call matters,
+// it's given to Update.
+func (p *AnimatedPixmap) Seek(t time.Duration) {
+	if t < p.last {
+		p.last = t
+	}
+	dt := t - p.last
+	p.last = t
+	p.Update(dt)
+}
+
+// Rewind brings the animation to its start.
+func (p *AnimatedPixmap) Rewind() {
+	p.last = 0
+	p.SetPosition(0)
+}
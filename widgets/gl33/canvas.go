@@ -0,0 +1,578 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"log"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+
+	g "github.com/Sergobot/Rocky/geometry"
+	"github.com/Sergobot/Rocky/opengl"
+	"github.com/Sergobot/Rocky/opengl/gl33"
+	"github.com/Sergobot/Rocky/paint"
+	"github.com/Sergobot/Rocky/widgets/basic"
+)
+
+// canvasFloats is the number of floats per canvas vertex: position (X, Y),
+// texture coordinates (U, V) and color (R, G, B, A).
+const canvasFloats = 8
+
+var canvasLayout = gl33.VertexLayout{
+	Stride: canvasFloats,
+	Attribs: []gl33.VertexAttrib{
+		{Name: "vert", Size: 2, Offset: 0},
+		{Name: "vertTexCoord", Size: 2, Offset: 2},
+		{Name: "vertColor", Size: 4, Offset: 4},
+	},
+}
+
+// canvasState is what Save and Restore keep.
+type canvasState struct {
+	transform g.Transform
+
+	// Clip region, convex polygons in canvas pixels, which don't overlap. It's
+	// only used, if clipped is true, and may be empty then.
+	clip    [][]g.PointF
+	clipped bool
+
+	fill, stroke paint.Color
+	fillRule     paint.FillRule
+	lineWidth    float32
+	lineCap      paint.Cap
+	lineJoin     paint.Join
+	alpha        float32
+
+	font     *paint.Font
+	fontSize float32
+}
+
+// canvasBatch is a part of the drawing list drawn with the same texture.
+type canvasBatch struct {
+	// Texture of images, or font for text. Both are nil for plain colors.
+	tex  opengl.Texture
+	font *paint.Font
+
+	first, count int
+}
+
+// Canvas is a widget to draw on, like HTML canvas: fill and stroke rects and
+// paths, draw images and text, with transforms and clipping. Everything drawn
+// is tessellated into triangles right away and kept in a drawing list, which
+// is drawn every frame, until the canvas is cleared.
+// Coordinates are in pixels, with origin at the top-left corner of the widget.
+type Canvas struct {
+	Widget
+
+	state canvasState
+	saved []canvasState
+
+	// Drawing list: vertices and batches of them
+	verts   []float32
+	batches []canvasBatch
+	// Vertices have to be uploaded again
+	dirty bool
+
+	vao, vbo uint32
+	program  *gl33.ShaderProgram
+	// Textures of fonts used, they are made of font images when drawn
+	fonts map[*paint.Font]uint32
+
+	ready bool
+}
+
+// NewCanvas returns an empty Canvas, which fills with white, strokes with
+// black lines of a pixel wide and writes with the default font.
+func NewCanvas() *Canvas {
+	c := &Canvas{fonts: map[*paint.Font]uint32{}}
+	c.state = canvasState{
+		transform: g.Identity(),
+		fill:      paint.White,
+		stroke:    paint.Black,
+		lineWidth: 1,
+		alpha:     1,
+		font:      paint.DefaultFont(),
+	}
+	c.state.fontSize = float32(c.state.font.LineHeight)
+	return c
+}
+
+// Clear empties the drawing list. Drawing state (colors, transform and so on)
+// is kept.
+func (c *Canvas) Clear() {
+	c.verts = c.verts[:0]
+	c.batches = c.batches[:0]
+	c.dirty = true
+}
+
+// Save pushes drawing state to a stack.
+func (c *Canvas) Save() {
+	// Polygons of the clip region are never changed, only replaced
+	c.saved = append(c.saved, c.state)
+}
+
+// Restore pops drawing state, pushed by Save, from the stack.
+func (c *Canvas) Restore() {
+	if len(c.saved) == 0 {
+		return
+	}
+	c.state = c.saved[len(c.saved)-1]
+	c.saved = c.saved[:len(c.saved)-1]
+}
+
+// Translate moves everything drawn afterwards by (x, y).
+func (c *Canvas) Translate(x, y float32) {
+	c.state.transform = c.state.transform.Mul(g.Translation(x, y))
+}
+
+// Scale scales everything drawn afterwards.
+func (c *Canvas) Scale(x, y float32) {
+	c.state.transform = c.state.transform.Mul(g.Scaling(x, y))
+}
+
+// Rotate rotates everything drawn afterwards around the origin, clockwise by
+// angle in radians.
+func (c *Canvas) Rotate(angle float32) {
+	c.state.transform = c.state.transform.Mul(g.Rotation(angle, g.PointF{}))
+}
+
+// SetTransform replaces the current transform.
+func (c *Canvas) SetTransform(t g.Transform) {
+	c.state.transform = t
+}
+
+// Transform returns the current transform.
+func (c *Canvas) Transform() g.Transform {
+	return c.state.transform
+}
+
+// Clip limits drawing to a rect, transformed with the current transform, and
+// the previous clip region. There is no way to widen the clip region other
+// than Restore.
+func (c *Canvas) Clip(r g.RectF) {
+	var p paint.Path
+	p.Rect(r)
+	c.ClipPath(&p)
+}
+
+// ClipPath limits drawing to the inside of a path according to the fill rule,
+// transformed with the current transform, and the previous clip region.
+func (c *Canvas) ClipPath(p *paint.Path) {
+	region := paint.Region(p, c.state.fillRule)
+	for _, poly := range region {
+		for i := range poly {
+			poly[i] = c.state.transform.Apply(poly[i])
+		}
+	}
+	if !c.state.clipped {
+		c.state.clip, c.state.clipped = region, true
+		return
+	}
+
+	var clip [][]g.PointF
+	for _, poly := range region {
+		subject := make([]paint.Vertex, len(poly))
+		for i, p := range poly {
+			subject[i].Pos = p
+		}
+		for _, piece := range c.state.clip {
+			res := paint.ClipPolygon(subject, piece)
+			if len(res) < 3 {
+				continue
+			}
+			pts := make([]g.PointF, len(res))
+			for i, v := range res {
+				pts[i] = v.Pos
+			}
+			clip = append(clip, pts)
+		}
+	}
+	c.state.clip = clip
+}
+
+// SetFillColor sets the color shapes and text are filled with.
+func (c *Canvas) SetFillColor(col paint.Color) {
+	c.state.fill = col
+}
+
+// FillColor returns the color shapes and text are filled with.
+func (c *Canvas) FillColor() paint.Color {
+	return c.state.fill
+}
+
+// SetFillRule sets the rule FillPath and ClipPath use to tell the inside of
+// paths.
+func (c *Canvas) SetFillRule(rule paint.FillRule) {
+	c.state.fillRule = rule
+}
+
+// FillRule returns the rule FillPath and ClipPath use to tell the inside of
+// paths.
+func (c *Canvas) FillRule() paint.FillRule {
+	return c.state.fillRule
+}
+
+// SetStrokeColor sets the color of lines.
+func (c *Canvas) SetStrokeColor(col paint.Color) {
+	c.state.stroke = col
+}
+
+// StrokeColor returns the color of lines.
+func (c *Canvas) StrokeColor() paint.Color {
+	return c.state.stroke
+}
+
+// SetLineWidth sets width of lines in pixels.
+func (c *Canvas) SetLineWidth(w float32) {
+	c.state.lineWidth = w
+}
+
+// LineWidth returns width of lines in pixels.
+func (c *Canvas) LineWidth() float32 {
+	return c.state.lineWidth
+}
+
+// SetLineCap sets how ends of lines are drawn.
+func (c *Canvas) SetLineCap(cap paint.Cap) {
+	c.state.lineCap = cap
+}
+
+// LineCap returns how ends of lines are drawn.
+func (c *Canvas) LineCap() paint.Cap {
+	return c.state.lineCap
+}
+
+// SetLineJoin sets how corners of lines are drawn.
+func (c *Canvas) SetLineJoin(join paint.Join) {
+	c.state.lineJoin = join
+}
+
+// LineJoin returns how corners of lines are drawn.
+func (c *Canvas) LineJoin() paint.Join {
+	return c.state.lineJoin
+}
+
+// SetGlobalAlpha sets opacity of everything drawn afterwards.
+func (c *Canvas) SetGlobalAlpha(a float32) {
+	c.state.alpha = a
+}
+
+// GlobalAlpha returns opacity of everything drawn.
+func (c *Canvas) GlobalAlpha() float32 {
+	return c.state.alpha
+}
+
+// SetFont sets the font of text and its size: height of a line in pixels.
+// Nil font means the default one.
+func (c *Canvas) SetFont(f *paint.Font, size float32) {
+	if f == nil {
+		f = paint.DefaultFont()
+	}
+	c.state.font, c.state.fontSize = f, size
+}
+
+// Font returns the font of text and its size.
+func (c *Canvas) Font() (*paint.Font, float32) {
+	return c.state.font, c.state.fontSize
+}
+
+// FillRect fills a rect with the fill color.
+func (c *Canvas) FillRect(r g.RectF) {
+	var p paint.Path
+	p.Rect(r)
+	c.FillPath(&p)
+}
+
+// StrokeRect outlines a rect with a line.
+func (c *Canvas) StrokeRect(r g.RectF) {
+	var p paint.Path
+	p.Rect(r)
+	c.StrokePath(&p)
+}
+
+// FillPath fills the inside of a path according to the fill rule with the
+// fill color. Subpaths are closed implicitly.
+func (c *Canvas) FillPath(p *paint.Path) {
+	c.addTriangles(paint.FillPath(p, c.state.fillRule), c.state.fill)
+}
+
+// StrokePath draws lines along a path.
+func (c *Canvas) StrokePath(p *paint.Path) {
+	s := &c.state
+	for _, sub := range p.Subpaths() {
+		c.addTriangles(paint.Stroke(sub.Points, sub.Closed, s.lineWidth, s.lineCap, s.lineJoin), s.stroke)
+	}
+}
+
+// DrawImage draws the whole texture into dst.
+func (c *Canvas) DrawImage(tex opengl.Texture, dst g.RectF) {
+	if tex == nil {
+		return
+	}
+	size := tex.Size()
+	c.DrawImagePart(tex, g.Rect{Size: size}, dst)
+}
+
+// DrawImagePart draws src part of the texture, in its pixels, into dst.
+func (c *Canvas) DrawImagePart(tex opengl.Texture, src g.Rect, dst g.RectF) {
+	if tex == nil || !tex.Ready() {
+		log.Println("Prevented drawing an empty texture on Canvas")
+		return
+	}
+	if tex.Version() != gl33.Version {
+		log.Printf("Wrong texture version: %v expected, %v provided", gl33.Version, tex.Version())
+		return
+	}
+
+	size := tex.Size()
+	uv := g.RectF{
+		PosF:  g.PosF{X: float32(src.X) / float32(size.W), Y: float32(src.Y) / float32(size.H)},
+		SizeF: g.SizeF{W: float32(src.W) / float32(size.W), H: float32(src.H) / float32(size.H)},
+	}
+	// Images aren't tinted, only made transparent with global alpha
+	c.add(quad(dst, uv), tex, nil, paint.White)
+}
+
+// DrawText writes text with its top-left corner at p. Lines are separated with
+// '\n'.
+func (c *Canvas) DrawText(s string, p g.PointF) {
+	f := c.state.font
+	scale := c.state.fontSize / float32(f.LineHeight)
+	img := f.Image.Bounds()
+
+	var tris []paint.Vertex
+	pen := p
+	for _, r := range s {
+		if r == '\n' {
+			pen.X = p.X
+			pen.Y += float32(f.LineHeight) * scale
+			continue
+		}
+		glyph := f.Glyph(r)
+		// Glyphs have a pixel of space above them in a line
+		dst := g.RectF{
+			PosF:  g.PosF{X: pen.X, Y: pen.Y + scale},
+			SizeF: g.SizeF{W: float32(glyph.W) * scale, H: float32(glyph.H) * scale},
+		}
+		uv := g.RectF{
+			PosF:  g.PosF{X: float32(glyph.X) / float32(img.Dx()), Y: float32(glyph.Y) / float32(img.Dy())},
+			SizeF: g.SizeF{W: float32(glyph.W) / float32(img.Dx()), H: float32(glyph.H) / float32(img.Dy())},
+		}
+		tris = append(tris, quad(dst, uv)...)
+		pen.X += float32(f.Advance) * scale
+	}
+	c.add(tris, nil, f, c.state.fill)
+}
+
+// MeasureText returns size of a text written with the current font, without
+// the current transform applied.
+func (c *Canvas) MeasureText(s string) g.SizeF {
+	scale := c.state.fontSize / float32(c.state.font.LineHeight)
+	size := c.state.font.Measure(s)
+	return g.SizeF{W: float32(size.W) * scale, H: float32(size.H) * scale}
+}
+
+// quad returns two triangles covering dst with uv part of a texture.
+func quad(dst, uv g.RectF) []paint.Vertex {
+	v := func(x, y float32) paint.Vertex {
+		return paint.Vertex{
+			Pos: g.PointF{X: dst.X + x*dst.W, Y: dst.Y + y*dst.H},
+			UV:  g.PointF{X: uv.X + x*uv.W, Y: uv.Y + y*uv.H},
+		}
+	}
+	return []paint.Vertex{v(0, 0), v(1, 0), v(1, 1), v(0, 0), v(1, 1), v(0, 1)}
+}
+
+// addTriangles adds untextured triangles of the given color.
+func (c *Canvas) addTriangles(tris []g.PointF, col paint.Color) {
+	verts := make([]paint.Vertex, len(tris))
+	for i, p := range tris {
+		verts[i].Pos = p
+	}
+	c.add(verts, nil, nil, col)
+}
+
+// add transforms and clips triangles and appends them to the drawing list.
+func (c *Canvas) add(tris []paint.Vertex, tex opengl.Texture, font *paint.Font, col paint.Color) {
+	s := &c.state
+	if len(tris) == 0 || s.alpha <= 0 {
+		return
+	}
+
+	for i := range tris {
+		tris[i].Pos = s.transform.Apply(tris[i].Pos)
+	}
+	if s.clipped {
+		var clipped []paint.Vertex
+		for _, piece := range s.clip {
+			clipped = append(clipped, paint.ClipTriangles(tris, piece)...)
+		}
+		tris = clipped
+		if len(tris) == 0 {
+			return
+		}
+	}
+
+	first := len(c.verts) / canvasFloats
+	a := col.A * s.alpha
+	for _, v := range tris {
+		c.verts = append(c.verts, v.Pos.X, v.Pos.Y, v.UV.X, v.UV.Y, col.R, col.G, col.B, a)
+	}
+	c.dirty = true
+
+	// Batches with the same texture go one after another are merged
+	if n := len(c.batches); n > 0 && c.batches[n-1].tex == tex && c.batches[n-1].font == font {
+		c.batches[n-1].count += len(tris)
+		return
+	}
+	c.batches = append(c.batches, canvasBatch{tex: tex, font: font, first: first, count: len(tris)})
+}
+
+// GetReady loads the shader program if needed and generates the buffer the
+// drawing list is stored in.
+func (c *Canvas) GetReady() {
+	if c.ready {
+		return
+	}
+
+	sp, err := Shaders.Program("canvas.vert", "canvas.frag")
+	if err != nil {
+		log.Println("Failed to prepare Canvas shader program:", err)
+		return
+	}
+	c.program = sp
+	if err := sp.CheckLayout(canvasLayout); err != nil {
+		log.Println("Failed to prepare Canvas:", err)
+		return
+	}
+
+	c.vao = gl33.GenVertexArray()
+	gl.BindVertexArray(c.vao)
+	c.vbo = gl33.GenBuffer()
+	gl.BindBuffer(gl.ARRAY_BUFFER, c.vbo)
+	sp.SetupLayout(canvasLayout)
+
+	gl.BindVertexArray(0)
+
+	c.dirty = true
+	c.ready = true
+}
+
+// Draw draws the drawing list.
+func (c *Canvas) Draw() {
+	if !c.ready {
+		log.Println("Prevented drawing a not ready Canvas")
+		return
+	}
+	if len(c.batches) == 0 {
+		return
+	}
+
+	r := c.GlobalGeometry()
+	if r.W <= 0 || r.H <= 0 {
+		return
+	}
+
+	sp := c.program
+	sp.Use()
+
+	// Canvas pixels are moved to the widget's position in window coordinates
+	px := gl33.NormalizedPixelSize()
+	modelMat := windowMatrix().Mul4(mgl32.Translate3D(r.X, r.Y, 0)).Mul4(mgl32.Scale3D(px, px, 1))
+	sp.SetMat4("modelMat", modelMat)
+	// Disabled Canvases are drawn greyed out
+	sp.SetBool("greyed", !basic.IsEnabled(c))
+
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+
+	// Nothing is drawn outside the widget, unless it's rotated: scissor works
+	// in screen space only
+	scissor := basic.DrawTransform().IsIdentity()
+	if scissor {
+		setScissor(r)
+		gl.Enable(gl.SCISSOR_TEST)
+	}
+
+	gl.BindVertexArray(c.vao)
+	if c.dirty {
+		gl.BindBuffer(gl.ARRAY_BUFFER, c.vbo)
+		gl.BufferData(gl.ARRAY_BUFFER, len(c.verts)*4, gl.Ptr(c.verts), gl.DYNAMIC_DRAW)
+		c.dirty = false
+	}
+
+	for _, b := range c.batches {
+		textured := true
+		switch {
+		case b.tex != nil:
+			if err := b.tex.Bind(); err != nil {
+				log.Println("Failed to bind texture while drawing Canvas:", err)
+				continue
+			}
+			sp.SetSampler("tex", b.tex.Unit())
+		case b.font != nil:
+			gl.ActiveTexture(gl.TEXTURE0)
+			gl.BindTexture(gl.TEXTURE_2D, c.fontTexture(b.font))
+			sp.SetSampler("tex", 0)
+		default:
+			textured = false
+		}
+		sp.SetBool("textured", textured)
+		gl.DrawArrays(gl.TRIANGLES, int32(b.first), int32(b.count))
+		gl33.CountDrawCall()
+	}
+	gl.BindVertexArray(0)
+
+	if scissor {
+		gl.Disable(gl.SCISSOR_TEST)
+	}
+}
+
+// fontTexture returns the texture of a font image, uploading it when the font
+// is used the first time.
+func (c *Canvas) fontTexture(f *paint.Font) uint32 {
+	if tex, ok := c.fonts[f]; ok {
+		return tex
+	}
+
+	var tex uint32
+	tex = gl33.GenTexture()
+	gl.BindTexture(gl.TEXTURE_2D, tex)
+	// Bitmap fonts stay crisp, when scaled by a whole number
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+
+	b := f.Image.Bounds()
+	gl.PixelStorei(gl.UNPACK_ROW_LENGTH, int32(f.Image.Stride/4))
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA, int32(b.Dx()), int32(b.Dy()), 0,
+		gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(f.Image.Pix))
+	gl.PixelStorei(gl.UNPACK_ROW_LENGTH, 0)
+
+	c.fonts[f] = tex
+	return tex
+}
+
+// Release frees OpenGL objects of the Canvas, but leaves it in the widget tree.
+// Textures of images drawn aren't deleted, since they are not owned. GetReady
+// makes the objects again.
+func (c *Canvas) Release() {
+	if c.ready {
+		gl33.DeleteVertexArray(&c.vao)
+		gl33.DeleteBuffer(&c.vbo)
+		c.ready = false
+	}
+	for f, tex := range c.fonts {
+		gl33.DeleteTexture(&tex)
+		delete(c.fonts, f)
+	}
+}
+
+// Destroy frees OpenGL objects of the Canvas and removes it from the widget
+// tree.
+func (c *Canvas) Destroy() {
+	c.Release()
+	c.Widget.Destroy()
+}
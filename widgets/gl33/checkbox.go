@@ -0,0 +1,134 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"github.com/Sergobot/Rocky/events"
+	g "github.com/Sergobot/Rocky/geometry"
+	"github.com/Sergobot/Rocky/paint"
+	"github.com/Sergobot/Rocky/theme"
+)
+
+// CheckBox is a square box, which is checked or not. It's toggled by a click,
+// Space, Enter or gamepad's first button. The box is drawn at the left edge of
+// the widget, so the rest of it may be covered with a label.
+type CheckBox struct {
+	Widget
+
+	box  *panel
+	mark *Line
+
+	checked   bool
+	onChanged func(bool)
+
+	// True between a press and a release of the mouse button
+	pressed bool
+}
+
+// NewCheckBox returns a focusable unchecked check box.
+func NewCheckBox() *CheckBox {
+	c := &CheckBox{box: newPanel(), mark: NewLine()}
+	c.SetFocusable(true)
+	c.mark.SetCap(paint.RoundCap)
+	return c
+}
+
+// SetChecked checks or unchecks the box.
+func (c *CheckBox) SetChecked(checked bool) {
+	if checked == c.checked {
+		return
+	}
+	c.checked = checked
+	if c.onChanged != nil {
+		c.onChanged(checked)
+	}
+}
+
+// Checked returns true if the box is checked.
+func (c *CheckBox) Checked() bool {
+	return c.checked
+}
+
+// Toggle checks the box if it's unchecked and vice versa.
+func (c *CheckBox) Toggle() {
+	c.SetChecked(!c.checked)
+}
+
+// SetOnChanged sets a function called every time the box is checked or
+// unchecked.
+func (c *CheckBox) SetOnChanged(f func(bool)) {
+	c.onChanged = f
+}
+
+// HandleEvent toggles the box on clicks and activation keys.
+func (c *CheckBox) HandleEvent(e *events.Event) {
+	c.Widget.HandleEvent(e)
+	if e.Accepted() {
+		return
+	}
+
+	switch {
+	case e.Type == events.MousePress && e.Button == events.MouseLeft:
+		c.pressed = true
+		e.Accept()
+	case isClick(c, e), isActivation(e):
+		c.pressed = false
+		c.Toggle()
+		e.Accept()
+	case e.Type == events.MouseRelease:
+		c.pressed = false
+	}
+}
+
+// state returns the state of the check box for theme's selectors.
+func (c *CheckBox) state() theme.State {
+	st := controlState(&c.Widget)
+	if c.checked {
+		st |= theme.Checked
+	}
+	if c.pressed {
+		st |= theme.Pressed
+	}
+	return st
+}
+
+// GetReady prepares shapes the check box is drawn with.
+func (c *CheckBox) GetReady() {
+	readyParts(c.box, c.mark)
+}
+
+// Draw draws the box and the check mark, if it's checked. Check box's theme
+// type is "CheckBox": the box is filled with Background, or with Accent when
+// it's checked, and the mark is drawn with Foreground.
+func (c *CheckBox) Draw() {
+	r := c.GlobalGeometry()
+	st := c.ResolveStyle("CheckBox", c.state())
+	side := min32(r.W, r.H)
+	box := g.RectF{
+		PosF:  g.PosF{X: r.X, Y: r.Y + (r.H-side)/2},
+		SizeF: g.SizeF{W: side, H: side},
+	}
+
+	fill := *st.Background
+	if c.checked {
+		fill = *st.Accent
+	}
+	c.box.draw(box, fill, st)
+
+	if c.checked {
+		c.mark.SetColor(*st.Foreground)
+		c.mark.SetThickness(side / 8)
+		c.mark.SetPoints([]g.PointF{
+			{X: side * 0.25, Y: side * 0.5},
+			{X: side * 0.43, Y: side * 0.7},
+			{X: side * 0.75, Y: side * 0.3},
+		})
+		drawAt(c.mark, box)
+	}
+}
+
+// Destroy frees the shapes and removes the check box from the widget tree.
+func (c *CheckBox) Destroy() {
+	destroyParts(c.box, c.mark)
+	c.Widget.Destroy()
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"log"
+
+	"github.com/Sergobot/Rocky/events"
+	g "github.com/Sergobot/Rocky/geometry"
+	"github.com/Sergobot/Rocky/opengl/gl33"
+	"github.com/Sergobot/Rocky/paint"
+	"github.com/Sergobot/Rocky/theme"
+	"github.com/Sergobot/Rocky/widgets/basic"
+)
+
+// Controls draw their parts with shapes, which aren't attached to any widget
+// tree. Geometry of such a shape is in window coordinates.
+type part interface {
+	GetReady()
+	Draw()
+	SetGeometry(g.RectF)
+	Destroy()
+}
+
+// drawAt moves a part to the given geometry in window coordinates and draws it.
+func drawAt(p part, r g.RectF) {
+	p.SetGeometry(r)
+	p.Draw()
+}
+
+// resource is anything holding OpenGL objects: parts and panels.
+type resource interface {
+	GetReady()
+	Destroy()
+}
+
+func readyParts(parts ...resource) {
+	for _, p := range parts {
+		p.GetReady()
+	}
+}
+
+func destroyParts(parts ...resource) {
+	for _, p := range parts {
+		p.Destroy()
+	}
+}
+
+// controlState returns the theme state common for all the controls: whether
+// the control is focused and whether it's disabled.
+func controlState(w *Widget) theme.State {
+	st := theme.Normal
+	if w.HasFocus() {
+		st |= theme.Focused
+	}
+	if !basic.IsEnabled(w) {
+		st |= theme.Disabled
+	}
+	return st
+}
+
+// applyStyle sets colors, border and corner radius of a rectangle drawn in r
+// from a style. fill is one of style's colors, depending on what the rectangle
+// is for.
+func applyStyle(rect *Rectangle, r g.RectF, fill paint.Color, st theme.Style) {
+	rect.SetColor(fill)
+	rect.SetBorder(*st.BorderWidth*gl33.NormalizedPixelSize(), *st.BorderColor)
+	rect.SetRadii(paint.UniformRadii(*st.Radius * min32(r.W, r.H)))
+}
+
+// panel is a background of a control: a rectangle styled by the theme, or a
+// nine-patch, if the theme sets a skin.
+type panel struct {
+	rect  *Rectangle
+	patch *NinePatch
+}
+
+func newPanel() *panel {
+	return &panel{rect: NewRectangle(), patch: NewNinePatch()}
+}
+
+func (p *panel) GetReady() {
+	readyParts(p.rect, p.patch)
+}
+
+func (p *panel) Destroy() {
+	destroyParts(p.rect, p.patch)
+}
+
+// draw draws the panel in r with a style. fill is used if there is no skin.
+func (p *panel) draw(r g.RectF, fill paint.Color, st theme.Style) {
+	if tex := skinTexture(st.Skin.Image); tex != nil {
+		p.patch.texture = tex
+		p.patch.SetBorder(st.Skin.Border)
+		drawAt(p.patch, r)
+		return
+	}
+
+	applyStyle(p.rect, r, fill, st)
+	drawAt(p.rect, r)
+}
+
+// skins are textures of skin images loaded so far. Images failed to load are
+// stored as nil, so they are not loaded every frame.
+var skins = map[string]*gl33.Texture{}
+
+// skinTexture returns a texture of a skin image, loading it if needed. It
+// returns nil if there is no image or it failed to load.
+func skinTexture(image string) *gl33.Texture {
+	if image == "" {
+		return nil
+	}
+
+	tex, ok := skins[image]
+	if !ok {
+		tex = new(gl33.Texture)
+		if err := tex.LoadFromFile(image); err != nil {
+			log.Println("Failed to load skin:", err)
+			tex = nil
+		}
+		skins[image] = tex
+	}
+	return tex
+}
+
+// releaseSkins deletes textures of skins. They are loaded again, when needed.
+func releaseSkins() {
+	for image, tex := range skins {
+		if tex != nil {
+			tex.Release()
+		}
+		delete(skins, image)
+	}
+}
+
+// drawItem draws a node which isn't attached to any widget tree, like a
+// dropdown item, with all its children at the given geometry.
+func drawItem(n basic.Node, r g.RectF) {
+	n.SetGeometry(r)
+	basic.DrawTree(n)
+}
+
+// isActivation returns true if the event is a key press activating a control:
+// Space, Enter or gamepad's first button, which works as Enter.
+func isActivation(e *events.Event) bool {
+	if e.Type != events.KeyPress {
+		return false
+	}
+	return e.Key == events.KeySpace || e.Key == events.KeyEnter || e.Key == events.KeyKPEnter
+}
+
+// isClick returns true if the event is a release of the left mouse button
+// inside of the node. Controls accept presses to grab the mouse, and react to
+// releases, so a click may be cancelled by moving the cursor away.
+func isClick(n basic.Node, e *events.Event) bool {
+	return e.Type == events.MouseRelease && e.Button == events.MouseLeft && basic.Contains(n, e.Pos)
+}
+
+func clamp32(v, min, max float32) float32 {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+func min32(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
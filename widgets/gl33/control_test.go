@@ -0,0 +1,76 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"testing"
+
+	"github.com/Sergobot/Rocky/events"
+	g "github.com/Sergobot/Rocky/geometry"
+)
+
+func key(k events.Key) *events.Event {
+	return &events.Event{Type: events.KeyPress, Key: k}
+}
+
+func TestSlider(t *testing.T) {
+	s := NewSlider()
+	s.SetRange(0, 10)
+	s.SetStep(2)
+
+	var changes []float32
+	s.SetOnChanged(func(v float32) { changes = append(changes, v) })
+
+	s.SetValue(4.9)
+	if s.Value() != 4 {
+		t.Errorf("SetValue(4.9) with step 2 = %v, want 4", s.Value())
+	}
+
+	s.HandleEvent(key(events.KeyRight))
+	s.HandleEvent(key(events.KeyEnd))
+	if s.Value() != 10 {
+		t.Errorf("Value() after End = %v, want 10", s.Value())
+	}
+
+	// At the end the key is left for focus navigation
+	e := key(events.KeyRight)
+	s.HandleEvent(e)
+	if e.Accepted() {
+		t.Errorf("Right accepted at the maximum")
+	}
+
+	// Dragging: the knob is as wide as the slider is tall
+	s.SetGeometry(g.RectF{SizeF: g.SizeF{W: 1.1, H: 0.1}})
+	s.HandleEvent(&events.Event{Type: events.MousePress, Pos: g.PointF{X: 0.3, Y: 0.05}})
+	if s.Value() != 2 {
+		t.Errorf("Value() after press = %v, want 2", s.Value())
+	}
+
+	if want := []float32{4, 6, 10, 2}; len(changes) != len(want) {
+		t.Errorf("changes = %v, want %v", changes, want)
+	}
+}
+
+func TestRadioGroup(t *testing.T) {
+	r := NewRadioGroup()
+	r.SetGeometry(g.RectF{SizeF: g.SizeF{W: 1, H: 0.3}})
+	for i := 0; i < 3; i++ {
+		r.AddOption(nil)
+	}
+
+	r.HandleEvent(key(events.KeyDown))
+	r.HandleEvent(key(events.KeyDown))
+	if r.Selected() != 1 {
+		t.Errorf("Selected() = %v, want 1", r.Selected())
+	}
+
+	r.HandleEvent(&events.Event{Type: events.MouseRelease, Pos: g.PointF{X: 0.5, Y: 0.25}})
+	if r.Selected() != 2 {
+		t.Errorf("Selected() after click = %v, want 2", r.Selected())
+	}
+	e := key(events.KeyDown)
+	r.HandleEvent(e)
+	if e.Accepted() {
+		t.Errorf("Down accepted at the last option")
+	}
+}
@@ -0,0 +1,309 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"log"
+
+	"github.com/Sergobot/Rocky/events"
+	g "github.com/Sergobot/Rocky/geometry"
+	"github.com/Sergobot/Rocky/paint"
+	"github.com/Sergobot/Rocky/theme"
+	"github.com/Sergobot/Rocky/widgets/basic"
+	"github.com/Sergobot/Rocky/widgets/overlay"
+)
+
+// Dropdown is a box showing the selected item of a list. A click, Space, Enter
+// or gamepad's first button opens the list in a popup below the box, where
+// another item may be chosen with the mouse or with Up, Down and Enter keys.
+// Items are any widgets, for example Pixmaps with some text. They don't belong
+// to any widget tree: the dropdown draws them itself.
+type Dropdown struct {
+	Widget
+
+	box   *panel
+	arrow *Line
+
+	items     []basic.Node
+	selected  int
+	onChanged func(int)
+
+	list  *dropdownList
+	popup *overlay.Layer
+
+	ready bool
+}
+
+// NewDropdown returns a focusable dropdown without items.
+func NewDropdown() *Dropdown {
+	d := &Dropdown{box: newPanel(), arrow: NewLine(), selected: -1}
+	d.SetFocusable(true)
+	d.list = newDropdownList(d)
+	d.arrow.SetCap(paint.RoundCap)
+	return d
+}
+
+// AddItem adds an item to the list and returns its index. The first item
+// added is selected.
+func (d *Dropdown) AddItem(item basic.Node) int {
+	d.items = append(d.items, item)
+	if d.ready {
+		item.GetReady()
+	}
+	if d.selected < 0 {
+		d.SetSelected(0)
+	}
+	return len(d.items) - 1
+}
+
+// Items returns all the items of the list.
+func (d *Dropdown) Items() []basic.Node {
+	return d.items
+}
+
+// SetSelected selects the item with the given index. Pass -1 to clear the
+// selection.
+func (d *Dropdown) SetSelected(i int) {
+	if i < -1 || i >= len(d.items) || i == d.selected {
+		return
+	}
+	d.selected = i
+	if d.onChanged != nil {
+		d.onChanged(i)
+	}
+}
+
+// Selected returns index of the selected item or -1.
+func (d *Dropdown) Selected() int {
+	return d.selected
+}
+
+// SetOnChanged sets a function called every time another item is selected.
+func (d *Dropdown) SetOnChanged(f func(int)) {
+	d.onChanged = f
+}
+
+// Open shows the list in a popup. The dropdown has to be in a window.
+func (d *Dropdown) Open() {
+	if d.popup != nil || len(d.items) == 0 {
+		return
+	}
+
+	s := overlay.Of(d)
+	if s == nil {
+		log.Println("Failed to open a dropdown: it's not in a window")
+		return
+	}
+
+	r := d.GlobalGeometry()
+	d.list.SetSize(g.SizeF{W: r.W, H: r.H * float32(len(d.items))})
+	d.list.highlighted = d.selected
+
+	d.popup = s.Popup(d.list, r, overlay.Below)
+	d.popup.OnClose = func() {
+		d.popup = nil
+	}
+}
+
+// Close hides the list, if it's shown.
+func (d *Dropdown) Close() {
+	if d.popup != nil {
+		d.popup.Close()
+	}
+}
+
+// IsOpen returns true if the list is shown.
+func (d *Dropdown) IsOpen() bool {
+	return d.popup != nil
+}
+
+// HandleEvent opens the list on clicks and activation keys.
+func (d *Dropdown) HandleEvent(e *events.Event) {
+	d.Widget.HandleEvent(e)
+	if e.Accepted() {
+		return
+	}
+
+	switch {
+	case e.Type == events.MousePress && e.Button == events.MouseLeft:
+		d.Open()
+		e.Accept()
+	case isActivation(e):
+		d.Open()
+		e.Accept()
+	}
+}
+
+// GetReady prepares shapes the dropdown is drawn with and all its items.
+func (d *Dropdown) GetReady() {
+	readyParts(d.box, d.arrow)
+	for _, it := range d.items {
+		it.GetReady()
+	}
+	d.ready = true
+}
+
+// Draw draws the box, the selected item in it and an arrow at the right edge.
+// Dropdown's theme type is "Dropdown": the box is filled with Background, the
+// arrow is drawn with Foreground and the item has Padding around it. The list
+// is "DropdownList", the highlighted item is marked with Accent.
+func (d *Dropdown) Draw() {
+	r := d.GlobalGeometry()
+
+	state := controlState(&d.Widget)
+	if d.IsOpen() {
+		state |= theme.Pressed
+	}
+	st := d.ResolveStyle("Dropdown", state)
+
+	d.box.draw(r, *st.Background, st)
+
+	if d.selected >= 0 {
+		drawItem(d.items[d.selected], st.Padding.Shrink(g.RectF{
+			PosF:  r.PosF,
+			SizeF: g.SizeF{W: r.W - r.H, H: r.H},
+		}))
+	}
+
+	// Arrow is a chevron in a square at the right edge
+	h := r.H
+	d.arrow.SetColor(*st.Foreground)
+	d.arrow.SetThickness(h / 12)
+	d.arrow.SetPoints([]g.PointF{
+		{X: h * 0.3, Y: h * 0.4},
+		{X: h * 0.5, Y: h * 0.6},
+		{X: h * 0.7, Y: h * 0.4},
+	})
+	drawAt(d.arrow, g.RectF{
+		PosF:  g.PosF{X: r.X + r.W - h, Y: r.Y},
+		SizeF: g.SizeF{W: h, H: h},
+	})
+}
+
+// Destroy closes the list, frees the shapes, destroys all the items and
+// removes the dropdown from the widget tree.
+func (d *Dropdown) Destroy() {
+	d.Close()
+	d.list.Destroy()
+	destroyParts(d.box, d.arrow)
+	for _, it := range d.items {
+		it.Destroy()
+	}
+	d.items = nil
+	d.Widget.Destroy()
+}
+
+// dropdownList is the content of dropdown's popup: a column of items with
+// the highlighted one marked.
+type dropdownList struct {
+	Widget
+
+	dropdown   *Dropdown
+	background *panel
+	bar        *Rectangle
+
+	highlighted int
+}
+
+func newDropdownList(d *Dropdown) *dropdownList {
+	l := &dropdownList{dropdown: d, background: newPanel(), bar: NewRectangle()}
+	l.SetFocusable(true)
+	return l
+}
+
+// rowHeight returns height of a single item, which is the height of the box.
+func (l *dropdownList) rowHeight() float32 {
+	return l.dropdown.Size().H
+}
+
+// itemAt returns index of the item under a point in window coordinates or -1.
+func (l *dropdownList) itemAt(p g.PointF) int {
+	h := l.rowHeight()
+	if h <= 0 || !basic.Contains(l, p) {
+		return -1
+	}
+	i := int(l.MapFromGlobal(p).Y / h)
+	if i >= len(l.dropdown.items) {
+		return -1
+	}
+	return i
+}
+
+// choose selects an item in the dropdown and closes the popup.
+func (l *dropdownList) choose(i int) {
+	if i >= 0 {
+		l.dropdown.SetSelected(i)
+	}
+	l.dropdown.Close()
+}
+
+// HandleEvent highlights items under the cursor and chooses them on clicks.
+// Up and Down keys move the highlight, activation keys choose the item.
+// Escape is handled by the overlay, which closes the popup.
+func (l *dropdownList) HandleEvent(e *events.Event) {
+	switch e.Type {
+	case events.MouseMove:
+		if i := l.itemAt(e.Pos); i >= 0 {
+			l.highlighted = i
+		}
+		e.Accept()
+	case events.MousePress:
+		e.Accept()
+	case events.MouseRelease:
+		if isClick(l, e) {
+			l.choose(l.itemAt(e.Pos))
+		}
+		e.Accept()
+	case events.KeyPress, events.KeyRepeat:
+		n := len(l.dropdown.items)
+		switch {
+		case e.Key == events.KeyUp && l.highlighted > 0:
+			l.highlighted--
+			e.Accept()
+		case e.Key == events.KeyDown && l.highlighted < n-1:
+			l.highlighted++
+			e.Accept()
+		case e.Key == events.KeyUp, e.Key == events.KeyDown:
+			// Focus mustn't leave the list
+			e.Accept()
+		case isActivation(e):
+			l.choose(l.highlighted)
+			e.Accept()
+		}
+	}
+}
+
+// GetReady prepares shapes the list is drawn with. Items are prepared by the
+// dropdown.
+func (l *dropdownList) GetReady() {
+	readyParts(l.background, l.bar)
+}
+
+// Draw draws the background, the highlight and all the items.
+func (l *dropdownList) Draw() {
+	r := l.GlobalGeometry()
+	h := l.rowHeight()
+	st := l.dropdown.ResolveStyle("DropdownList", theme.Normal)
+
+	l.background.draw(r, *st.Background, st)
+	if l.highlighted >= 0 {
+		l.bar.SetColor(*st.Accent)
+		drawAt(l.bar, g.RectF{
+			PosF:  g.PosF{X: r.X, Y: r.Y + float32(l.highlighted)*h},
+			SizeF: g.SizeF{W: r.W, H: h},
+		})
+	}
+
+	for i, it := range l.dropdown.items {
+		drawItem(it, st.Padding.Shrink(g.RectF{
+			PosF:  g.PosF{X: r.X, Y: r.Y + float32(i)*h},
+			SizeF: g.SizeF{W: r.W, H: h},
+		}))
+	}
+}
+
+// Destroy frees the shapes. Items are destroyed by the dropdown.
+func (l *dropdownList) Destroy() {
+	destroyParts(l.background, l.bar)
+	l.Widget.Destroy()
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"log"
+
+	g "github.com/Sergobot/Rocky/geometry"
+	"github.com/Sergobot/Rocky/opengl/gl33"
+	"github.com/Sergobot/Rocky/widgets/scale"
+)
+
+// NinePatch is a Pixmap, which stretches its texture without distorting the
+// borders: corners keep their size, edges are stretched along and the center
+// is stretched both ways. It's how skinned buttons and panels are drawn.
+// Scale mode and alignment of the Pixmap are ignored.
+type NinePatch struct {
+	Pixmap
+
+	// Sizes of the borders in pixels of the texture
+	border g.InsetsF
+}
+
+// NewNinePatch returns a NinePatch with an empty texture and no borders.
+func NewNinePatch() *NinePatch {
+	n := new(NinePatch)
+	n.init()
+	return n
+}
+
+// SetBorder sets sizes of the borders in pixels of the texture.
+func (n *NinePatch) SetBorder(b g.InsetsF) {
+	n.border = b
+}
+
+// Border returns sizes of the borders in pixels of the texture.
+func (n *NinePatch) Border() g.InsetsF {
+	return n.border
+}
+
+// Draw draws the nine parts of the texture.
+func (n *NinePatch) Draw() {
+	if !n.ready {
+		log.Println("Prevented drawing a not ready NinePatch")
+		return
+	}
+
+	// Borders are drawn in their native size, so convert everything from pixels
+	px := gl33.NormalizedPixelSize()
+	texSize := n.texture.Size()
+	img := g.SizeF{W: float32(texSize.W) * px, H: float32(texSize.H) * px}
+	border := g.InsetsF{
+		Left:   n.border.Left * px,
+		Top:    n.border.Top * px,
+		Right:  n.border.Right * px,
+		Bottom: n.border.Bottom * px,
+	}
+
+	dst, src := scale.NinePatch(img, border, n.GlobalGeometry())
+	for i := range dst {
+		n.drawPart(dst[i], src[i], false)
+	}
+}
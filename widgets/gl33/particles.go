@@ -0,0 +1,272 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"log"
+	"time"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+
+	"github.com/Sergobot/Rocky/animation"
+	g "github.com/Sergobot/Rocky/geometry"
+	"github.com/Sergobot/Rocky/opengl"
+	"github.com/Sergobot/Rocky/opengl/gl33"
+	"github.com/Sergobot/Rocky/particles"
+	"github.com/Sergobot/Rocky/widgets/basic"
+)
+
+// particleFloats is the number of floats describing a single particle for the
+// shader: center (X, Y), size, rotation and color (R, G, B, A).
+const particleFloats = 8
+
+// particleLayout takes particles from the instances buffer, advancing once per
+// quad.
+var particleLayout = gl33.VertexLayout{
+	Stride: particleFloats,
+	Attribs: []gl33.VertexAttrib{
+		{Name: "center", Size: 2, Offset: 0, Divisor: 1},
+		{Name: "size", Size: 1, Offset: 2, Divisor: 1},
+		{Name: "rotation", Size: 1, Offset: 3, Divisor: 1},
+		{Name: "color", Size: 4, Offset: 4, Divisor: 1},
+	},
+}
+
+// ParticleEmitter spawns particles from the center of its geometry and draws
+// them all at once with instancing: a single quad is drawn as many times as
+// there are particles. Particles are simulated in window pixels by
+// particles.Emitter and don't follow the widget once spawned.
+// ParticleEmitter is an animation: play it with a window's animation player to
+// make particles move, or call Update every frame.
+type ParticleEmitter struct {
+	Widget
+
+	emitter *particles.Emitter
+
+	// Texture of particles. Without one, particles are soft round dots.
+	texture opengl.Texture
+	// Particles are added to what's under them instead of covering it
+	additive bool
+
+	vao, vbo, ebo uint32
+	program       *gl33.ShaderProgram
+	// Buffer with particles, one particleFloats record each
+	instances uint32
+	// Particles data, reused between frames
+	data []float32
+
+	ready bool
+}
+
+// NewParticleEmitter returns a ParticleEmitter spawning particles as c tells.
+func NewParticleEmitter(c particles.Config) *ParticleEmitter {
+	return &ParticleEmitter{emitter: particles.NewEmitter(c)}
+}
+
+// Emitter returns the emitter simulating particles. Use it to look at
+// particles or to seed random numbers.
+func (p *ParticleEmitter) Emitter() *particles.Emitter {
+	return p.emitter
+}
+
+// SetConfig changes how particles are spawned and how they behave.
+func (p *ParticleEmitter) SetConfig(c particles.Config) {
+	p.emitter.SetConfig(c)
+}
+
+// Config returns config of the emitter.
+func (p *ParticleEmitter) Config() particles.Config {
+	return p.emitter.Config()
+}
+
+// Burst spawns n particles right away.
+func (p *ParticleEmitter) Burst(n int) {
+	p.place()
+	p.emitter.Burst(n)
+}
+
+// SetEmitting starts or stops spawning particles. Particles already spawned
+// live their lives anyway.
+func (p *ParticleEmitter) SetEmitting(emit bool) {
+	p.emitter.SetEmitting(emit)
+}
+
+// Emitting returns true if particles are being spawned.
+func (p *ParticleEmitter) Emitting() bool {
+	return p.emitter.Emitting()
+}
+
+// SetTexture sets the texture particles are drawn with. It's tinted with the
+// color of a particle. Nil makes particles soft round dots.
+func (p *ParticleEmitter) SetTexture(tex opengl.Texture) {
+	if tex == nil {
+		p.texture = nil
+		return
+	}
+	if tex.Version() != gl33.Version {
+		log.Printf("Wrong texture version: %v expected, %v provided", gl33.Version, tex.Version())
+		return
+	}
+
+	if tex.Ready() {
+		p.texture = tex
+	} else {
+		log.Println("Prevented setting an empty texture to ParticleEmitter")
+	}
+}
+
+// Texture returns the texture particles are drawn with, or nil.
+func (p *ParticleEmitter) Texture() opengl.Texture {
+	return p.texture
+}
+
+// SetAdditive turns on additive blending: colors of particles are added to
+// what's drawn under them, which makes fire and sparks glow.
+func (p *ParticleEmitter) SetAdditive(a bool) {
+	p.additive = a
+}
+
+// Additive returns true if particles are blended additively.
+func (p *ParticleEmitter) Additive() bool {
+	return p.additive
+}
+
+// place moves the emitter to the center of the widget, in window pixels.
+func (p *ParticleEmitter) place() {
+	r := p.GlobalGeometry()
+	px := gl33.NormalizedPixelSize()
+	p.emitter.SetPos(g.PointF{X: (r.X + r.W/2) / px, Y: (r.Y + r.H/2) / px})
+}
+
+// Update moves particles dt forward and spawns new ones.
+func (p *ParticleEmitter) Update(dt time.Duration) {
+	p.place()
+	p.emitter.Update(dt)
+}
+
+// Duration returns animation.Infinite: particles may be spawned forever.
+func (p *ParticleEmitter) Duration() time.Duration {
+	return animation.Infinite
+}
+
+// Seek updates particles up to time t since the emitter's start.
+func (p *ParticleEmitter) Seek(t time.Duration) {
+	p.place()
+	p.emitter.Seek(t)
+}
+
+// Rewind kills all the particles and restarts the emitter.
+func (p *ParticleEmitter) Rewind() {
+	p.emitter.Rewind()
+}
+
+// GetReady loads the shader program if needed and generates the quad and
+// the buffer particles are streamed to.
+func (p *ParticleEmitter) GetReady() {
+	if p.ready {
+		return
+	}
+
+	sp, err := Shaders.Program("particle.vert", "particle.frag")
+	if err != nil {
+		log.Println("Failed to prepare particle shader program:", err)
+		return
+	}
+	p.program = sp
+	if err := sp.CheckLayout(particleLayout); err != nil {
+		log.Println("Failed to prepare ParticleEmitter:", err)
+		return
+	}
+
+	p.vao, p.vbo, p.ebo, err = genQuad(sp)
+	if err != nil {
+		log.Println("Failed to prepare ParticleEmitter:", err)
+		return
+	}
+
+	// Per-particle attributes are taken from the instances buffer, advancing
+	// once per quad
+	gl.BindVertexArray(p.vao)
+	p.instances = gl33.GenBuffer()
+	gl.BindBuffer(gl.ARRAY_BUFFER, p.instances)
+	sp.SetupLayout(particleLayout)
+
+	gl.BindVertexArray(0)
+
+	p.ready = true
+}
+
+// Draw draws all the living particles in a single draw call.
+func (p *ParticleEmitter) Draw() {
+	if !p.ready {
+		log.Println("Prevented drawing a not ready ParticleEmitter")
+		return
+	}
+
+	ps := p.emitter.Particles()
+	if len(ps) == 0 {
+		return
+	}
+
+	p.data = p.data[:0]
+	for i := range ps {
+		pt := &ps[i]
+		p.data = append(p.data,
+			pt.Pos.X, pt.Pos.Y, pt.Size, pt.Rotation,
+			pt.Color.R, pt.Color.G, pt.Color.B, pt.Color.A)
+	}
+
+	sp := p.program
+	sp.Use()
+	sp.SetMat4("modelMat", windowMatrix())
+	sp.SetFloat("pixel", gl33.NormalizedPixelSize())
+	// Disabled ParticleEmitters are drawn greyed out
+	sp.SetBool("greyed", !basic.IsEnabled(p))
+
+	textured := false
+	if p.texture != nil {
+		if err := p.texture.Bind(); err != nil {
+			log.Println("Failed to bind texture while drawing ParticleEmitter:", err)
+		} else {
+			textured = true
+			sp.SetSampler("tex", p.texture.Unit())
+		}
+	}
+	sp.SetBool("textured", textured)
+
+	gl.Enable(gl.BLEND)
+	if p.additive {
+		gl.BlendFunc(gl.SRC_ALPHA, gl.ONE)
+	}
+
+	gl.BindVertexArray(p.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, p.instances)
+	// The buffer is reallocated every frame, so the driver doesn't wait for the
+	// previous frame to finish drawing from it
+	gl.BufferData(gl.ARRAY_BUFFER, len(p.data)*4, gl.Ptr(p.data), gl.STREAM_DRAW)
+	gl.DrawElementsInstanced(gl.TRIANGLES, 6, gl.UNSIGNED_INT, nil, int32(len(ps)))
+	gl33.CountDrawCall()
+	gl.BindVertexArray(0)
+
+	if p.additive {
+		gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	}
+}
+
+// Release frees OpenGL objects of the ParticleEmitter, but leaves it in the
+// widget tree. The texture isn't deleted, since it may be shared. GetReady
+// makes the objects again.
+func (p *ParticleEmitter) Release() {
+	if p.ready {
+		deleteQuad(&p.vao, &p.vbo, &p.ebo)
+		gl33.DeleteBuffer(&p.instances)
+		p.ready = false
+	}
+}
+
+// Destroy frees OpenGL objects of the ParticleEmitter and removes it from the
+// widget tree.
+func (p *ParticleEmitter) Destroy() {
+	p.Release()
+	p.Widget.Destroy()
+}
@@ -8,8 +8,12 @@ import (
 	"github.com/go-gl/gl/v3.3-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
 
+	g "github.com/Sergobot/Rocky/geometry"
 	"github.com/Sergobot/Rocky/opengl"
 	"github.com/Sergobot/Rocky/opengl/gl33"
+	"github.com/Sergobot/Rocky/paint"
+	"github.com/Sergobot/Rocky/widgets/basic"
+	"github.com/Sergobot/Rocky/widgets/scale"
 )
 
 // Pixmap is one of the simplest widgets, intended to draw raster images
@@ -22,19 +26,36 @@ type Pixmap struct {
 
 	vao, vbo, ebo uint32
 	texture       opengl.Texture
+	program       *gl33.ShaderProgram
+
+	// Texture made by the Pixmap itself, which is released along with it
+	own opengl.Texture
+
+	// How the texture is fitted into Pixmap's geometry and where it is placed
+	// if there is some free space left.
+	scaleMode scale.Mode
+	alignment g.Alignment
+
+	// Opacity of the whole Pixmap, in range [0, 1]
+	opacity float32
+
+	// Sprite batch the Pixmap is drawn with, if any
+	batch *gl33.SpriteBatch
 
 	ready bool
 }
 
-// init is used to initialize pixmap's matrices and currently nothing more.
+// init is used to initialize pixmap's texture and alignment.
 func (p *Pixmap) init() *Pixmap {
-	p.transMat = mgl32.Ident4()
-	p.scaleMat = mgl32.Ident4()
+	p.texture = new(gl33.Texture)
+	p.own = p.texture
+	p.alignment = g.AlignCenter
+	p.opacity = 1
 	return p
 }
 
 // NewPixmap is used to create initialized Pixmap. For now, that's required
-// to only have identity matrices instead of null ones.
+// to have an empty texture to load images in, centered alignment and full opacity.
 func NewPixmap() *Pixmap { return new(Pixmap).init() }
 
 // LoadFromFile loads a texture from the given image.
@@ -60,6 +81,70 @@ func (p *Pixmap) SetTexture(tex opengl.Texture) {
 	}
 }
 
+// Texture returns texture used by Pixmap.
+func (p *Pixmap) Texture() opengl.Texture {
+	return p.texture
+}
+
+// SetOpacity sets opacity of the Pixmap: 0 is fully transparent, 1 is opaque.
+func (p *Pixmap) SetOpacity(o float32) {
+	p.opacity = o
+}
+
+// Opacity returns opacity of the Pixmap.
+func (p *Pixmap) Opacity() float32 {
+	return p.opacity
+}
+
+// SetScaleMode sets the way texture is fitted into Pixmap's geometry.
+// Default is scale.Stretch. With scale.Tile the texture is switched to
+// gl33.Repeat wrap mode, which affects all the widgets sharing it.
+func (p *Pixmap) SetScaleMode(m scale.Mode) {
+	p.scaleMode = m
+}
+
+// ScaleMode returns current scale mode.
+func (p *Pixmap) ScaleMode() scale.Mode {
+	return p.scaleMode
+}
+
+// SetAlignment sets where texture is placed inside Pixmap's geometry, if it
+// doesn't cover all of it. Default is g.AlignCenter.
+func (p *Pixmap) SetAlignment(a g.Alignment) {
+	p.alignment = a
+}
+
+// Alignment returns current alignment of the texture.
+func (p *Pixmap) Alignment() g.Alignment {
+	return p.alignment
+}
+
+// SetBatch makes the Pixmap add itself to a sprite batch instead of drawing
+// itself, when the batch is between Begin and End. The batch must be begun with
+// gl33.WindowProjection(). Pixmaps are drawn, when the batch is flushed, so
+// they are drawn after widgets not batched, no matter of their order in the
+// tree. Tiled and disabled Pixmaps are always drawn on their own. Nil turns
+// batching off.
+func (p *Pixmap) SetBatch(b *gl33.SpriteBatch) {
+	p.batch = b
+}
+
+// Batch returns the sprite batch the Pixmap is drawn with or nil.
+func (p *Pixmap) Batch() *gl33.SpriteBatch {
+	return p.batch
+}
+
+// placement returns the rect Pixmap's texture is drawn in and the part of the
+// texture to be drawn there, according to scale mode and alignment.
+func (p *Pixmap) placement() (dst, src g.RectF) {
+	// Convert texture size from pixels to the units geometry is measured in
+	px := gl33.NormalizedPixelSize()
+	texSize := p.texture.Size()
+	img := g.SizeF{W: float32(texSize.W) * px, H: float32(texSize.H) * px}
+
+	return scale.Place(p.scaleMode, img, p.GlobalGeometry(), p.alignment)
+}
+
 // GetReady initializes the Pixmap to be ready to Draw() function calls.
 func (p *Pixmap) GetReady() {
 	if p.ready {
@@ -68,51 +153,41 @@ func (p *Pixmap) GetReady() {
 
 	p.ready = false
 
-	if !PixmapShaderProgram.Linked() {
-		var vShader, fShader gl33.Shader
-		if err := vShader.Compile(PixmapVertexShaderSrc, gl33.VertexShader); err != nil {
-			log.Println("Failed to compile Pixmap vertex shader:", err)
-			return
-		}
-		if err := fShader.Compile(PixmapFragmentShaderSrc, gl33.FragmentShader); err != nil {
-			log.Println("Failed to compile Pixmap fragment shader:", err)
-			return
-		}
-		if err := PixmapShaderProgram.Link(vShader, fShader); err != nil {
-			log.Println("Failed to link Pixmap shader program:", err)
-			return
-		}
-	}
-
-	// Here we generate VAO, VBO and EBO
-	gl.GenVertexArrays(1, &p.vao)
-	gl.BindVertexArray(p.vao)
-
-	gl.GenBuffers(1, &p.vbo)
-	gl.BindBuffer(gl.ARRAY_BUFFER, p.vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(widgetVertices)*4, gl.Ptr(widgetVertices), gl.STATIC_DRAW)
-
-	gl.GenBuffers(1, &p.ebo)
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, p.ebo)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(widgetIndices)*4, gl.Ptr(widgetIndices), gl.STATIC_DRAW)
-
-	// And then we load vertices and indices to OpenGL pipeline.
-	vertAttrib := uint32(gl.GetAttribLocation(PixmapShaderProgram.Program(), gl.Str("vert\x00")))
-	gl.EnableVertexAttribArray(vertAttrib)
-	gl.VertexAttribPointer(vertAttrib, 3, gl.FLOAT, false, 5*4, gl.PtrOffset(0))
+	sp, err := pixmapProgram()
+	if err != nil {
+		log.Println("Failed to prepare Pixmap shader program:", err)
+		return
+	}
+	p.program = sp
 
-	texCoordAttrib := uint32(gl.GetAttribLocation(PixmapShaderProgram.Program(), gl.Str("vertTexCoord\x00")))
-	gl.EnableVertexAttribArray(texCoordAttrib)
-	gl.VertexAttribPointer(texCoordAttrib, 2, gl.FLOAT, false, 5*4, gl.PtrOffset(3*4))
+	p.vao, p.vbo, p.ebo, err = genQuad(sp)
+	if err != nil {
+		log.Println("Failed to prepare Pixmap:", err)
+		return
+	}
 
-	gl.BindVertexArray(0)
+	p.ready = true
+}
 
-	// When we have already loaded vertices and indices,
-	// it's time for uniforms, like texture
-	textureUniform := gl.GetUniformLocation(PixmapShaderProgram.Program(), gl.Str("tex\x00"))
-	gl.Uniform1i(textureUniform, 0)
+// Release frees OpenGL objects of the Pixmap and the image loaded with
+// LoadFromFile, but leaves it in the widget tree. Textures set with SetTexture
+// aren't deleted, since they may be shared with other Pixmaps. Load an image
+// and call GetReady to use the Pixmap again.
+func (p *Pixmap) Release() {
+	if p.ready {
+		deleteQuad(&p.vao, &p.vbo, &p.ebo)
+		p.ready = false
+	}
+	if p.own != nil {
+		p.own.Release()
+	}
+}
 
-	p.ready = true
+// Destroy frees OpenGL objects of the Pixmap, see Release, and removes it from
+// the widget tree.
+func (p *Pixmap) Destroy() {
+	p.Release()
+	p.Widget.Destroy()
 }
 
 // Draw draws Pixmap's contents to the screen
@@ -122,13 +197,66 @@ func (p *Pixmap) Draw() {
 		return
 	}
 
-	transMatUniform := gl.GetUniformLocation(PixmapShaderProgram.Program(), gl.Str("transMat\x00"))
-	gl.UniformMatrix4fv(transMatUniform, 1, false, &p.transMat[0])
-	scaleMatUniform := gl.GetUniformLocation(PixmapShaderProgram.Program(), gl.Str("scaleMat\x00"))
-	gl.UniformMatrix4fv(scaleMatUniform, 1, false, &p.scaleMat[0])
+	dst, src := p.placement()
+	p.drawPart(dst, src, p.scaleMode == scale.Tile)
+}
+
+// addToBatch adds a part of the texture (src, in texture coordinates) drawn to
+// dst in window coordinates to the sprite batch.
+func (p *Pixmap) addToBatch(dst, src g.RectF) {
+	if !p.texture.Ready() {
+		return
+	}
+
+	t := basic.DrawTransform()
+	corners := [4]g.PointF{
+		t.Apply(g.PointF{X: dst.X, Y: dst.Y}),
+		t.Apply(g.PointF{X: dst.X + dst.W, Y: dst.Y}),
+		t.Apply(g.PointF{X: dst.X + dst.W, Y: dst.Y + dst.H}),
+		t.Apply(g.PointF{X: dst.X, Y: dst.Y + dst.H}),
+	}
+	col := paint.White
+	col.A = p.opacity
+	p.batch.DrawQuad(p.texture.ID(), corners, src, col, gl33.BlendAlpha)
+}
+
+// repeat makes a texture repeat beyond [0, 1] texture coordinates, so that the
+// texture is tiled by the sampler without seams between tiles. Other options of
+// the texture are kept.
+func repeat(t opengl.Texture) {
+	o := t.Options()
+	if o.WrapS != gl33.Repeat || o.WrapT != gl33.Repeat {
+		o.WrapS, o.WrapT = gl33.Repeat, gl33.Repeat
+		t.SetOptions(o)
+	}
+}
+
+// drawPart draws a part of the texture (src, in texture coordinates) to dst
+// in window coordinates. If tile is true, the texture is repeated when src
+// goes beyond it.
+func (p *Pixmap) drawPart(dst, src g.RectF, tile bool) {
+	if dst.W <= 0 || dst.H <= 0 {
+		// Nothing is visible
+		return
+	}
+	if p.batch != nil && p.batch.Drawing() && !tile && basic.IsEnabled(p) {
+		p.addToBatch(dst, src)
+		return
+	}
+	if tile {
+		repeat(p.texture)
+	}
+	modelMat := rectMatrix(dst)
+
+	sp := p.program
+	sp.Use()
+	sp.SetMat4("modelMat", modelMat)
+	sp.SetVec4("uvRect", mgl32.Vec4{src.X, src.Y, src.W, src.H})
+	sp.SetSampler("tex", p.texture.Unit())
+	sp.SetFloat("opacity", p.opacity)
+	// Disabled Pixmaps are drawn greyed out
+	sp.SetBool("greyed", !basic.IsEnabled(p))
 
-	// Activate shader
-	PixmapShaderProgram.Use()
 	//Bind texture
 	err := p.texture.Bind()
 	if err != nil {
@@ -138,33 +266,41 @@ func (p *Pixmap) Draw() {
 	// Draw container
 	gl.BindVertexArray(p.vao)
 	gl.DrawElements(gl.TRIANGLES, 6, gl.UNSIGNED_INT, nil)
+	gl33.CountDrawCall()
 	gl.BindVertexArray(0)
 }
 
-// PixmapShaderProgram is default shader program for Pixmaps
+// PixmapShaderProgram is the shader program Pixmaps are drawn with instead of
+// pixmap.vert and pixmap.frag, if it's linked or PixmapVertexShaderSrc and
+// PixmapFragmentShaderSrc are set. It gets the same attributes and uniforms.
+//
+// Deprecated: Pixmaps load their shaders with Shaders. To draw them with other
+// shaders, point Shaders to files with the same names with Shaders.SetFS.
 var PixmapShaderProgram gl33.ShaderProgram
 
-// PixmapVertexShaderSrc is default vertex shader source for Pixmaps
-var PixmapVertexShaderSrc = `
-#version 330 core
-in vec3 vert;
-in vec2 vertTexCoord;
-out vec2 fragTexCoord;
-uniform mat4 transMat;
-uniform mat4 scaleMat;
-void main() {
-    gl_Position = scaleMat * transMat * vec4(vert, 1.0f);
-    fragTexCoord = vec2(vertTexCoord.x, 1.0 - vertTexCoord.y);
-}
-` + "\x00"
-
-// PixmapFragmentShaderSrc is default fragment shader source for Pixmaps
-var PixmapFragmentShaderSrc = `
-#version 330 core
-in vec2 fragTexCoord;
-out vec4 color;
-uniform sampler2D tex;
-void main() {
-    color = texture(tex, fragTexCoord).rgba;
-}
-` + "\x00"
+// PixmapVertexShaderSrc is the vertex shader source PixmapShaderProgram is
+// linked from. Empty by default.
+//
+// Deprecated: see PixmapShaderProgram.
+var PixmapVertexShaderSrc string
+
+// PixmapFragmentShaderSrc is the fragment shader source PixmapShaderProgram is
+// linked from. Empty by default.
+//
+// Deprecated: see PixmapShaderProgram.
+var PixmapFragmentShaderSrc string
+
+// pixmapProgram returns the shader program Pixmaps are drawn with: either the
+// one set up with deprecated PixmapShaderProgram or the one loaded from files.
+func pixmapProgram() (*gl33.ShaderProgram, error) {
+	if PixmapShaderProgram.Linked() {
+		return &PixmapShaderProgram, nil
+	}
+	if PixmapVertexShaderSrc == "" || PixmapFragmentShaderSrc == "" {
+		return Shaders.Program("pixmap.vert", "pixmap.frag")
+	}
+	if err := linkProgram(&PixmapShaderProgram, PixmapVertexShaderSrc, PixmapFragmentShaderSrc); err != nil {
+		return nil, err
+	}
+	return &PixmapShaderProgram, nil
+}
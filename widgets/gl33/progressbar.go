@@ -0,0 +1,75 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"github.com/Sergobot/Rocky/paint"
+)
+
+// ProgressBar is a horizontal bar filled proportionally to its value, which
+// is between 0 and 1. It's not interactive, so it's not focusable.
+type ProgressBar struct {
+	Widget
+
+	track *panel
+	fill  *Rectangle
+
+	value     float32
+	onChanged func(float32)
+}
+
+// NewProgressBar returns an empty progress bar.
+func NewProgressBar() *ProgressBar {
+	return &ProgressBar{track: newPanel(), fill: NewRectangle()}
+}
+
+// SetValue sets the progress. It's clamped to [0, 1].
+func (p *ProgressBar) SetValue(v float32) {
+	v = clamp32(v, 0, 1)
+	if v == p.value {
+		return
+	}
+	p.value = v
+	if p.onChanged != nil {
+		p.onChanged(v)
+	}
+}
+
+// Value returns the progress.
+func (p *ProgressBar) Value() float32 {
+	return p.value
+}
+
+// SetOnChanged sets a function called every time the progress changes.
+func (p *ProgressBar) SetOnChanged(f func(float32)) {
+	p.onChanged = f
+}
+
+// GetReady prepares shapes the progress bar is drawn with.
+func (p *ProgressBar) GetReady() {
+	readyParts(p.track, p.fill)
+}
+
+// Draw draws the bar and its filled part. Progress bar's theme type is
+// "ProgressBar": the bar is filled with Background and its filled part with
+// Accent.
+func (p *ProgressBar) Draw() {
+	r := p.GlobalGeometry()
+	st := p.ResolveStyle("ProgressBar", controlState(&p.Widget))
+
+	p.track.draw(r, *st.Background, st)
+
+	if p.value > 0 {
+		fill := r
+		fill.W *= p.value
+		p.fill.SetColor(*st.Accent)
+		p.fill.SetRadii(paint.UniformRadii(*st.Radius * r.H))
+		drawAt(p.fill, fill)
+	}
+}
+
+// Destroy frees the shapes and removes the progress bar from the widget tree.
+func (p *ProgressBar) Destroy() {
+	destroyParts(p.track, p.fill)
+	p.Widget.Destroy()
+}
@@ -0,0 +1,216 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"github.com/Sergobot/Rocky/events"
+	g "github.com/Sergobot/Rocky/geometry"
+	"github.com/Sergobot/Rocky/opengl/gl33"
+	"github.com/Sergobot/Rocky/theme"
+	"github.com/Sergobot/Rocky/widgets/basic"
+)
+
+// RadioGroup is a column of options, only one of which may be selected. Each
+// option is a round button, optionally followed by a label, which is any
+// widget, for example a Pixmap with some text. Options share the height of
+// the group equally.
+// An option is selected by a click, Up and Down keys (or gamepad's stick)
+// move the selection. At the first and the last option they are left for
+// focus navigation.
+type RadioGroup struct {
+	Widget
+
+	ring, dot *Ellipse
+
+	// Labels of options, nil for options without one
+	labels []basic.Node
+
+	selected  int
+	onChanged func(int)
+
+	ready bool
+}
+
+// NewRadioGroup returns a focusable radio group without options.
+func NewRadioGroup() *RadioGroup {
+	r := &RadioGroup{ring: NewEllipse(), dot: NewEllipse(), selected: -1}
+	r.SetFocusable(true)
+	return r
+}
+
+// AddOption adds an option with the given label, which may be nil, and returns
+// its index. The label becomes a child of the group.
+func (r *RadioGroup) AddOption(label basic.Node) int {
+	r.labels = append(r.labels, label)
+	if label != nil {
+		basic.Attach(r, label)
+		if r.ready {
+			label.GetReady()
+		}
+	}
+	r.arrange()
+	return len(r.labels) - 1
+}
+
+// Options returns the number of options.
+func (r *RadioGroup) Options() int {
+	return len(r.labels)
+}
+
+// SetSelected selects the option with the given index. Pass -1 to clear the
+// selection.
+func (r *RadioGroup) SetSelected(i int) {
+	if i < -1 || i >= len(r.labels) || i == r.selected {
+		return
+	}
+	r.selected = i
+	if r.onChanged != nil {
+		r.onChanged(i)
+	}
+}
+
+// Selected returns index of the selected option or -1.
+func (r *RadioGroup) Selected() int {
+	return r.selected
+}
+
+// SetOnChanged sets a function called every time another option is selected.
+func (r *RadioGroup) SetOnChanged(f func(int)) {
+	r.onChanged = f
+}
+
+// HandleEvent selects options on clicks and keys.
+func (r *RadioGroup) HandleEvent(e *events.Event) {
+	r.Widget.HandleEvent(e)
+	if e.Accepted() {
+		return
+	}
+
+	switch e.Type {
+	case events.MousePress:
+		if e.Button == events.MouseLeft {
+			e.Accept()
+		}
+	case events.MouseRelease:
+		if isClick(r, e) {
+			r.SetSelected(r.optionAt(e.Pos))
+			e.Accept()
+		}
+	case events.KeyPress, events.KeyRepeat:
+		switch e.Key {
+		case events.KeyUp:
+			r.step(-1, e)
+		case events.KeyDown:
+			r.step(1, e)
+		}
+	}
+}
+
+// step moves the selection and accepts the event, if it's moved.
+func (r *RadioGroup) step(delta int, e *events.Event) {
+	i := r.selected + delta
+	if r.selected < 0 {
+		i = 0
+	}
+	if i < 0 || i >= len(r.labels) {
+		return
+	}
+	r.SetSelected(i)
+	e.Accept()
+}
+
+// rowHeight returns height of a single option.
+func (r *RadioGroup) rowHeight() float32 {
+	if len(r.labels) == 0 {
+		return 0
+	}
+	return r.Size().H / float32(len(r.labels))
+}
+
+// optionAt returns index of the option under a point in window coordinates.
+func (r *RadioGroup) optionAt(p g.PointF) int {
+	h := r.rowHeight()
+	if h <= 0 {
+		return -1
+	}
+	i := int(r.MapFromGlobal(p).Y / h)
+	if i < 0 || i >= len(r.labels) {
+		return -1
+	}
+	return i
+}
+
+// arrange places labels to the right of their buttons, with theme's padding
+// around them.
+func (r *RadioGroup) arrange() {
+	h := r.rowHeight()
+	padding := *r.ResolveStyle("RadioGroup", theme.Normal).Padding
+	for i, l := range r.labels {
+		if l != nil {
+			l.SetGeometry(padding.Shrink(g.RectF{
+				PosF:  g.PosF{X: h, Y: float32(i) * h},
+				SizeF: g.SizeF{W: r.Size().W - h, H: h},
+			}))
+		}
+	}
+}
+
+// SetGeometry sets geometry of the group and rearranges labels.
+func (r *RadioGroup) SetGeometry(rect g.RectF) {
+	r.Widget.SetGeometry(rect)
+	r.arrange()
+}
+
+// SetSize sets size of the group and rearranges labels.
+func (r *RadioGroup) SetSize(s g.SizeF) {
+	r.Widget.SetSize(s)
+	r.arrange()
+}
+
+// GetReady prepares shapes the group is drawn with and its labels.
+func (r *RadioGroup) GetReady() {
+	readyParts(r.ring, r.dot)
+	for _, l := range r.labels {
+		if l != nil {
+			l.GetReady()
+		}
+	}
+	r.ready = true
+}
+
+// Draw draws the buttons. Labels are children, so they are drawn on their own.
+// Radio group's theme type is "RadioGroup": buttons are filled with Background
+// and have a border, the selected one has a dot of Foreground color.
+func (r *RadioGroup) Draw() {
+	rect := r.GlobalGeometry()
+	h := r.rowHeight()
+	d := min32(h*0.6, rect.W)
+
+	state := controlState(&r.Widget)
+	st := r.ResolveStyle("RadioGroup", state)
+	r.ring.SetColor(*st.Background)
+	r.ring.SetBorder(*st.BorderWidth*gl33.NormalizedPixelSize(), *st.BorderColor)
+	r.dot.SetColor(*st.Foreground)
+
+	for i := range r.labels {
+		ring := g.RectF{
+			PosF:  g.PosF{X: rect.X + (h-d)/2, Y: rect.Y + float32(i)*h + (h-d)/2},
+			SizeF: g.SizeF{W: d, H: d},
+		}
+		drawAt(r.ring, ring)
+
+		if i == r.selected {
+			drawAt(r.dot, g.RectF{
+				PosF:  g.PosF{X: ring.X + d/4, Y: ring.Y + d/4},
+				SizeF: g.SizeF{W: d / 2, H: d / 2},
+			})
+		}
+	}
+}
+
+// Destroy frees the shapes and destroys the group with its labels.
+func (r *RadioGroup) Destroy() {
+	destroyParts(r.ring, r.dot)
+	r.labels = nil
+	r.Widget.Destroy()
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"embed"
+	"io/fs"
+
+	"github.com/Sergobot/Rocky/opengl/gl33"
+)
+
+//go:embed shaders
+var shaderFiles embed.FS
+
+// Shaders loads shaders of widgets from files in the shaders directory, which
+// are embedded into the binary. Programs are loaded when the first widget
+// using them gets ready. To edit them without rebuilding, turn hot reload on
+// and read them from the source directory instead:
+//
+//	gl33.EnableHotReload(gl33.DefaultReloadInterval)
+//	Shaders.SetFS(os.DirFS("widgets/gl33/shaders"))
+var Shaders = gl33.NewShaderLoader(shaderFS())
+
+func shaderFS() fs.FS {
+	sub, err := fs.Sub(shaderFiles, "shaders")
+	if err != nil {
+		// That's only possible if the pattern above is broken
+		panic(err)
+	}
+	return sub
+}
+
+// ReleaseShaders deletes shader programs of widgets and sprite batches, and
+// textures of skins shared by controls. Widgets load them again, when they get
+// ready. Windows call it, when they are destroyed.
+func ReleaseShaders() {
+	releaseSkins()
+	Shaders.Clear()
+	gl33.Shaders.Clear()
+	PixmapShaderProgram.Release()
+}
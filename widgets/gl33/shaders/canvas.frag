@@ -0,0 +1,19 @@
+#version 330 core
+#include "common.glsl"
+in vec2 fragTexCoord;
+in vec4 fragColor;
+out vec4 color;
+// Is non-zero if the widget is disabled: it's drawn grey and half transparent
+uniform int greyed;
+uniform sampler2D tex;
+uniform bool textured;
+void main() {
+    if (textured) {
+        color = texture(tex, fragTexCoord) * fragColor;
+    } else {
+        color = fragColor;
+    }
+    if (greyed != 0) {
+        color = disabled(color);
+    }
+}
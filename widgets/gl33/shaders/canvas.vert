@@ -0,0 +1,12 @@
+#version 330 core
+in vec2 vert;
+in vec2 vertTexCoord;
+in vec4 vertColor;
+out vec2 fragTexCoord;
+out vec4 fragColor;
+uniform mat4 modelMat;
+void main() {
+    gl_Position = modelMat * vec4(vert, 0.0, 1.0);
+    fragTexCoord = vertTexCoord;
+    fragColor = vertColor;
+}
@@ -0,0 +1,12 @@
+// Helpers shared by widget shaders
+
+// greyscale returns luminance of a color as a grey color
+vec3 greyscale(vec3 rgb) {
+    return vec3(dot(rgb, vec3(0.299, 0.587, 0.114)));
+}
+
+// disabled returns a color the way disabled widgets are drawn: grey and half
+// transparent
+vec4 disabled(vec4 c) {
+    return vec4(greyscale(c.rgb), c.a * 0.5);
+}
@@ -0,0 +1,21 @@
+#version 330 core
+#include "common.glsl"
+in vec2 fragTexCoord;
+in vec4 fragColor;
+out vec4 color;
+// Is non-zero if the widget is disabled: it's drawn grey and half transparent
+uniform int greyed;
+uniform sampler2D tex;
+uniform bool textured;
+void main() {
+    if (textured) {
+        color = texture(tex, fragTexCoord) * fragColor;
+    } else {
+        // Soft round dot
+        float d = length(fragTexCoord * 2.0 - 1.0);
+        color = vec4(fragColor.rgb, fragColor.a * (1.0 - smoothstep(0.5, 1.0, d)));
+    }
+    if (greyed != 0) {
+        color = disabled(color);
+    }
+}
@@ -0,0 +1,21 @@
+#version 330 core
+in vec3 vert;
+in vec2 vertTexCoord;
+in vec2 center;
+in float size;
+in float rotation;
+in vec4 color;
+out vec2 fragTexCoord;
+out vec4 fragColor;
+uniform mat4 modelMat;
+uniform float pixel;
+void main() {
+    // Quad vertices are 2.0 wide, Y axis goes down in window coordinates
+    vec2 corner = vec2(vert.x, -vert.y) * 0.5 * size;
+    float s = sin(rotation);
+    float c = cos(rotation);
+    vec2 pos = center + vec2(c*corner.x - s*corner.y, s*corner.x + c*corner.y);
+    gl_Position = modelMat * vec4(pos * pixel, 0.0, 1.0);
+    fragTexCoord = vec2(vertTexCoord.x, 1.0 - vertTexCoord.y);
+    fragColor = color;
+}
@@ -0,0 +1,18 @@
+#version 330 core
+#include "common.glsl"
+in vec2 fragTexCoord;
+out vec4 color;
+uniform sampler2D tex;
+// Part of the texture to draw: offset in xy, size in zw
+uniform vec4 uvRect;
+uniform float opacity;
+// Is non-zero if the Pixmap is disabled: it's drawn grey and half transparent
+uniform int greyed;
+void main() {
+    vec2 uv = uvRect.xy + fragTexCoord * uvRect.zw;
+    color = texture(tex, uv).rgba;
+    color.a *= opacity;
+    if (greyed != 0) {
+        color = disabled(color);
+    }
+}
@@ -0,0 +1,9 @@
+#version 330 core
+in vec3 vert;
+in vec2 vertTexCoord;
+out vec2 fragTexCoord;
+uniform mat4 modelMat;
+void main() {
+    gl_Position = modelMat * vec4(vert, 1.0f);
+    fragTexCoord = vec2(vertTexCoord.x, 1.0 - vertTexCoord.y);
+}
@@ -0,0 +1,131 @@
+#version 330 core
+#include "common.glsl"
+in vec2 fragPos;
+in vec2 fragUV;
+out vec4 color;
+
+uniform int kind;
+uniform vec2 size;
+
+uniform vec4 fillColor;
+uniform float borderWidth;
+uniform vec4 borderColor;
+// Is non-zero if the shape is disabled: it's drawn grey and half transparent
+uniform int greyed;
+
+// Gradient. gradType is -1 if there is none, 0 for linear and 1 for radial.
+uniform int gradType;
+uniform vec2 gradFrom;
+uniform vec2 gradTo;
+uniform int stopCount;
+uniform float stopOffsets[8];
+uniform vec4 stopColors[8];
+
+// Rectangle: top-left, top-right, bottom-right and bottom-left radii
+uniform vec4 radii;
+
+// Polyline
+uniform int pointCount;
+uniform vec2 points[64];
+uniform float thickness;
+uniform int lineCap;
+
+float rectangle(vec2 p) {
+    vec2 halfSize = size / 2.0;
+    p -= halfSize;
+    float r = p.x > 0.0 ? (p.y > 0.0 ? radii.z : radii.y) : (p.y > 0.0 ? radii.w : radii.x);
+    r = min(r, min(halfSize.x, halfSize.y));
+    vec2 q = abs(p) - halfSize + r;
+    return min(max(q.x, q.y), 0.0) + length(max(q, 0.0)) - r;
+}
+
+float ellipse(vec2 p) {
+    vec2 ab = size / 2.0;
+    p -= ab;
+    float k0 = length(p / ab);
+    float k1 = length(p / (ab * ab));
+    if (k1 == 0.0) {
+        return -min(ab.x, ab.y);
+    }
+    return k0 * (k0 - 1.0) / k1;
+}
+
+float segment(vec2 p, vec2 a, vec2 b) {
+    float w = thickness / 2.0;
+    vec2 ba = b - a;
+    float len = length(ba);
+    if (lineCap == 1 || len == 0.0) {
+        // Round cap: distance to the segment itself
+        float h = len == 0.0 ? 0.0 : clamp(dot(p - a, ba) / (len * len), 0.0, 1.0);
+        return length(p - a - ba * h) - w;
+    }
+    // Butt and square caps: distance to an oriented box
+    vec2 dir = ba / len;
+    vec2 c = p - (a + b) / 2.0;
+    vec2 local = abs(vec2(dot(c, dir), dot(c, vec2(-dir.y, dir.x))));
+    float ext = lineCap == 2 ? w : 0.0;
+    vec2 q = local - vec2(len / 2.0 + ext, w);
+    return min(max(q.x, q.y), 0.0) + length(max(q, 0.0));
+}
+
+float polyline(vec2 p) {
+    if (pointCount == 1) {
+        return segment(p, points[0], points[0]);
+    }
+    float d = 1e10;
+    for (int i = 1; i < pointCount; i++) {
+        d = min(d, segment(p, points[i - 1], points[i]));
+    }
+    return d;
+}
+
+vec4 fill() {
+    if (gradType < 0) {
+        return fillColor;
+    }
+
+    vec2 dir = gradTo - gradFrom;
+    float t;
+    if (gradType == 0) {
+        t = dot(fragUV - gradFrom, dir) / dot(dir, dir);
+    } else {
+        t = length(fragUV - gradFrom) / length(dir);
+    }
+
+    if (t <= stopOffsets[0]) {
+        return stopColors[0];
+    }
+    for (int i = 1; i < stopCount; i++) {
+        if (t <= stopOffsets[i]) {
+            float span = stopOffsets[i] - stopOffsets[i - 1];
+            float k = span > 0.0 ? (t - stopOffsets[i - 1]) / span : 1.0;
+            return mix(stopColors[i - 1], stopColors[i], k);
+        }
+    }
+    return stopColors[stopCount - 1];
+}
+
+void main() {
+    float d;
+    if (kind == 0) {
+        d = rectangle(fragPos);
+    } else if (kind == 1) {
+        d = ellipse(fragPos);
+    } else {
+        d = polyline(fragPos);
+    }
+
+    // Coverage of the fragment by the shape, anti-aliased over one pixel
+    float outer = clamp(0.5 - d, 0.0, 1.0);
+
+    vec4 c = fill();
+    if (kind != 2 && borderWidth > 0.0) {
+        float inner = clamp(0.5 - (d + borderWidth), 0.0, 1.0);
+        c = mix(borderColor, c, inner);
+    }
+
+    color = vec4(c.rgb, c.a * outer);
+    if (greyed != 0) {
+        color = disabled(color);
+    }
+}
@@ -0,0 +1,12 @@
+#version 330 core
+in vec3 vert;
+in vec2 vertTexCoord;
+out vec2 fragPos;
+out vec2 fragUV;
+uniform mat4 modelMat;
+uniform vec2 size;
+void main() {
+    gl_Position = modelMat * vec4(vert, 1.0f);
+    fragUV = vec2(vertTexCoord.x, 1.0 - vertTexCoord.y);
+    fragPos = fragUV * size;
+}
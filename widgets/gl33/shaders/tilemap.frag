@@ -0,0 +1,15 @@
+#version 330 core
+#include "common.glsl"
+in vec2 fragTexCoord;
+out vec4 color;
+// Is non-zero if the widget is disabled: it's drawn grey and half transparent
+uniform int greyed;
+uniform sampler2D tex;
+uniform float opacity;
+void main() {
+    color = texture(tex, fragTexCoord);
+    color.a *= opacity;
+    if (greyed != 0) {
+        color = disabled(color);
+    }
+}
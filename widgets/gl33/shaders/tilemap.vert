@@ -0,0 +1,9 @@
+#version 330 core
+in vec2 vert;
+in vec2 vertTexCoord;
+out vec2 fragTexCoord;
+uniform mat4 modelMat;
+void main() {
+    gl_Position = modelMat * vec4(vert, 0.0, 1.0);
+    fragTexCoord = vertTexCoord;
+}
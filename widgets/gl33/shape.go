@@ -0,0 +1,320 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"log"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+
+	g "github.com/Sergobot/Rocky/geometry"
+	"github.com/Sergobot/Rocky/opengl/gl33"
+	"github.com/Sergobot/Rocky/paint"
+	"github.com/Sergobot/Rocky/widgets/basic"
+)
+
+// Kinds of shapes shape.frag can draw. Keep in sync with the shader.
+const (
+	rectangleKind int32 = iota
+	ellipseKind
+	polylineKind
+)
+
+// maxPolylinePoints is the maximum number of points in a Line. Keep in sync
+// with the shader.
+const maxPolylinePoints = 64
+
+// shape holds everything common for all the shape widgets: fill color or
+// gradient, border and OpenGL objects. It draws itself with the shape.frag shader,
+// which calculates signed distance to the shape for every fragment. That gives
+// us anti-aliased edges for free.
+type shape struct {
+	Widget
+
+	vao, vbo, ebo uint32
+	program       *gl33.ShaderProgram
+
+	color    paint.Color
+	gradient *paint.Gradient
+
+	borderWidth float32
+	borderColor paint.Color
+
+	ready bool
+}
+
+// init sets default values for a shape: it is white and has no border.
+func (s *shape) init() {
+	s.color = paint.White
+}
+
+// SetColor sets color the shape is filled with. Use paint.Transparent to draw
+// only the border. Gradient, if set, has priority over the color.
+func (s *shape) SetColor(c paint.Color) {
+	s.color = c
+}
+
+// Color returns fill color of the shape.
+func (s *shape) Color() paint.Color {
+	return s.color
+}
+
+// SetGradient sets gradient the shape is filled with. Pass nil to fill the shape
+// with a solid color again.
+func (s *shape) SetGradient(gr *paint.Gradient) {
+	s.gradient = gr
+}
+
+// Gradient returns gradient the shape is filled with or nil, if there is none.
+func (s *shape) Gradient() *paint.Gradient {
+	return s.gradient
+}
+
+// SetBorder sets border width and color. Width is measured in the same units as
+// geometry. Border is drawn inside the shape.
+func (s *shape) SetBorder(width float32, c paint.Color) {
+	s.borderWidth = width
+	s.borderColor = c
+}
+
+// Border returns border width and color.
+func (s *shape) Border() (float32, paint.Color) {
+	return s.borderWidth, s.borderColor
+}
+
+// GetReady loads the shader program if needed and generates OpenGL objects.
+func (s *shape) GetReady() {
+	if s.ready {
+		return
+	}
+
+	sp, err := Shaders.Program("shape.vert", "shape.frag")
+	if err != nil {
+		log.Println("Failed to prepare shape shader program:", err)
+		return
+	}
+	s.program = sp
+
+	s.vao, s.vbo, s.ebo, err = genQuad(sp)
+	if err != nil {
+		log.Println("Failed to prepare shape:", err)
+		return
+	}
+
+	s.ready = true
+}
+
+// Release frees OpenGL objects of the shape, but leaves it in the widget tree.
+// GetReady makes them again.
+func (s *shape) Release() {
+	if s.ready {
+		deleteQuad(&s.vao, &s.vbo, &s.ebo)
+		s.ready = false
+	}
+}
+
+// Destroy frees OpenGL objects of the shape and removes it from the widget tree.
+func (s *shape) Destroy() {
+	s.Release()
+	s.Widget.Destroy()
+}
+
+// draw draws a shape of the given kind. Caller is responsible for setting
+// kind-specific uniforms, which is done in setup after the program is in use.
+func (s *shape) draw(kind int32, setup func(sp *gl33.ShaderProgram, px float32)) {
+	if !s.ready {
+		log.Println("Prevented drawing a not ready shape")
+		return
+	}
+
+	r := s.GlobalGeometry()
+	if r.W <= 0 || r.H <= 0 {
+		return
+	}
+	modelMat := rectMatrix(r)
+
+	// Shader works in pixels, so anti-aliasing is always one pixel wide
+	px := gl33.NormalizedPixelSize()
+
+	sp := s.program
+	sp.Use()
+
+	sp.SetMat4("modelMat", modelMat)
+	sp.SetInt("kind", kind)
+	sp.SetVec2("size", mgl32.Vec2{r.W / px, r.H / px})
+	sp.SetVec4("fillColor", colorVec(s.color))
+	sp.SetFloat("borderWidth", s.borderWidth/px)
+	sp.SetVec4("borderColor", colorVec(s.borderColor))
+	setGradientUniforms(sp, s.gradient)
+	// Disabled shapes are drawn greyed out
+	sp.SetBool("greyed", !basic.IsEnabled(s))
+
+	setup(sp, px)
+
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+
+	gl.BindVertexArray(s.vao)
+	gl.DrawElements(gl.TRIANGLES, 6, gl.UNSIGNED_INT, nil)
+	gl33.CountDrawCall()
+	gl.BindVertexArray(0)
+}
+
+// setGradientUniforms passes gradient to the shape shader program. Gradient may be nil.
+func setGradientUniforms(sp *gl33.ShaderProgram, gr *paint.Gradient) {
+	if gr == nil || len(gr.Stops) == 0 {
+		sp.SetInt("gradType", -1)
+		return
+	}
+
+	stops := gr.Stops
+	if len(stops) > paint.MaxStops {
+		stops = stops[:paint.MaxStops]
+	}
+
+	offsets := make([]float32, len(stops))
+	colors := make([]mgl32.Vec4, len(stops))
+	for i, st := range stops {
+		offsets[i] = st.Offset
+		colors[i] = colorVec(st.Color)
+	}
+
+	sp.SetInt("gradType", int32(gr.Type))
+	sp.SetVec2("gradFrom", mgl32.Vec2{gr.From.X, gr.From.Y})
+	sp.SetVec2("gradTo", mgl32.Vec2{gr.To.X, gr.To.Y})
+	sp.SetInt("stopCount", int32(len(stops)))
+	sp.SetFloats("stopOffsets", offsets)
+	sp.SetVec4s("stopColors", colors)
+}
+
+// colorVec converts a color to a vec4 uniform.
+func colorVec(c paint.Color) mgl32.Vec4 {
+	return mgl32.Vec4{c.R, c.G, c.B, c.A}
+}
+
+// Rectangle is a filled and/or stroked rectangle with optionally rounded corners.
+type Rectangle struct {
+	shape
+
+	radii paint.Radii
+}
+
+// NewRectangle returns a white rectangle with sharp corners and no border.
+func NewRectangle() *Rectangle {
+	r := new(Rectangle)
+	r.init()
+	return r
+}
+
+// SetRadii sets radius of each corner. Radii are measured in the same units as
+// geometry.
+func (r *Rectangle) SetRadii(radii paint.Radii) {
+	r.radii = radii
+}
+
+// Radii returns radius of each corner.
+func (r *Rectangle) Radii() paint.Radii {
+	return r.radii
+}
+
+// Draw draws the rectangle.
+func (r *Rectangle) Draw() {
+	r.draw(rectangleKind, func(sp *gl33.ShaderProgram, px float32) {
+		sp.SetVec4("radii", mgl32.Vec4{
+			r.radii.TopLeft / px, r.radii.TopRight / px, r.radii.BottomRight / px, r.radii.BottomLeft / px})
+	})
+}
+
+// Ellipse is a filled and/or stroked ellipse inscribed in its geometry.
+type Ellipse struct {
+	shape
+}
+
+// NewEllipse returns a white ellipse with no border.
+func NewEllipse() *Ellipse {
+	e := new(Ellipse)
+	e.init()
+	return e
+}
+
+// Draw draws the ellipse.
+func (e *Ellipse) Draw() {
+	e.draw(ellipseKind, func(*gl33.ShaderProgram, float32) {})
+}
+
+// Line is a polyline of given thickness. Its points are relative to top-left
+// corner of its geometry and anything outside the geometry is clipped. Line
+// uses fill color or gradient, but not the border.
+type Line struct {
+	shape
+
+	points    []g.PointF
+	thickness float32
+	lineCap   paint.Cap
+}
+
+// NewLine returns a white line with no points. Its default thickness is the
+// size of a pixel at the moment of creation.
+func NewLine() *Line {
+	l := new(Line)
+	l.init()
+	l.thickness = gl33.NormalizedPixelSize()
+	return l
+}
+
+// SetPoints sets points of the line. Only first 64 points are drawn.
+func (l *Line) SetPoints(points []g.PointF) {
+	if len(points) > maxPolylinePoints {
+		log.Printf("Line has %d points, only %d are drawn", len(points), maxPolylinePoints)
+	}
+	l.points = points
+}
+
+// Points returns points of the line.
+func (l *Line) Points() []g.PointF {
+	return l.points
+}
+
+// SetThickness sets thickness of the line, measured in the same units as geometry.
+func (l *Line) SetThickness(t float32) {
+	l.thickness = t
+}
+
+// Thickness returns thickness of the line.
+func (l *Line) Thickness() float32 {
+	return l.thickness
+}
+
+// SetCap sets the way ends of the line are drawn.
+func (l *Line) SetCap(c paint.Cap) {
+	l.lineCap = c
+}
+
+// Cap returns the way ends of the line are drawn.
+func (l *Line) Cap() paint.Cap {
+	return l.lineCap
+}
+
+// Draw draws the line.
+func (l *Line) Draw() {
+	points := l.points
+	if len(points) > maxPolylinePoints {
+		points = points[:maxPolylinePoints]
+	}
+	if len(points) == 0 {
+		return
+	}
+
+	l.draw(polylineKind, func(sp *gl33.ShaderProgram, px float32) {
+		coords := make([]mgl32.Vec2, len(points))
+		for i, p := range points {
+			coords[i] = mgl32.Vec2{p.X / px, p.Y / px}
+		}
+
+		sp.SetInt("pointCount", int32(len(points)))
+		sp.SetVec2s("points", coords)
+		sp.SetFloat("thickness", l.thickness/px)
+		sp.SetInt("lineCap", int32(l.lineCap))
+	})
+}
@@ -0,0 +1,234 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"math"
+
+	"github.com/Sergobot/Rocky/events"
+	g "github.com/Sergobot/Rocky/geometry"
+	"github.com/Sergobot/Rocky/paint"
+	"github.com/Sergobot/Rocky/theme"
+)
+
+// Slider is a horizontal track with a knob, which selects a value in a range.
+// The knob is dragged with the mouse or moved with Left and Right arrow keys
+// (or gamepad's stick), Page Up and Page Down, Home and End. Up and Down are
+// left for focus navigation, so a column of sliders is easy to walk through.
+type Slider struct {
+	Widget
+
+	track *panel
+	fill  *Rectangle
+	knob  *Ellipse
+
+	min, max, step, value float32
+
+	onChanged func(float32)
+
+	// True while the knob is dragged with the mouse
+	dragging bool
+}
+
+// NewSlider returns a focusable slider with range [0, 1] and no step.
+func NewSlider() *Slider {
+	s := &Slider{
+		track: newPanel(),
+		fill:  NewRectangle(),
+		knob:  NewEllipse(),
+		max:   1,
+	}
+	s.SetFocusable(true)
+	return s
+}
+
+// SetRange sets minimum and maximum values. The value is clamped to the new
+// range.
+func (s *Slider) SetRange(min, max float32) {
+	if min > max {
+		min, max = max, min
+	}
+	s.min, s.max = min, max
+	s.SetValue(s.value)
+}
+
+// Range returns minimum and maximum values.
+func (s *Slider) Range() (float32, float32) {
+	return s.min, s.max
+}
+
+// SetStep sets the step values are rounded to, counting from the minimum.
+// Zero step means any value in range is fine.
+func (s *Slider) SetStep(step float32) {
+	if step < 0 {
+		step = 0
+	}
+	s.step = step
+	s.SetValue(s.value)
+}
+
+// Step returns the step values are rounded to.
+func (s *Slider) Step() float32 {
+	return s.step
+}
+
+// SetValue sets the value. It's rounded to the step and clamped to the range.
+func (s *Slider) SetValue(v float32) {
+	if s.step > 0 {
+		steps := math.Floor(float64((v-s.min)/s.step) + 0.5)
+		v = s.min + float32(steps)*s.step
+	}
+	v = clamp32(v, s.min, s.max)
+
+	if v == s.value {
+		return
+	}
+	s.value = v
+	if s.onChanged != nil {
+		s.onChanged(v)
+	}
+}
+
+// Value returns the value.
+func (s *Slider) Value() float32 {
+	return s.value
+}
+
+// SetOnChanged sets a function called every time the value changes.
+func (s *Slider) SetOnChanged(f func(float32)) {
+	s.onChanged = f
+}
+
+// HandleEvent moves the knob with the mouse, the wheel and keys.
+func (s *Slider) HandleEvent(e *events.Event) {
+	s.Widget.HandleEvent(e)
+	if e.Accepted() {
+		return
+	}
+
+	switch e.Type {
+	case events.MousePress:
+		if e.Button == events.MouseLeft {
+			s.dragging = true
+			s.setFromPos(e.Pos)
+			e.Accept()
+		}
+	case events.MouseMove:
+		if s.dragging {
+			s.setFromPos(e.Pos)
+			e.Accept()
+		}
+	case events.MouseRelease:
+		if s.dragging {
+			s.dragging = false
+			e.Accept()
+		}
+	case events.Scroll:
+		if e.Scroll.Y != 0 {
+			s.nudge(e.Scroll.Y*s.keyStep(), e)
+		}
+	case events.KeyPress, events.KeyRepeat:
+		switch e.Key {
+		case events.KeyLeft:
+			s.nudge(-s.keyStep(), e)
+		case events.KeyRight:
+			s.nudge(s.keyStep(), e)
+		case events.KeyPageDown:
+			s.nudge(-10*s.keyStep(), e)
+		case events.KeyPageUp:
+			s.nudge(10*s.keyStep(), e)
+		case events.KeyHome:
+			s.nudge(s.min-s.value, e)
+		case events.KeyEnd:
+			s.nudge(s.max-s.value, e)
+		}
+	}
+}
+
+// keyStep returns how much a single key press changes the value: a step, if
+// there is one, or a twentieth of the range.
+func (s *Slider) keyStep() float32 {
+	if s.step > 0 {
+		return s.step
+	}
+	return (s.max - s.min) / 20
+}
+
+// nudge changes the value by delta and accepts the event, if the value has
+// changed. When the knob is already at the end, focus navigation gets the key.
+func (s *Slider) nudge(delta float32, e *events.Event) {
+	old := s.value
+	s.SetValue(s.value + delta)
+	if s.value != old {
+		e.Accept()
+	}
+}
+
+// knobRadius returns radius of the knob. The knob is as tall as the slider.
+func (s *Slider) knobRadius() float32 {
+	return s.Size().H / 2
+}
+
+// setFromPos sets the value corresponding to a point in window coordinates.
+func (s *Slider) setFromPos(p g.PointF) {
+	r := s.knobRadius()
+	length := s.Size().W - 2*r
+	if length <= 0 {
+		return
+	}
+
+	t := clamp32((s.MapFromGlobal(p).X-r)/length, 0, 1)
+	s.SetValue(s.min + t*(s.max-s.min))
+}
+
+// GetReady prepares shapes the slider is drawn with.
+func (s *Slider) GetReady() {
+	readyParts(s.track, s.fill, s.knob)
+}
+
+// state returns the state of the slider for theme's selectors.
+func (s *Slider) state() theme.State {
+	st := controlState(&s.Widget)
+	if s.dragging {
+		st |= theme.Pressed
+	}
+	return st
+}
+
+// Draw draws the track, the filled part of it and the knob. Slider's theme
+// type is "Slider": the track is filled with Background, its filled part with
+// Accent and the knob with Foreground.
+func (s *Slider) Draw() {
+	r := s.GlobalGeometry()
+	kr := s.knobRadius()
+	st := s.ResolveStyle("Slider", s.state())
+
+	var t float32
+	if s.max > s.min {
+		t = (s.value - s.min) / (s.max - s.min)
+	}
+
+	track := g.RectF{
+		PosF:  g.PosF{X: r.X + kr, Y: r.Y + r.H*3/8},
+		SizeF: g.SizeF{W: r.W - 2*kr, H: r.H / 4},
+	}
+	s.track.draw(track, *st.Background, st)
+
+	fill := track
+	fill.W *= t
+	s.fill.SetColor(*st.Accent)
+	s.fill.SetRadii(paint.UniformRadii(*st.Radius * track.H))
+	drawAt(s.fill, fill)
+
+	s.knob.SetColor(*st.Foreground)
+	drawAt(s.knob, g.RectF{
+		PosF:  g.PosF{X: track.X + fill.W - kr, Y: r.Y},
+		SizeF: g.SizeF{W: 2 * kr, H: 2 * kr},
+	})
+}
+
+// Destroy frees the shapes and removes the slider from the widget tree.
+func (s *Slider) Destroy() {
+	destroyParts(s.track, s.fill, s.knob)
+	s.Widget.Destroy()
+}
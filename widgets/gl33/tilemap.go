@@ -0,0 +1,381 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package gl33
+
+import (
+	"log"
+	"time"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+
+	"github.com/Sergobot/Rocky/animation"
+	g "github.com/Sergobot/Rocky/geometry"
+	"github.com/Sergobot/Rocky/opengl/gl33"
+	"github.com/Sergobot/Rocky/tilemap"
+	"github.com/Sergobot/Rocky/widgets/basic"
+)
+
+// TileMap draws a tile map, usually loaded from a file made in Tiled. Only
+// tiles inside the widget are drawn: the map is scrolled and zoomed to show
+// the part of it you need. Tiles of a layer are drawn in batches, one per
+// tileset, so maps of any size are cheap to draw.
+// Object layers are not drawn, they are for the game to look at.
+// TileMap is an animation, which drives animated tiles: play it with a
+// window's animation player to make them move.
+type TileMap struct {
+	Widget
+
+	m *tilemap.Map
+
+	// Textures of tilesets, nil for tilesets failed to load
+	textures []*gl33.Texture
+
+	vao, vbo uint32
+	program  *gl33.ShaderProgram
+
+	// Vertices of each tileset, reused between frames
+	batches [][]float32
+
+	// Point of the map (in its pixels) shown at the top-left corner
+	scroll g.PointF
+	// Screen pixels per pixel of the map
+	zoom float32
+
+	// Time of tile animations
+	clock time.Duration
+
+	ready bool
+}
+
+// NewTileMap returns a TileMap without a map at zoom 1.
+func NewTileMap() *TileMap {
+	return &TileMap{zoom: 1}
+}
+
+// LoadFromFile loads a map from a TMX or JSON file, see tilemap.LoadFile.
+func (t *TileMap) LoadFromFile(file string) {
+	m, err := tilemap.LoadFile(file)
+	if err != nil {
+		log.Println("Failed to load a map to a TileMap:", err)
+		return
+	}
+	t.SetMap(m)
+}
+
+// SetMap sets the map to draw and loads images of its tilesets. The map may
+// be changed afterwards, for example to replace some tiles, but tilesets
+// added later are not loaded until SetMap is called again.
+func (t *TileMap) SetMap(m *tilemap.Map) {
+	t.m = m
+	t.releaseTextures()
+	if m == nil {
+		return
+	}
+
+	t.textures = make([]*gl33.Texture, len(m.Tilesets))
+	for i, ts := range m.Tilesets {
+		tex := new(gl33.Texture)
+		if err := tex.LoadFromFile(ts.Image); err != nil {
+			log.Printf("Failed to load tileset %q: %v", ts.Name, err)
+			continue
+		}
+		t.textures[i] = tex
+	}
+	t.batches = make([][]float32, len(m.Tilesets))
+}
+
+// Map returns the map drawn or nil.
+func (t *TileMap) Map() *tilemap.Map {
+	return t.m
+}
+
+// SetScroll sets the point of the map, in its pixels, shown at the top-left
+// corner of the widget.
+func (t *TileMap) SetScroll(p g.PointF) {
+	t.scroll = p
+}
+
+// Scroll returns the point of the map shown at the top-left corner.
+func (t *TileMap) Scroll() g.PointF {
+	return t.scroll
+}
+
+// SetZoom sets how many screen pixels a pixel of the map takes. Zoom 1 shows
+// tiles pixel to pixel.
+func (t *TileMap) SetZoom(z float32) {
+	if z <= 0 {
+		return
+	}
+	t.zoom = z
+}
+
+// Zoom returns how many screen pixels a pixel of the map takes.
+func (t *TileMap) Zoom() float32 {
+	return t.zoom
+}
+
+// scale returns the size of a map pixel in window coordinates.
+func (t *TileMap) scale() float32 {
+	return gl33.NormalizedPixelSize() * t.zoom
+}
+
+// MapPoint converts a point in window coordinates, like a mouse position, to
+// a point of the map in its pixels.
+func (t *TileMap) MapPoint(p g.PointF) g.PointF {
+	local := t.MapFromGlobal(p)
+	s := t.scale()
+	return g.PointF{X: t.scroll.X + local.X/s, Y: t.scroll.Y + local.Y/s}
+}
+
+// CellAt returns the cell of the map under a point in window coordinates.
+// It returns false if there is no map or the point is outside of it.
+func (t *TileMap) CellAt(p g.PointF) (x, y int, ok bool) {
+	if t.m == nil {
+		return 0, 0, false
+	}
+	mp := t.MapPoint(p)
+	if mp.X < 0 || mp.Y < 0 {
+		return 0, 0, false
+	}
+	x, y = int(mp.X)/t.m.TileWidth, int(mp.Y)/t.m.TileHeight
+	return x, y, x < t.m.Width && y < t.m.Height
+}
+
+// Duration returns animation.Infinite: tiles are animated forever.
+func (t *TileMap) Duration() time.Duration {
+	return animation.Infinite
+}
+
+// Seek sets the time of tile animations.
+func (t *TileMap) Seek(d time.Duration) {
+	t.clock = d
+}
+
+// Rewind brings tile animations back to their first frames.
+func (t *TileMap) Rewind() {
+	t.clock = 0
+}
+
+// GetReady loads the shader program if needed and generates the buffers
+// tiles are streamed to.
+func (t *TileMap) GetReady() {
+	if t.ready {
+		return
+	}
+
+	sp, err := Shaders.Program("tilemap.vert", "tilemap.frag")
+	if err != nil {
+		log.Println("Failed to prepare TileMap shader program:", err)
+		return
+	}
+	t.program = sp
+	if err := sp.CheckLayout(tileMapLayout); err != nil {
+		log.Println("Failed to prepare TileMap:", err)
+		return
+	}
+
+	t.vao = gl33.GenVertexArray()
+	gl.BindVertexArray(t.vao)
+	t.vbo = gl33.GenBuffer()
+	gl.BindBuffer(gl.ARRAY_BUFFER, t.vbo)
+
+	sp.SetupLayout(tileMapLayout)
+
+	gl.BindVertexArray(0)
+
+	t.ready = true
+}
+
+// Draw draws visible tile layers of the map.
+func (t *TileMap) Draw() {
+	if !t.ready {
+		log.Println("Prevented drawing a not ready TileMap")
+		return
+	}
+	if t.m == nil {
+		return
+	}
+
+	r := t.GlobalGeometry()
+	if r.W <= 0 || r.H <= 0 {
+		return
+	}
+
+	sp := t.program
+	sp.Use()
+	sp.SetMat4("modelMat", windowMatrix())
+	sp.SetSampler("tex", 0)
+	// Disabled TileMaps are drawn greyed out
+	sp.SetBool("greyed", !basic.IsEnabled(t))
+
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+
+	// Tiles on the edges stick out of the widget, scissor cuts them off. It
+	// works in screen space, so rotated maps are not cut.
+	scissor := basic.DrawTransform().IsIdentity()
+	if scissor {
+		setScissor(r)
+		gl.Enable(gl.SCISSOR_TEST)
+	}
+
+	gl.BindVertexArray(t.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, t.vbo)
+	for _, l := range t.m.Layers {
+		if l.Kind == tilemap.TileLayer && l.Visible && l.Opacity > 0 {
+			t.drawLayer(l, r, sp)
+		}
+	}
+	gl.BindVertexArray(0)
+
+	if scissor {
+		gl.Disable(gl.SCISSOR_TEST)
+	}
+}
+
+// drawLayer draws a tile layer in r, a batch per tileset.
+func (t *TileMap) drawLayer(l *tilemap.Layer, r g.RectF, sp *gl33.ShaderProgram) {
+	m := t.m
+	s := t.scale()
+
+	// Part of the map (in its pixels) visible in the widget
+	view := g.RectF{
+		PosF:  g.PosF{X: t.scroll.X - l.Offset.X, Y: t.scroll.Y - l.Offset.Y},
+		SizeF: g.SizeF{W: r.W / s, H: r.H / s},
+	}
+	x0, y0, x1, y1 := m.VisibleCells(view)
+
+	for i := range t.batches {
+		t.batches[i] = t.batches[i][:0]
+	}
+
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			gid := m.At(l, x, y)
+			ts, id := m.Tileset(gid)
+			if ts == nil {
+				continue
+			}
+			i := t.tilesetIndex(ts)
+			if i < 0 || i >= len(t.textures) || t.textures[i] == nil {
+				continue
+			}
+
+			// Tiles are placed by their bottom-left corner, like in Tiled
+			px := float32(x*m.TileWidth) - view.X
+			py := float32((y+1)*m.TileHeight-ts.TileHeight) - view.Y
+			dst := g.RectF{
+				PosF:  g.PosF{X: r.X + px*s, Y: r.Y + py*s},
+				SizeF: g.SizeF{W: float32(ts.TileWidth) * s, H: float32(ts.TileHeight) * s},
+			}
+
+			frame := ts.Frame(id, t.clock)
+			t.batches[i] = appendTile(t.batches[i], dst, ts.TileRect(frame), t.textures[i].Size(), gid.Flips())
+		}
+	}
+
+	sp.SetFloat("opacity", l.Opacity)
+	for i, verts := range t.batches {
+		if len(verts) == 0 {
+			continue
+		}
+		if err := t.textures[i].Bind(); err != nil {
+			log.Println("Failed to bind texture while drawing TileMap:", err)
+			continue
+		}
+		gl.BufferData(gl.ARRAY_BUFFER, len(verts)*4, gl.Ptr(verts), gl.STREAM_DRAW)
+		gl.DrawArrays(gl.TRIANGLES, 0, int32(len(verts)/4))
+		gl33.CountDrawCall()
+	}
+}
+
+// tilesetIndex returns index of a tileset in the map or -1.
+func (t *TileMap) tilesetIndex(ts *tilemap.Tileset) int {
+	for i, mts := range t.m.Tilesets {
+		if mts == ts {
+			return i
+		}
+	}
+	return -1
+}
+
+// appendTile appends two triangles covering dst with src part of a texture
+// of the given size, flipped as GID flags tell.
+func appendTile(verts []float32, dst g.RectF, src g.Rect, tex g.Size, flips tilemap.GID) []float32 {
+	if tex.W <= 0 || tex.H <= 0 {
+		return verts
+	}
+
+	// Half a texel inside, so neighbour tiles don't bleed in when filtered
+	u0 := (float32(src.X) + 0.5) / float32(tex.W)
+	v0 := (float32(src.Y) + 0.5) / float32(tex.H)
+	u1 := (float32(src.X+src.W) - 0.5) / float32(tex.W)
+	v1 := (float32(src.Y+src.H) - 0.5) / float32(tex.H)
+
+	corner := func(cx, cy float32) (x, y, u, v float32) {
+		x, y = dst.X+cx*dst.W, dst.Y+cy*dst.H
+		// Flags are applied like in Tiled: the diagonal first
+		if flips&tilemap.FlipDiagonal != 0 {
+			cx, cy = cy, cx
+		}
+		if flips&tilemap.FlipHorizontal != 0 {
+			cx = 1 - cx
+		}
+		if flips&tilemap.FlipVertical != 0 {
+			cy = 1 - cy
+		}
+		return x, y, u0 + cx*(u1-u0), v0 + cy*(v1-v0)
+	}
+
+	for _, c := range [6][2]float32{{0, 0}, {1, 0}, {1, 1}, {0, 0}, {1, 1}, {0, 1}} {
+		x, y, u, v := corner(c[0], c[1])
+		verts = append(verts, x, y, u, v)
+	}
+	return verts
+}
+
+// setScissor sets the scissor box to a rect in window coordinates.
+func setScissor(r g.RectF) {
+	_, _, _, vpH := gl33.Viewport()
+	px := gl33.NormalizedPixelSize()
+	gl.Scissor(int32(r.X/px), vpH-int32((r.Y+r.H)/px), int32(r.W/px+0.5), int32(r.H/px+0.5))
+}
+
+// releaseTextures deletes textures of tilesets.
+func (t *TileMap) releaseTextures() {
+	for _, tex := range t.textures {
+		if tex != nil {
+			tex.Release()
+		}
+	}
+	t.textures = nil
+}
+
+// Release frees OpenGL objects of the TileMap, including textures of tilesets,
+// but leaves it in the widget tree. The map itself is left intact: call SetMap
+// and GetReady to use the TileMap again.
+func (t *TileMap) Release() {
+	if t.ready {
+		gl33.DeleteVertexArray(&t.vao)
+		gl33.DeleteBuffer(&t.vbo)
+		t.ready = false
+	}
+	t.releaseTextures()
+}
+
+// Destroy frees OpenGL objects of the TileMap, see Release, and removes it
+// from the widget tree.
+func (t *TileMap) Destroy() {
+	t.Release()
+	t.Widget.Destroy()
+}
+
+// tileMapLayout is the layout of TileMap vertices: a position in window
+// coordinates and texture coordinates.
+var tileMapLayout = gl33.VertexLayout{
+	Stride: 4,
+	Attribs: []gl33.VertexAttrib{
+		{Name: "vert", Size: 2, Offset: 0},
+		{Name: "vertTexCoord", Size: 2, Offset: 2},
+	},
+}
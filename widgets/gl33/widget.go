@@ -3,98 +3,119 @@
 package gl33
 
 import (
+	"fmt"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
 
 	g "github.com/Sergobot/Rocky/geometry"
 	"github.com/Sergobot/Rocky/opengl/gl33"
+	"github.com/Sergobot/Rocky/widgets/basic"
 )
 
 // Widget is the simpliest widget type, just a blank one.
-// It implements only functions for work with size and position.
-// Used to be embedded in another more specific widget struct.
+// Everything about geometry and widget tree is done by embedded basic.Widget,
+// this one is used to be embedded in more specific widget structs drawn with
+// OpenGL 3.3.
 type Widget struct {
-	// Basic parameters: width, height and X/Y coordinates
-	geometry g.RectF
-
-	// Two matrices: one for scalings, another for translatons
-	transMat, scaleMat mgl32.Mat4
+	basic.Widget
 }
 
-// SetGeometry sets the rectangle (or bounding box, if you want) of a widget.
-// That means, widget will have same coordinates and size as a given rect.
-func (w *Widget) SetGeometry(r g.RectF) {
-	w.SetSize(r.SizeF)
-	w.SetPos(r.PosF)
+// rectMatrix returns a matrix, which transforms widgetVertices (a quad covering
+// the whole viewport) to cover the given rect. The rect is in our own normalized
+// coordinates, see gl33.NormalizedViewportSize. Rotation of the widget being
+// drawn (see basic.DrawTransform) is applied to the rect as well.
+func rectMatrix(r g.RectF) mgl32.Mat4 {
+	// widgetVertices are 2.0 wide and tall and centered at zero, so they are
+	// scaled and moved to cover the rect. Note, Y axis goes up in OpenGL and
+	// down in ours.
+	toRect := mgl32.Translate3D(r.X+r.W/2, r.Y+r.H/2, 0).Mul4(mgl32.Scale3D(r.W/2, -r.H/2, 1))
+	return windowMatrix().Mul4(toRect)
 }
 
-// Geometry returns current bounding box of a widget.
-func (w *Widget) Geometry() g.RectF {
-	return w.geometry
+// windowMatrix returns a matrix, which transforms our normalized coordinates
+// to OpenGL ones, applying rotation of the widget being drawn. It's used to
+// draw vertices already placed in window coordinates.
+func windowMatrix() mgl32.Mat4 {
+	toGL := gl33.WindowProjection()
+
+	t := basic.DrawTransform()
+	if t.IsIdentity() {
+		return toGL
+	}
+	rotation := mgl32.Mat4{
+		t.A, t.B, 0, 0,
+		t.C, t.D, 0, 0,
+		0, 0, 1, 0,
+		t.E, t.F, 0, 1,
+	}
+	return toGL.Mul4(rotation)
 }
 
-// SetSize sets the widget's size. You can call it manually or through SetGeometry.
-func (w *Widget) SetSize(s g.SizeF) {
-	xScaleRatio := s.W / w.geometry.W
-	yScaleRatio := s.H / w.geometry.H
-	zScaleRatio := float32(1) // There is no need to scale Z coordinate
-
-	// This is because of side effect of scaling: our widget moves and we need to
-	// move it back.
-	// First, retrieve current normilized viewport size
-	vpSize := gl33.NormalizedViewportSize()
-	// Next convert current, "good" widget coordinates to use in OpenGL
-	xCur := (2*w.geometry.X - vpSize.W) / vpSize.W
-	yCur := (vpSize.H - 2*w.geometry.Y) / vpSize.H
-	// Then we find new ("bad") ones
-	xNew := xCur * xScaleRatio
-	yNew := yCur * yScaleRatio
-
-	// And, finally, apply transformations to matrices
-	w.transMat = w.transMat.Mul4(mgl32.Translate3D(xCur-xNew, yCur-yNew, float32(0)))
-	w.scaleMat = w.scaleMat.Mul4(mgl32.Scale3D(xScaleRatio, yScaleRatio, zScaleRatio))
-
-	// Don't forget to update size in the widget itself
-	w.geometry.SizeF = s
+// linkProgram compiles vertex and fragment shaders from the given sources and
+// links them into sp.
+func linkProgram(sp *gl33.ShaderProgram, vSrc, fSrc string) error {
+	var vShader, fShader gl33.Shader
+	// The program doesn't need shaders after linking
+	defer vShader.Release()
+	defer fShader.Release()
+
+	if err := vShader.Compile(vSrc, gl33.VertexShader); err != nil {
+		return fmt.Errorf("Failed to compile vertex shader: %v", err)
+	}
+	if err := fShader.Compile(fSrc, gl33.FragmentShader); err != nil {
+		return fmt.Errorf("Failed to compile fragment shader: %v", err)
+	}
+	if err := sp.Link(vShader, fShader); err != nil {
+		return fmt.Errorf("Failed to link shader program: %v", err)
+	}
+	return nil
 }
 
-// Size returns current widget's size.
-func (w *Widget) Size() g.SizeF {
-	return w.geometry.SizeF
+// quadLayout is the layout of widgetVertices: positions go to "vert" and
+// texture coordinates to "vertTexCoord".
+var quadLayout = gl33.VertexLayout{
+	Stride: 5,
+	Attribs: []gl33.VertexAttrib{
+		{Name: "vert", Size: 3, Offset: 0},
+		{Name: "vertTexCoord", Size: 2, Offset: 3},
+	},
 }
 
-// SetPos sets position of a widget. It's used in SetGeometry but you can call
-// it manually.
-func (w *Widget) SetPos(p g.PosF) {
-	// Get normalized viewport size
-	vpSize := gl33.NormalizedViewportSize()
+// genQuad generates VAO, VBO and EBO holding widgetVertices and widgetIndices,
+// which are passed to the given shader program as described by quadLayout.
+// Nothing is generated, if the program doesn't take such vertices.
+func genQuad(sp *gl33.ShaderProgram) (vao, vbo, ebo uint32, err error) {
+	if err := sp.CheckLayout(quadLayout); err != nil {
+		return 0, 0, 0, fmt.Errorf("Failed to set up quad vertices: %v", err)
+	}
 
-	// Convert our own normalized coordinates to OpenGL ones and get the difference
-	// between new and old coordinates.
-	xTrans := (2*p.X - w.geometry.X) / vpSize.W
-	yTrans := (2*w.geometry.Y - p.Y) / vpSize.H
-	zTrans := float32(0) // We don't tranlsate z coordinate
+	vao = gl33.GenVertexArray()
+	gl.BindVertexArray(vao)
 
-	// Then multiply widget's translation matrix and one returned by 'Translate3D'
-	w.transMat = w.transMat.Mul4(mgl32.Translate3D(xTrans, yTrans, zTrans))
+	vbo = gl33.GenBuffer()
+	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
+	gl.BufferData(gl.ARRAY_BUFFER, len(widgetVertices)*4, gl.Ptr(widgetVertices), gl.STATIC_DRAW)
 
-	// And, finally, we assign widget's position to the new one
-	w.geometry.PosF = p
-}
+	ebo = gl33.GenBuffer()
+	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
+	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(widgetIndices)*4, gl.Ptr(widgetIndices), gl.STATIC_DRAW)
 
-// Pos returns current widget's position.
-func (w *Widget) Pos() g.PosF {
-	return w.geometry.PosF
-}
+	// And then we load vertices and indices to OpenGL pipeline. The layout
+	// is checked above, so that can't fail.
+	sp.SetupLayout(quadLayout)
 
-// GetReady initializes matrices and if there was attempt to resize/move widget
-// that geometry change is applied here.
-func (w *Widget) GetReady() {}
+	gl.BindVertexArray(0)
 
-// Update does nothing: Widget should not get any events or draw anything
-func (w *Widget) Update() {}
+	return vao, vbo, ebo, nil
+}
 
-// Draw does nothing: Widget's always blank, we don't draw anything on it
-func (w *Widget) Draw() {}
+// deleteQuad deletes VAO, VBO and EBO generated by genQuad and zeroes them.
+func deleteQuad(vao, vbo, ebo *uint32) {
+	gl33.DeleteVertexArray(vao)
+	gl33.DeleteBuffer(vbo)
+	gl33.DeleteBuffer(ebo)
+}
 
 // widgetVertices are default widget vertices, used in more advanced widget than
 // the one implemented in this file
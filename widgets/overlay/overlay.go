@@ -0,0 +1,371 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+// Package overlay implements widgets floating above window's layout: popups,
+// context menus, modal dialogs and tooltips.
+package overlay
+
+import (
+	"time"
+
+	"github.com/Sergobot/Rocky/events"
+	g "github.com/Sergobot/Rocky/geometry"
+	"github.com/Sergobot/Rocky/widgets/basic"
+)
+
+// DefaultTooltipDelay is how long the cursor has to hover a widget before its
+// tooltip is shown.
+const DefaultTooltipDelay = 700 * time.Millisecond
+
+// TooltipOffset is the distance between the cursor and its tooltip, so the
+// cursor doesn't cover the tooltip.
+const TooltipOffset float32 = 0.04
+
+// Stack is the root of window's widget tree. Its first child is the main
+// widget, usually window's root container, and the rest are layers opened
+// above it. Every layer covers the whole window and holds a single widget,
+// called content. Layers opened later are drawn and hit tested first.
+//
+// There are three kinds of layers. Popups get all the mouse events, and
+// pressing a button outside of the content or pressing Escape closes them.
+// Modal layers block input to everything beneath them and are closed only by
+// the code which opened them. Passive layers, like tooltips, don't take any
+// input at all: it goes to whatever is under them.
+//
+// Popups and modal layers also take keyboard focus: while one is open, focus
+// can't leave it. When it's closed, focus returns to where it was.
+type Stack struct {
+	basic.Widget
+
+	focus  *basic.FocusManager
+	layers []*Layer
+
+	// TooltipDelay is how long the cursor has to hover a widget before its
+	// tooltip is shown.
+	TooltipDelay time.Duration
+
+	// Widget with a tooltip under the cursor, for how long it's hovered and
+	// the layer showing its tooltip
+	hovered   basic.Node
+	hoverPos  g.PointF
+	hoverTime time.Duration
+	tooltip   *Layer
+}
+
+// NewStack returns a stack with the main widget in it. Focus manager is
+// optional, if it's given, popups and modal layers restrict focus to
+// themselves. Windows create a stack automatically, see Window.Overlay.
+func NewStack(main basic.Node, focus *basic.FocusManager) *Stack {
+	s := &Stack{focus: focus, TooltipDelay: DefaultTooltipDelay}
+	basic.Attach(s, main)
+	return s
+}
+
+// Of returns the stack a widget belongs to or nil, if it's not in a widget
+// tree with a stack at the root. This is how widgets open popups without
+// knowing their window.
+func Of(n basic.Node) *Stack {
+	for n != nil {
+		if s, ok := n.(*Stack); ok {
+			return s
+		}
+		n = n.Parent()
+	}
+	return nil
+}
+
+// Layer holds a widget floating above the main one. Layers are created by
+// Stack methods, such as Popup or Modal.
+type Layer struct {
+	basic.Widget
+
+	stack   *Stack
+	content basic.Node
+	kind    kind
+
+	// Calculates geometry of the content inside window-sized bounds
+	place func(bounds g.RectF) g.RectF
+
+	// Focused widget before the layer was opened
+	prevFocus basic.Node
+
+	// OnClose is called when the layer is closed in any way.
+	OnClose func()
+}
+
+type kind int
+
+const (
+	popup   kind = iota
+	modal   kind = iota
+	passive kind = iota
+)
+
+// Content returns the widget the layer holds.
+func (l *Layer) Content() basic.Node {
+	return l.content
+}
+
+// Modal returns true if the layer blocks all the input to widgets beneath it.
+func (l *Layer) Modal() bool {
+	return l.kind == modal
+}
+
+// Close closes the layer. The content is detached from it, but not destroyed,
+// so it may be shown again later. Destroy it in OnClose, if it's not needed.
+func (l *Layer) Close() {
+	l.stack.close(l)
+}
+
+// HandleEvent makes popups and modal layers take all the mouse events, which
+// content didn't accept, and closes a popup, when a button is pressed outside
+// of its content.
+func (l *Layer) HandleEvent(e *events.Event) {
+	l.Widget.HandleEvent(e)
+	if e.Accepted() || !e.IsMouse() || l.kind == passive {
+		return
+	}
+
+	if l.kind == popup && e.Type == events.MousePress &&
+		(l.content == nil || !basic.Contains(l.content, e.Pos)) {
+		l.Close()
+	}
+	e.Accept()
+}
+
+// Popup opens a popup anchored to a rect in window coordinates, usually the
+// geometry of the widget which opened it. The popup keeps its current size and
+// is placed at the given side of the anchor, flipped to the opposite side if
+// there is no room.
+func (s *Stack) Popup(content basic.Node, anchor g.RectF, side Side) *Layer {
+	size := content.Geometry().SizeF
+	return s.open(content, popup, func(bounds g.RectF) g.RectF {
+		return Place(size, anchor, bounds, side)
+	})
+}
+
+// ContextMenu opens a popup at a point in window coordinates, usually where
+// the cursor is.
+func (s *Stack) ContextMenu(content basic.Node, p g.PointF) *Layer {
+	return s.Popup(content, g.RectF{PosF: g.PosF(p)}, Below)
+}
+
+// Modal opens a modal layer with the content centered in the window. Widgets
+// beneath it don't get any input until it's closed.
+func (s *Stack) Modal(content basic.Node) *Layer {
+	size := content.Geometry().SizeF
+	return s.open(content, modal, func(bounds g.RectF) g.RectF {
+		return g.AlignCenter.Align(size, bounds)
+	})
+}
+
+// Passive opens a layer which doesn't take any input, with the content at the
+// given geometry. It's useful for notifications and other decorations.
+func (s *Stack) Passive(content basic.Node, r g.RectF) *Layer {
+	return s.open(content, passive, func(g.RectF) g.RectF {
+		return r
+	})
+}
+
+// Layers returns all the open layers, the topmost one is the last.
+func (s *Stack) Layers() []*Layer {
+	return s.layers
+}
+
+// Top returns the topmost layer, which takes input, or nil. Passive layers are
+// skipped.
+func (s *Stack) Top() *Layer {
+	for i := len(s.layers) - 1; i >= 0; i-- {
+		if s.layers[i].kind != passive {
+			return s.layers[i]
+		}
+	}
+	return nil
+}
+
+func (s *Stack) open(content basic.Node, k kind, place func(g.RectF) g.RectF) *Layer {
+	l := &Layer{stack: s, content: content, kind: k, place: place}
+	l.SetMouseTransparent(k == passive)
+
+	basic.Attach(s, l)
+	basic.Attach(l, content)
+	content.GetReady()
+	s.layers = append(s.layers, l)
+	s.arrange(l)
+
+	if k != passive && s.focus != nil {
+		l.prevFocus = s.focus.Focused()
+		s.focus.SetRoot(l)
+		s.focus.Next()
+	}
+
+	return l
+}
+
+// arrange makes a layer cover the whole stack and places its content.
+func (s *Stack) arrange(l *Layer) {
+	bounds := g.RectF{SizeF: s.Size()}
+	l.SetGeometry(bounds)
+	l.content.SetGeometry(l.place(bounds))
+}
+
+func (s *Stack) close(l *Layer) {
+	i := s.index(l)
+	if i < 0 {
+		return
+	}
+	s.layers = append(s.layers[:i], s.layers[i+1:]...)
+	if s.tooltip == l {
+		s.tooltip = nil
+	}
+
+	if l.content != nil && l.content.Parent() != nil {
+		l.Widget.RemoveChild(l.content)
+	}
+	s.Widget.RemoveChild(l)
+
+	// Focus returns to the layer below, if the closed one had it
+	if s.focus != nil && l.kind != passive {
+		var scope basic.Node = s
+		if top := s.Top(); top != nil {
+			scope = top
+		}
+		if s.focus.Root() != scope {
+			s.focus.SetRoot(scope)
+			if basic.InTree(scope, l.prevFocus) {
+				s.focus.SetFocus(l.prevFocus)
+			}
+		}
+	}
+
+	if l.OnClose != nil {
+		l.OnClose()
+	}
+}
+
+func (s *Stack) index(l *Layer) int {
+	for i, m := range s.layers {
+		if m == l {
+			return i
+		}
+	}
+	return -1
+}
+
+// RemoveChild closes the layer, if a layer is removed.
+func (s *Stack) RemoveChild(n basic.Node) {
+	if l, ok := n.(*Layer); ok && s.index(l) >= 0 {
+		s.close(l)
+		return
+	}
+	s.Widget.RemoveChild(n)
+}
+
+// SetGeometry sets geometry of the stack. The main widget and all the layers
+// are resized to cover it, and layers' contents are placed again.
+func (s *Stack) SetGeometry(r g.RectF) {
+	s.Widget.SetGeometry(r)
+	s.resize()
+}
+
+// SetSize sets size of the stack, see SetGeometry.
+func (s *Stack) SetSize(sz g.SizeF) {
+	s.Widget.SetSize(sz)
+	s.resize()
+}
+
+func (s *Stack) resize() {
+	bounds := g.RectF{SizeF: s.Size()}
+	for _, c := range s.Children() {
+		if l, ok := c.(*Layer); ok {
+			s.arrange(l)
+		} else {
+			c.SetGeometry(bounds)
+		}
+	}
+}
+
+// HandleEvent closes the topmost popup, when Escape is pressed and nobody
+// accepted it.
+func (s *Stack) HandleEvent(e *events.Event) {
+	s.Widget.HandleEvent(e)
+	if e.Accepted() || e.Type != events.KeyPress || e.Key != events.KeyEscape {
+		return
+	}
+
+	if top := s.Top(); top != nil && top.kind == popup {
+		top.Close()
+		e.Accept()
+	}
+}
+
+// Track tells the stack about a mouse event, so it knows when to show tooltips.
+// Windows call it for every mouse event before dispatching it.
+func (s *Stack) Track(e *events.Event) {
+	switch e.Type {
+	case events.MouseMove:
+		s.hoverPos = e.Pos
+
+		var owner basic.Node
+		for n := basic.NodeAt(s, e.Pos); n != nil; n = n.Parent() {
+			if tooltipOf(n) != nil {
+				owner = n
+				break
+			}
+		}
+		if owner != s.hovered {
+			s.hideTooltip()
+			s.hovered = owner
+		}
+	case events.MousePress, events.Scroll:
+		// Tooltip shows up again, if the cursor stays still
+		s.hideTooltip()
+	}
+}
+
+// Update shows the tooltip of the hovered widget, if it's hovered long enough.
+// dt is the time passed since the last call, windows call it every frame.
+func (s *Stack) Update(dt time.Duration) {
+	if s.hovered != nil && !basic.InTree(s, s.hovered) {
+		s.hideTooltip()
+		s.hovered = nil
+	}
+	if s.hovered == nil || s.tooltip != nil {
+		return
+	}
+
+	s.hoverTime += dt
+	if s.hoverTime < s.TooltipDelay {
+		return
+	}
+
+	tip := tooltipOf(s.hovered)
+	if tip == nil {
+		return
+	}
+	anchor := g.RectF{PosF: g.PosF(s.hoverPos), SizeF: g.SizeF{H: TooltipOffset}}
+	size := tip.Geometry().SizeF
+	s.tooltip = s.open(tip, passive, func(bounds g.RectF) g.RectF {
+		return Place(size, anchor, bounds, Below)
+	})
+}
+
+// Tooltip returns the layer showing a tooltip right now or nil.
+func (s *Stack) Tooltip() *Layer {
+	return s.tooltip
+}
+
+func (s *Stack) hideTooltip() {
+	if s.tooltip != nil {
+		s.tooltip.Close()
+	}
+	s.hoverTime = 0
+}
+
+func tooltipOf(n basic.Node) basic.Node {
+	if t, ok := n.(interface {
+		Tooltip() basic.Node
+	}); ok {
+		return t.Tooltip()
+	}
+	return nil
+}
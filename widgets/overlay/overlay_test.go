@@ -0,0 +1,125 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package overlay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Sergobot/Rocky/events"
+	g "github.com/Sergobot/Rocky/geometry"
+	"github.com/Sergobot/Rocky/widgets/basic"
+)
+
+// Builds a stack with a focusable button in the main widget.
+func testStack() (s *Stack, f *basic.FocusManager, button *basic.Widget) {
+	main, button := new(basic.Widget), new(basic.Widget)
+	button.SetGeometry(rect(0, 0, 0.5, 0.5))
+	button.SetFocusable(true)
+	basic.Attach(main, button)
+
+	f = new(basic.FocusManager)
+	s = NewStack(main, f)
+	f.SetRoot(s)
+	s.SetGeometry(rect(0, 0, 2, 1))
+	return
+}
+
+func press(x, y float32) *events.Event {
+	return &events.Event{Type: events.MousePress, Pos: g.PointF{X: x, Y: y}}
+}
+
+func TestPopup(t *testing.T) {
+	s, f, button := testStack()
+	f.SetFocus(button)
+
+	content := new(basic.Widget)
+	content.SetGeometry(rect(0, 0, 0.5, 0.5))
+	content.SetFocusable(true)
+
+	closed := false
+	l := s.Popup(content, button.GlobalGeometry(), Below)
+	l.OnClose = func() { closed = true }
+
+	if got, want := content.Geometry(), rect(0, 0.5, 0.5, 0.5); got != want {
+		t.Errorf("popup geometry = %v, want %v", got, want)
+	}
+	if f.Focused() != content {
+		t.Errorf("focus didn't move to the popup")
+	}
+
+	// Click inside isn't accepted by content, but the popup stays open
+	if n := basic.Dispatch(s, press(0.25, 0.75)); n != l || closed {
+		t.Errorf("click inside: accepted by %v, closed = %v", n, closed)
+	}
+
+	// Click on the button beneath closes the popup and doesn't reach the button
+	var got bool
+	button.SetEventHandler(func(e *events.Event) { got = got || e.IsMouse() })
+	basic.Dispatch(s, press(0.25, 0.25))
+	if !closed || got {
+		t.Errorf("click outside: closed = %v, button got event = %v", closed, got)
+	}
+	if s.Top() != nil || content.Parent() != nil {
+		t.Errorf("popup wasn't removed")
+	}
+	if f.Focused() != button {
+		t.Errorf("focus didn't return to the button")
+	}
+}
+
+func TestModal(t *testing.T) {
+	s, _, button := testStack()
+
+	content := new(basic.Widget)
+	content.SetGeometry(rect(0, 0, 1, 0.5))
+	l := s.Modal(content)
+
+	if got, want := content.Geometry(), rect(0.5, 0.25, 1, 0.5); got != want {
+		t.Errorf("modal geometry = %v, want %v", got, want)
+	}
+
+	// Neither clicks nor Escape close modal layers
+	var got bool
+	button.SetEventHandler(func(*events.Event) { got = true })
+	basic.Dispatch(s, press(0.1, 0.1))
+	basic.Propagate(l, &events.Event{Type: events.KeyPress, Key: events.KeyEscape})
+	if got || s.Top() != l {
+		t.Errorf("button got event = %v, modal open = %v", got, s.Top() == l)
+	}
+
+	// Resizing the window keeps the dialog centered
+	s.SetSize(g.SizeF{W: 1, H: 1})
+	if got, want := content.Geometry(), rect(0, 0.25, 1, 0.5); got != want {
+		t.Errorf("modal geometry after resize = %v, want %v", got, want)
+	}
+}
+
+func TestTooltip(t *testing.T) {
+	s, _, button := testStack()
+
+	tip := new(basic.Widget)
+	tip.SetGeometry(rect(0, 0, 0.5, 0.1))
+	button.SetTooltip(tip)
+
+	s.Track(&events.Event{Type: events.MouseMove, Pos: g.PointF{X: 0.25, Y: 0.25}})
+	s.Update(s.TooltipDelay / 2)
+	if s.Tooltip() != nil {
+		t.Fatalf("tooltip shown too early")
+	}
+	s.Update(s.TooltipDelay / 2)
+	if s.Tooltip() == nil {
+		t.Fatalf("tooltip not shown")
+	}
+
+	// Tooltip doesn't take mouse events
+	if n := basic.NodeAt(s, g.PointF{X: 0.3, Y: 0.3}); n != button {
+		t.Errorf("NodeAt() = %v, want button", n)
+	}
+
+	s.Track(&events.Event{Type: events.MouseMove, Pos: g.PointF{X: 1, Y: 0.5}})
+	s.Update(time.Second)
+	if s.Tooltip() != nil || tip.Parent() != nil {
+		t.Errorf("tooltip not hidden after cursor left")
+	}
+}
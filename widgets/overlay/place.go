@@ -0,0 +1,70 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package overlay
+
+import (
+	g "github.com/Sergobot/Rocky/geometry"
+)
+
+// Side tells at which side of its anchor a popup prefers to appear.
+type Side int
+
+// Sides of the anchor. Below is the usual one for drop-down lists and context
+// menus, RightOf - for submenus.
+const (
+	Below   Side = iota
+	Above   Side = iota
+	RightOf Side = iota
+	LeftOf  Side = iota
+)
+
+// Place returns geometry of a popup of size s anchored to a rect. The popup is
+// put at the preferred side of the anchor, but if it doesn't fit there and
+// there is more room at the opposite side, it's flipped. Then the popup is
+// shifted to stay inside bounds, if it's possible.
+func Place(s g.SizeF, anchor, bounds g.RectF, side Side) g.RectF {
+	r := g.RectF{SizeF: s}
+
+	switch side {
+	case Below, Above:
+		r.Y = placeAlong(s.H, anchor.Y, anchor.H, bounds.Y, bounds.H, side == Above)
+		r.X = clamp(anchor.X, s.W, bounds.X, bounds.W)
+	case RightOf, LeftOf:
+		r.X = placeAlong(s.W, anchor.X, anchor.W, bounds.X, bounds.W, side == LeftOf)
+		r.Y = clamp(anchor.Y, s.H, bounds.Y, bounds.H)
+	}
+
+	return r
+}
+
+// placeAlong places a segment of length size before or after the anchor
+// segment, flipping it if needed. All the arguments are coordinates on a single
+// axis.
+func placeAlong(size, aPos, aLen, bPos, bLen float32, before bool) float32 {
+	roomBefore := aPos - bPos
+	roomAfter := bPos + bLen - (aPos + aLen)
+
+	if before && size > roomBefore && roomAfter > roomBefore {
+		before = false
+	} else if !before && size > roomAfter && roomBefore > roomAfter {
+		before = true
+	}
+
+	pos := aPos + aLen
+	if before {
+		pos = aPos - size
+	}
+	return clamp(pos, size, bPos, bLen)
+}
+
+// clamp shifts a segment to lie inside bounds. If it's longer than bounds, it
+// starts at the beginning of bounds.
+func clamp(pos, size, bPos, bLen float32) float32 {
+	if pos+size > bPos+bLen {
+		pos = bPos + bLen - size
+	}
+	if pos < bPos {
+		pos = bPos
+	}
+	return pos
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package overlay
+
+import (
+	"testing"
+
+	g "github.com/Sergobot/Rocky/geometry"
+)
+
+func rect(x, y, w, h float32) g.RectF {
+	return g.RectF{PosF: g.PosF{X: x, Y: y}, SizeF: g.SizeF{W: w, H: h}}
+}
+
+func TestPlace(t *testing.T) {
+	bounds := rect(0, 0, 2, 1)
+	size := g.SizeF{W: 0.5, H: 0.25}
+
+	cases := []struct {
+		name   string
+		anchor g.RectF
+		side   Side
+		want   g.RectF
+	}{
+		{"below", rect(0.5, 0.25, 0.5, 0.1), Below, rect(0.5, 0.35, 0.5, 0.25)},
+		{"flipped above", rect(0.5, 0.8, 0.5, 0.1), Below, rect(0.5, 0.55, 0.5, 0.25)},
+		{"above", rect(0.5, 0.5, 0.5, 0.1), Above, rect(0.5, 0.25, 0.5, 0.25)},
+		{"flipped below", rect(0.5, 0.1, 0.5, 0.1), Above, rect(0.5, 0.2, 0.5, 0.25)},
+		{"shifted left", rect(1.75, 0.25, 0.25, 0.1), Below, rect(1.5, 0.35, 0.5, 0.25)},
+		{"right of", rect(0.5, 0.25, 0.5, 0.1), RightOf, rect(1, 0.25, 0.5, 0.25)},
+		{"flipped left", rect(1.25, 0.25, 0.5, 0.1), RightOf, rect(0.75, 0.25, 0.5, 0.25)},
+		{"shifted up", rect(0.5, 0.9, 0.5, 0.1), RightOf, rect(1, 0.75, 0.5, 0.25)},
+	}
+	for _, c := range cases {
+		if got := Place(size, c.anchor, bounds, c.side); got != c.want {
+			t.Errorf("%s: Place() = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
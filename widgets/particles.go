@@ -0,0 +1,62 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package widgets
+
+import (
+	"time"
+
+	"github.com/Sergobot/Rocky/animation"
+	"github.com/Sergobot/Rocky/opengl"
+	ogl33 "github.com/Sergobot/Rocky/opengl/gl33"
+	"github.com/Sergobot/Rocky/particles"
+	wgts33 "github.com/Sergobot/Rocky/widgets/gl33"
+)
+
+// ParticleEmitter spawns particles from the center of its geometry: sparks,
+// smoke, explosions and alike. Look at particles.Config to learn what can be
+// tuned. All the particles of an emitter are drawn in a single draw call.
+// ParticleEmitter is an animation: play it with window's Animations() to make
+// particles move.
+type ParticleEmitter interface {
+	Widget
+	animation.Animation
+
+	// Release frees video memory of the ParticleEmitter, but leaves it in the
+	// widget tree, unlike Destroy.
+	Release()
+
+	// Emitter returns the emitter simulating particles
+	Emitter() *particles.Emitter
+
+	SetConfig(particles.Config)
+	Config() particles.Config
+
+	// Burst spawns the given number of particles right away
+	Burst(int)
+
+	// SetEmitting starts or stops spawning particles
+	SetEmitting(bool)
+	Emitting() bool
+
+	// SetTexture sets a texture particles are drawn with, tinted with their
+	// colors. Without one particles are soft round dots.
+	SetTexture(opengl.Texture)
+	Texture() opengl.Texture
+
+	// SetAdditive makes colors of particles add up, which makes them glow
+	SetAdditive(bool)
+	Additive() bool
+
+	// Update moves particles forward in time, if the emitter isn't played
+	// as an animation
+	Update(time.Duration)
+}
+
+// NewParticleEmitter returns a struct, which implements ParticleEmitter
+// interface defined above.
+func NewParticleEmitter(c particles.Config) ParticleEmitter {
+	if ogl33.Initialized() {
+		return wgts33.NewParticleEmitter(c)
+	}
+	return nil
+}
@@ -3,28 +3,23 @@
 package widgets
 
 import (
+	"time"
+
+	"github.com/Sergobot/Rocky/animated"
+	"github.com/Sergobot/Rocky/animation"
 	g "github.com/Sergobot/Rocky/geometry"
 	"github.com/Sergobot/Rocky/opengl"
 	ogl33 "github.com/Sergobot/Rocky/opengl/gl33"
 	wgts33 "github.com/Sergobot/Rocky/widgets/gl33"
+	"github.com/Sergobot/Rocky/widgets/scale"
 )
 
 // Pixmap is one of the simplest widgets, intended to draw raster images
 // using OpenGL. It uses gl**.Texture struct for image loading and gl**.ShaderProgram
 // for drawing.
 type Pixmap interface {
-	// Look in widget.go to learn more about these basic methods
-	GetReady()
-	Draw()
-
-	SetSize(g.SizeF)
-	Size() g.SizeF
-
-	SetPos(g.PosF)
-	Pos() g.PosF
-
-	SetGeometry(g.RectF)
-	Geometry() g.RectF
+	// Look in widget.go to learn more about basic widget methods
+	Widget
 
 	// Pixmap-specific methods are going below
 
@@ -37,6 +32,42 @@ type Pixmap interface {
 	// not an actual value, so every Pixmap with the same texture pointer inside
 	// will have the same image.
 	SetTexture(opengl.Texture)
+	Texture() opengl.Texture
+
+	// SetOpacity sets opacity of the whole Pixmap: 0 is fully transparent,
+	// 1 (default) is opaque.
+	SetOpacity(float32)
+	Opacity() float32
+
+	// SetScaleMode sets the way an image is fitted into Pixmap's geometry:
+	// stretched, fit, filled, tiled and so on. Look in scale package for details.
+	SetScaleMode(scale.Mode)
+	ScaleMode() scale.Mode
+
+	// SetAlignment sets where an image is placed inside Pixmap's geometry, if
+	// it doesn't cover the whole geometry.
+	SetAlignment(g.Alignment)
+	Alignment() g.Alignment
+
+	// SetBatch makes the Pixmap add itself to a sprite batch, when it's begun,
+	// instead of drawing itself. Nil turns it off.
+	SetBatch(*ogl33.SpriteBatch)
+	Batch() *ogl33.SpriteBatch
+
+	// Release frees video memory of the Pixmap, but leaves it in the widget
+	// tree, unlike Destroy. Textures set with SetTexture are not released.
+	Release()
+}
+
+// NinePatch is a Pixmap, which stretches its texture keeping borders intact:
+// corners aren't scaled at all, edges are stretched only along. That's how
+// skinned buttons and panels of any size are drawn from a single small image.
+type NinePatch interface {
+	Pixmap
+
+	// SetBorder sets sizes of the borders in pixels of the texture.
+	SetBorder(g.InsetsF)
+	Border() g.InsetsF
 }
 
 // NewPixmap returns a struct, which implements Pixmap interface defined above.
@@ -46,3 +77,66 @@ func NewPixmap() Pixmap {
 	}
 	return nil
 }
+
+// NewNinePatch returns a struct, which implements NinePatch interface defined above.
+func NewNinePatch() NinePatch {
+	if ogl33.Initialized() {
+		return wgts33.NewNinePatch()
+	}
+	return nil
+}
+
+// NewDragVisual returns a semi-transparent copy of a Pixmap, sharing its texture,
+// scale mode and size. It's intended to be a Visual of basic.Drag.
+func NewDragVisual(p Pixmap) Pixmap {
+	v := NewPixmap()
+	if v == nil {
+		return nil
+	}
+
+	v.SetTexture(p.Texture())
+	v.SetScaleMode(p.ScaleMode())
+	v.SetAlignment(p.Alignment())
+	v.SetOpacity(p.Opacity() / 2)
+	v.SetSize(p.Size())
+	return v
+}
+
+// AnimatedPixmap is a Pixmap playing an animated GIF or APNG, with delays,
+// disposal and loops of the image respected. It's also an animation: play it
+// with window's Animations(), or call Update every frame.
+type AnimatedPixmap interface {
+	Pixmap
+	animation.Animation
+
+	// SetImage sets an animated image, already decoded
+	SetImage(*animated.Image)
+	Image() *animated.Image
+
+	Play()
+	Pause()
+	Playing() bool
+
+	// SetPosition jumps to the given time since the start of the animation
+	SetPosition(time.Duration)
+	Position() time.Duration
+	// Length returns how long a single loop takes
+	Length() time.Duration
+	// Frame returns index of the frame shown
+	Frame() int
+
+	// SetOnFinished sets a function called, when all the loops are played
+	SetOnFinished(func())
+
+	// Update moves the animation forward, unless it's paused
+	Update(time.Duration)
+}
+
+// NewAnimatedPixmap returns a struct, which implements AnimatedPixmap interface
+// defined above.
+func NewAnimatedPixmap() AnimatedPixmap {
+	if ogl33.Initialized() {
+		return wgts33.NewAnimatedPixmap()
+	}
+	return nil
+}
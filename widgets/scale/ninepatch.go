@@ -0,0 +1,47 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package scale
+
+import (
+	g "github.com/Sergobot/Rocky/geometry"
+)
+
+// NinePatch splits an image of size img into a 3x3 grid by border and
+// calculates where each of the nine parts is drawn to cover bounds: corners
+// keep their size, edges are stretched along and the center both ways. img,
+// border and bounds must be in the same units. Parts are ordered row by row,
+// starting at the top-left corner; src rects are in texture coordinates.
+// If bounds are smaller than the borders, the borders shrink proportionally.
+func NinePatch(img g.SizeF, border g.InsetsF, bounds g.RectF) (dst, src [9]g.RectF) {
+	if img.W <= 0 || img.H <= 0 {
+		return
+	}
+
+	// Borders in bounds may be smaller than in the image
+	kx, ky := float32(1), float32(1)
+	if w := border.Left + border.Right; w > bounds.W && w > 0 {
+		kx = bounds.W / w
+	}
+	if h := border.Top + border.Bottom; h > bounds.H && h > 0 {
+		ky = bounds.H / h
+	}
+
+	dstX := [4]float32{bounds.X, bounds.X + border.Left*kx, bounds.X + bounds.W - border.Right*kx, bounds.X + bounds.W}
+	dstY := [4]float32{bounds.Y, bounds.Y + border.Top*ky, bounds.Y + bounds.H - border.Bottom*ky, bounds.Y + bounds.H}
+	srcX := [4]float32{0, border.Left / img.W, 1 - border.Right/img.W, 1}
+	srcY := [4]float32{0, border.Top / img.H, 1 - border.Bottom/img.H, 1}
+
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			i := row*3 + col
+			dst[i] = span(dstX[col], dstY[row], dstX[col+1], dstY[row+1])
+			src[i] = span(srcX[col], srcY[row], srcX[col+1], srcY[row+1])
+		}
+	}
+	return
+}
+
+// span returns a rect between two corners.
+func span(x0, y0, x1, y1 float32) g.RectF {
+	return g.RectF{PosF: g.PosF{X: x0, Y: y0}, SizeF: g.SizeF{W: x1 - x0, H: y1 - y0}}
+}
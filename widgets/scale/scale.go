@@ -0,0 +1,110 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package scale
+
+import (
+	"math"
+
+	g "github.com/Sergobot/Rocky/geometry"
+)
+
+// Mode variables tell how an image is fitted into a widget's geometry.
+type Mode int
+
+// Available scale modes. Stretch is the default one, it's what Pixmap always did.
+const (
+	// Stretch stretches an image to fill the whole rect, ignoring aspect ratio.
+	Stretch Mode = iota
+	// Fit scales an image to fit inside the rect, keeping aspect ratio.
+	// Free space is left empty (letterboxing).
+	Fit Mode = iota
+	// Fill scales an image to cover the whole rect, keeping aspect ratio.
+	// Parts sticking out of the rect are cropped.
+	Fill Mode = iota
+	// Tile repeats an image in its native size all over the rect.
+	Tile Mode = iota
+	// Center draws an image in its native size. It's cropped if it doesn't fit.
+	Center Mode = iota
+	// PixelPerfect scales an image by the biggest integer factor that still fits
+	// into the rect. If an image doesn't fit even in its native size, it's
+	// downscaled by an integer divisor instead.
+	PixelPerfect Mode = iota
+)
+
+// Place calculates where an image of size img is drawn inside bounds.
+// img and bounds must be in the same units. It returns two rects: dst is an
+// area inside bounds covered by the image and src is a part of the image to
+// draw there, in texture coordinates, where {0, 0, 1, 1} is the whole image.
+// In Tile mode src is bigger than that and is meant to be wrapped.
+func Place(m Mode, img g.SizeF, bounds g.RectF, a g.Alignment) (dst, src g.RectF) {
+	src = g.RectF{SizeF: g.SizeF{W: 1, H: 1}}
+
+	// There is nothing to scale, so just stretch
+	if img.W <= 0 || img.H <= 0 {
+		return bounds, src
+	}
+
+	var k float32
+	switch m {
+	case Fit:
+		k = min32(bounds.W/img.W, bounds.H/img.H)
+	case Fill:
+		k = max32(bounds.W/img.W, bounds.H/img.H)
+	case Center:
+		k = 1
+	case PixelPerfect:
+		k = min32(bounds.W/img.W, bounds.H/img.H)
+		if k >= 1 {
+			k = float32(math.Floor(float64(k)))
+		} else {
+			k = 1 / float32(math.Ceil(float64(1/k)))
+		}
+	case Tile:
+		// Align a single tile and start tiling from its position
+		first := a.Align(img, bounds)
+		src.X = (bounds.X - first.X) / img.W
+		src.Y = (bounds.Y - first.Y) / img.H
+		src.W = bounds.W / img.W
+		src.H = bounds.H / img.H
+		return bounds, src
+	default:
+		return bounds, src
+	}
+
+	dst = a.Align(g.SizeF{W: img.W * k, H: img.H * k}, bounds)
+	return crop(dst, bounds)
+}
+
+// crop cuts dst to fit inside bounds and returns the visible part with
+// texture coordinates of that part.
+func crop(dst, bounds g.RectF) (g.RectF, g.RectF) {
+	x0 := max32(dst.X, bounds.X)
+	y0 := max32(dst.Y, bounds.Y)
+	x1 := min32(dst.X+dst.W, bounds.X+bounds.W)
+	y1 := min32(dst.Y+dst.H, bounds.Y+bounds.H)
+
+	vis := g.RectF{
+		PosF:  g.PosF{X: x0, Y: y0},
+		SizeF: g.SizeF{W: x1 - x0, H: y1 - y0},
+	}
+	src := g.RectF{
+		PosF:  g.PosF{X: (x0 - dst.X) / dst.W, Y: (y0 - dst.Y) / dst.H},
+		SizeF: g.SizeF{W: vis.W / dst.W, H: vis.H / dst.H},
+	}
+
+	return vis, src
+}
+
+func min32(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max32(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
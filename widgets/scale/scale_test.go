@@ -0,0 +1,71 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package scale
+
+import (
+	"testing"
+
+	g "github.com/Sergobot/Rocky/geometry"
+)
+
+var (
+	bounds = g.RectF{SizeF: g.SizeF{W: 4, H: 2}}
+	whole  = g.RectF{SizeF: g.SizeF{W: 1, H: 1}}
+)
+
+func rect(x, y, w, h float32) g.RectF {
+	return g.RectF{PosF: g.PosF{X: x, Y: y}, SizeF: g.SizeF{W: w, H: h}}
+}
+
+func TestPlace(t *testing.T) {
+	cases := []struct {
+		name     string
+		mode     Mode
+		img      g.SizeF
+		align    g.Alignment
+		dst, src g.RectF
+	}{
+		{"stretch", Stretch, g.SizeF{W: 1, H: 1}, g.AlignCenter, bounds, whole},
+		{"fit", Fit, g.SizeF{W: 1, H: 1}, g.AlignCenter, rect(1, 0, 2, 2), whole},
+		{"fit left", Fit, g.SizeF{W: 1, H: 1}, g.AlignLeft, rect(0, 0, 2, 2), whole},
+		{"fill", Fill, g.SizeF{W: 1, H: 1}, g.AlignCenter, bounds, rect(0, 0.25, 1, 0.5)},
+		{"center", Center, g.SizeF{W: 1, H: 1}, g.AlignCenter, rect(1.5, 0.5, 1, 1), whole},
+		{"center cropped", Center, g.SizeF{W: 8, H: 1}, g.AlignCenter, rect(0, 0.5, 4, 1), rect(0.25, 0, 0.5, 1)},
+		{"pixel perfect up", PixelPerfect, g.SizeF{W: 1.5, H: 0.5}, g.AlignLeft | g.AlignTop, rect(0, 0, 3, 1), whole},
+		{"pixel perfect down", PixelPerfect, g.SizeF{W: 6, H: 1}, g.AlignLeft | g.AlignTop, rect(0, 0, 3, 0.5), whole},
+		{"tile", Tile, g.SizeF{W: 1, H: 1}, g.AlignLeft | g.AlignTop, bounds, rect(0, 0, 4, 2)},
+		{"tile centered", Tile, g.SizeF{W: 1, H: 1}, g.AlignCenter, bounds, rect(-1.5, -0.5, 4, 2)},
+	}
+
+	for _, c := range cases {
+		dst, src := Place(c.mode, c.img, bounds, c.align)
+		if dst != c.dst {
+			t.Errorf("%s: dst = %v, want %v", c.name, dst, c.dst)
+		}
+		if src != c.src {
+			t.Errorf("%s: src = %v, want %v", c.name, src, c.src)
+		}
+	}
+}
+
+func TestNinePatch(t *testing.T) {
+	img := g.SizeF{W: 4, H: 4}
+	border := g.UniformInsets(1)
+
+	dst, src := NinePatch(img, border, rect(0, 0, 10, 6))
+	if want := rect(0, 0, 1, 1); dst[0] != want || src[0] != rect(0, 0, 0.25, 0.25) {
+		t.Errorf("top-left: dst = %v, src = %v", dst[0], src[0])
+	}
+	if want := rect(1, 1, 8, 4); dst[4] != want || src[4] != rect(0.25, 0.25, 0.5, 0.5) {
+		t.Errorf("center: dst = %v, src = %v", dst[4], src[4])
+	}
+	if want := rect(9, 5, 1, 1); dst[8] != want {
+		t.Errorf("bottom-right: dst = %v, want %v", dst[8], want)
+	}
+
+	// Too small bounds shrink the borders
+	dst, _ = NinePatch(img, border, rect(0, 0, 1, 6))
+	if want := rect(0, 0, 0.5, 1); dst[0] != want {
+		t.Errorf("shrunk top-left: dst = %v, want %v", dst[0], want)
+	}
+}
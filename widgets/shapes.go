@@ -0,0 +1,86 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package widgets
+
+import (
+	g "github.com/Sergobot/Rocky/geometry"
+	ogl33 "github.com/Sergobot/Rocky/opengl/gl33"
+	"github.com/Sergobot/Rocky/paint"
+	wgts33 "github.com/Sergobot/Rocky/widgets/gl33"
+)
+
+// Shape is a widget drawing a simple geometric figure, filled with a color or
+// a gradient. Shapes are anti-aliased, so they look nice at any size.
+type Shape interface {
+	Widget
+
+	// SetColor sets solid fill color. Use paint.Transparent to get an outline.
+	SetColor(paint.Color)
+	Color() paint.Color
+
+	// SetGradient sets a gradient to fill the shape with instead of a solid
+	// color. Pass nil to use the solid color again.
+	SetGradient(*paint.Gradient)
+	Gradient() *paint.Gradient
+
+	// SetBorder sets width (in the same units as geometry) and color of the
+	// border, drawn inside the shape.
+	SetBorder(float32, paint.Color)
+	Border() (float32, paint.Color)
+}
+
+// Rectangle is a shape with optionally rounded corners. Each corner may have its
+// own radius.
+type Rectangle interface {
+	Shape
+
+	SetRadii(paint.Radii)
+	Radii() paint.Radii
+}
+
+// Ellipse is a shape inscribed in widget's geometry. Make geometry square to get
+// a circle.
+type Ellipse interface {
+	Shape
+}
+
+// Line is a polyline of given thickness. Points are relative to top-left corner
+// of line's geometry. Line is filled with its color or gradient, border is not
+// drawn.
+type Line interface {
+	Shape
+
+	SetPoints([]g.PointF)
+	Points() []g.PointF
+
+	// Thickness is measured in the same units as geometry
+	SetThickness(float32)
+	Thickness() float32
+
+	SetCap(paint.Cap)
+	Cap() paint.Cap
+}
+
+// NewRectangle returns a struct, which implements Rectangle interface defined above.
+func NewRectangle() Rectangle {
+	if ogl33.Initialized() {
+		return wgts33.NewRectangle()
+	}
+	return nil
+}
+
+// NewEllipse returns a struct, which implements Ellipse interface defined above.
+func NewEllipse() Ellipse {
+	if ogl33.Initialized() {
+		return wgts33.NewEllipse()
+	}
+	return nil
+}
+
+// NewLine returns a struct, which implements Line interface defined above.
+func NewLine() Line {
+	if ogl33.Initialized() {
+		return wgts33.NewLine()
+	}
+	return nil
+}
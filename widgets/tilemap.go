@@ -0,0 +1,55 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package widgets
+
+import (
+	"github.com/Sergobot/Rocky/animation"
+	g "github.com/Sergobot/Rocky/geometry"
+	ogl33 "github.com/Sergobot/Rocky/opengl/gl33"
+	"github.com/Sergobot/Rocky/tilemap"
+	wgts33 "github.com/Sergobot/Rocky/widgets/gl33"
+)
+
+// TileMap draws tile layers of a map made in Tiled. Only the part of the map
+// inside the widget is drawn, tiles of each tileset in a single batch, so
+// levels of any size are fine. Object layers are not drawn, look at them in
+// Map().Layers to place things in the level.
+// TileMap is also an animation driving animated tiles: play it with window's
+// Animations() to make them move.
+type TileMap interface {
+	Widget
+	animation.Animation
+
+	// Release frees video memory of the TileMap, including tileset images,
+	// but leaves it in the widget tree, unlike Destroy.
+	Release()
+
+	// LoadFromFile loads a map from a TMX or JSON file exported by Tiled
+	LoadFromFile(string)
+
+	// SetMap sets a map to draw, loading images of its tilesets
+	SetMap(*tilemap.Map)
+	Map() *tilemap.Map
+
+	// SetScroll sets the point of the map (in its pixels) shown at the top-left
+	// corner of the widget.
+	SetScroll(g.PointF)
+	Scroll() g.PointF
+
+	// SetZoom sets how many screen pixels a pixel of the map takes, 1 by default
+	SetZoom(float32)
+	Zoom() float32
+
+	// MapPoint converts a point in window coordinates to map pixels
+	MapPoint(g.PointF) g.PointF
+	// CellAt returns the cell of the map under a point in window coordinates
+	CellAt(g.PointF) (x, y int, ok bool)
+}
+
+// NewTileMap returns a struct, which implements TileMap interface defined above.
+func NewTileMap() TileMap {
+	if ogl33.Initialized() {
+		return wgts33.NewTileMap()
+	}
+	return nil
+}
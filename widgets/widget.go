@@ -2,7 +2,12 @@
 
 package widgets
 
-import g "github.com/Sergobot/Rocky/geometry"
+import (
+	"github.com/Sergobot/Rocky/events"
+	g "github.com/Sergobot/Rocky/geometry"
+	"github.com/Sergobot/Rocky/theme"
+	"github.com/Sergobot/Rocky/widgets/basic"
+)
 
 // Widget is an interface for objects, each of which a separate piece of
 // window's space. There are many possible examples of widgets:
@@ -11,21 +16,22 @@ import g "github.com/Sergobot/Rocky/geometry"
 // - Images
 // - Text inputs
 // - And even more!
+//
+// Widgets form a tree: each one may have a parent and children (look at
+// Container to learn how to build one). Geometry of a widget is relative to its
+// parent.
 type Widget interface {
-	// GetReady does everything about initialization before drawing.
-	// Usually it's called in AddWidget method of a window, so there is no need
-	// to call it manually anywhere else.
-	GetReady()
-
-	// TODO:
-	// - Make widgets able to react on user's actions (Update method corresponds to that)
-
-	// Update updates widget's contents and reacts to events (if there are some).
-	// Also, Draw() method is called as one of update steps.
-	//Update()
-
-	// Draw draws something inside widget's space.
-	Draw()
+	// basic.Node contains methods required to place a widget in a widget tree:
+	// - GetReady does everything about initialization before drawing.
+	//   Usually it's called in AddWidget method of a layout, so there is no need
+	//   to call it manually anywhere else.
+	// - Draw draws something inside widget's space, but not its children.
+	// - [Set]Geometry sets/gets widget's bounding box relative to its parent.
+	// - Parent, Children and RemoveChild deal with the tree itself.
+	// - HandleEvent reacts to events delivered to the widget.
+	// - Destroy frees resources of the widget and all its children and removes
+	//   it from its parent.
+	basic.Node
 
 	// Some basic methods to contol widget's size and position.
 	SetSize(g.SizeF)
@@ -34,7 +40,60 @@ type Widget interface {
 	SetPos(g.PosF)
 	Pos() g.PosF
 
-	// These two are usually aliases for separate size and pos set/get methods
-	SetGeometry(g.RectF)
-	Geometry() g.RectF
+	// GlobalGeometry returns widget's geometry in window coordinates.
+	GlobalGeometry() g.RectF
+
+	// These two convert points between widget's own and window coordinates.
+	MapToGlobal(g.PointF) g.PointF
+	MapFromGlobal(g.PointF) g.PointF
+
+	// SetEventHandler sets a function called for every event delivered to the
+	// widget, unless the widget handles events on its own.
+	SetEventHandler(func(*events.Event))
+
+	// Keyboard focus. Only focusable widgets may be focused, with a click, Tab
+	// key or arrow keys. Focused widget receives all the key events.
+	SetFocusable(bool)
+	Focusable() bool
+	HasFocus() bool
+
+	// Mouse transparent widgets and their children don't get mouse events,
+	// whatever is under them gets them instead.
+	SetMouseTransparent(bool)
+	MouseTransparent() bool
+
+	// Tooltip is a widget shown near the cursor when it hovers the widget for
+	// a while.
+	SetTooltip(basic.Node)
+	Tooltip() basic.Node
+
+	// Style class is used in theme selectors, like "Slider.volume". Style
+	// overrides theme properties for a single widget.
+	SetStyleClass(string)
+	StyleClass() string
+	SetStyle(*theme.Style)
+	Style() *theme.Style
+
+	// Hidden widgets aren't drawn and don't get mouse events. Layouts give
+	// them no space, unless they reserve it.
+	SetVisible(bool)
+	Visible() bool
+	SetReserveSpace(bool)
+	ReserveSpace() bool
+
+	// Disabled widgets ignore input and are drawn greyed out.
+	SetEnabled(bool)
+	Enabled() bool
+
+	// Z-index controls the order siblings are drawn in: bigger ones are on top.
+	SetZ(int)
+	Z() int
+
+	// Rotation in radians around a pivot, which is relative to widget's size.
+	// Children are rotated together with their parent.
+	SetRotation(float32)
+	Rotation() float32
+	SetPivot(g.PointF)
+	Pivot() g.PointF
+	GlobalTransform() g.Transform
 }
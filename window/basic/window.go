@@ -3,18 +3,43 @@
 package basic
 
 import (
+	"github.com/Sergobot/Rocky/animation"
+	"github.com/Sergobot/Rocky/events"
 	g "github.com/Sergobot/Rocky/geometry"
+	"github.com/Sergobot/Rocky/layouts"
+	"github.com/Sergobot/Rocky/widgets/basic"
+	"github.com/Sergobot/Rocky/widgets/container"
+	"github.com/Sergobot/Rocky/widgets/overlay"
 	"github.com/Sergobot/Rocky/window/state"
 )
 
 // Window is used to be embedded in other, more specific window structs.
-// It deals only with geometry, state and *nothing* more. But anyway there are all the
-// methods required to be Window, so this is (almost) an abstract window.
+// It deals only with geometry, state and widget tree, *nothing* more. But anyway
+// there are all the methods required to be Window, so this is (almost) an
+// abstract window.
 // Please don't perform any operations on Window's members directly, use
 // Window's methods instead.
 type Window struct {
 	geometry g.Rect
 	state    state.State
+
+	// Container holding the layout. It's created when a layout is set.
+	root *container.Container
+
+	// Root of the widget tree: root container with popups and dialogs above it
+	stack *overlay.Stack
+
+	// Keyboard focus of the widget tree
+	focus basic.FocusManager
+	// Drag and drop in progress in the widget tree
+	drags basic.DragManager
+
+	// Widget which accepted the last mouse press. It gets all the mouse events
+	// until the button is released.
+	grabber basic.Node
+
+	// Animations updated every frame
+	animations *animation.Player
 }
 
 // SetGeometry sets geometry (bounding box) of a window.
@@ -45,6 +70,16 @@ func (w *Window) Hide() {
 func (w *Window) Destroy() {
 	w.state = state.NotInitialized
 
+	// Widgets may hold OpenGL objects, which are useless without a window
+	if w.stack != nil {
+		w.drags.SetRoot(nil)
+		w.grabber = nil
+		w.stack.Destroy()
+		w.focus.SetRoot(nil)
+		w.stack = nil
+		w.root = nil
+	}
+
 	// It's safe enough, right?
 	w.geometry = *new(g.Rect)
 }
@@ -55,11 +90,119 @@ func (w *Window) State() state.State {
 	return w.state
 }
 
-// TODO: Add support for layouts
-func (w *Window) SetLayout() {
+// SetLayout sets a layout of widgets to the window. The layout is held by
+// the root container, which covers the whole window.
+func (w *Window) SetLayout(l layouts.Layout) {
+	if w.root == nil {
+		w.root = container.New(l)
+		w.stack = overlay.NewStack(w.root, &w.focus)
+		w.focus.SetRoot(w.stack)
+		w.drags.SetRoot(w.stack)
+		return
+	}
+	w.root.SetLayout(l)
+}
+
+// Layout returns the layout set to the window or nil, if there is none.
+func (w *Window) Layout() layouts.Layout {
+	if w.root == nil {
+		return nil
+	}
+	return w.root.Layout()
+}
+
+// Root returns root container of the window's widget tree or nil, if no layout
+// was set yet.
+func (w *Window) Root() *container.Container {
+	return w.root
+}
+
+// Animations returns the player of animations updated every frame, right
+// before the widgets are drawn.
+func (w *Window) Animations() *animation.Player {
+	if w.animations == nil {
+		w.animations = animation.NewPlayer()
+	}
+	return w.animations
+}
 
+// Overlay returns the stack of popups, dialogs and tooltips shown above the
+// root container. It's the real root of the window's widget tree. It's nil
+// until a layout is set.
+func (w *Window) Overlay() *overlay.Stack {
+	return w.stack
 }
 
-func (w *Window) Layout() {
+// Focus returns focus manager of the window's widget tree.
+func (w *Window) Focus() *basic.FocusManager {
+	return &w.focus
+}
+
+// Drags returns drag manager of the window's widget tree. Widgets start drags
+// with it.
+func (w *Window) Drags() *basic.DragManager {
+	return &w.drags
+}
+
+// Dispatch delivers an event to the window's widgets. More specific windows
+// call it when they receive events from the OS.
+// Mouse events go to the widget under the cursor, and a click focuses the
+// nearest focusable widget there. A widget accepting a mouse press grabs the
+// mouse: it gets all the mouse events until the button is released, even if
+// the cursor leaves it. Key events go to the focused widget, and if nobody
+// accepts them, they are used for focus navigation. If there is a drag in
+// progress, it gets the events first. Popups and modal dialogs are above the
+// root container, so they get mouse events first and keep the focus inside.
+func (w *Window) Dispatch(e *events.Event) {
+	if w.stack == nil {
+		return
+	}
+
+	if w.drags.Handle(e) {
+		if e.Type == events.MouseRelease {
+			w.grabber = nil
+		}
+		return
+	}
+
+	if e.IsMouse() {
+		w.stack.Track(e)
+		w.dispatchMouse(e)
+		return
+	}
+
+	target := w.focus.Focused()
+	if target == nil {
+		target = w.focus.Root()
+	}
+	basic.Propagate(target, e)
+
+	if !e.Accepted() {
+		w.focus.HandleKey(e)
+	}
+}
+
+// dispatchMouse delivers a mouse event, respecting the mouse grab.
+func (w *Window) dispatchMouse(e *events.Event) {
+	if w.grabber != nil && !basic.InTree(w.stack, w.grabber) {
+		// Grabber was removed from the tree
+		w.grabber = nil
+	}
+
+	if w.grabber != nil {
+		basic.Propagate(w.grabber, e)
+		if e.Type == events.MouseRelease {
+			w.grabber = nil
+		}
+		return
+	}
+
+	if e.Type == events.MousePress {
+		w.focus.FocusAt(e.Pos)
+	}
 
+	accepted := basic.Dispatch(w.stack, e)
+	if e.Type == events.MousePress {
+		w.grabber = accepted
+	}
 }
@@ -0,0 +1,84 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package glfw
+
+import (
+	"github.com/go-gl/glfw/v3.2/glfw"
+
+	"github.com/Sergobot/Rocky/events"
+	g "github.com/Sergobot/Rocky/geometry"
+)
+
+// setCallbacks makes GLFW window pass all the input to Rocky window's widgets.
+func (w *Window) setCallbacks() {
+	w.window.SetCursorPosCallback(func(_ *glfw.Window, x, y float64) {
+		w.cursor = w.normalize(x, y)
+		w.Dispatch(&events.Event{Type: events.MouseMove, Pos: w.cursor})
+	})
+
+	w.window.SetMouseButtonCallback(func(_ *glfw.Window, b glfw.MouseButton, a glfw.Action, m glfw.ModifierKey) {
+		e := &events.Event{
+			Type:   events.MousePress,
+			Pos:    w.cursor,
+			Button: events.MouseButton(b),
+			Mods:   events.Modifier(m),
+		}
+		if a == glfw.Release {
+			e.Type = events.MouseRelease
+		}
+		// Debug overlay takes clicks to select widgets
+		if w.debug.HandleEvent(w.tree(), e) {
+			return
+		}
+		w.Dispatch(e)
+	})
+
+	w.window.SetScrollCallback(func(_ *glfw.Window, x, y float64) {
+		w.Dispatch(&events.Event{
+			Type:   events.Scroll,
+			Pos:    w.cursor,
+			Scroll: g.PointF{X: float32(x), Y: float32(y)},
+		})
+	})
+
+	w.window.SetKeyCallback(func(_ *glfw.Window, k glfw.Key, _ int, a glfw.Action, m glfw.ModifierKey) {
+		e := &events.Event{
+			Pos:  w.cursor,
+			Key:  events.Key(k),
+			Mods: events.Modifier(m),
+		}
+		switch a {
+		case glfw.Press:
+			e.Type = events.KeyPress
+		case glfw.Repeat:
+			e.Type = events.KeyRepeat
+		default:
+			e.Type = events.KeyRelease
+		}
+		if e.Key == events.KeyF12 && e.Type == events.KeyPress {
+			w.debug.Toggle()
+			return
+		}
+		w.Dispatch(e)
+	})
+
+	w.window.SetCharCallback(func(_ *glfw.Window, r rune) {
+		w.Dispatch(&events.Event{Type: events.Char, Pos: w.cursor, Char: r})
+	})
+}
+
+// normalize converts cursor position from GLFW screen coordinates to our
+// normalized ones, where bigger side of the window is 2.0 long.
+func (w *Window) normalize(x, y float64) g.PointF {
+	width, height := w.window.GetSize()
+	side := width
+	if height > side {
+		side = height
+	}
+	if side == 0 {
+		return g.PointF{}
+	}
+
+	k := 2 / float64(side)
+	return g.PointF{X: float32(x * k), Y: float32(y * k)}
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2016 Sergey Popov <sergobot@protonmail.com>
+
+package glfw
+
+import (
+	"github.com/go-gl/glfw/v3.2/glfw"
+
+	"github.com/Sergobot/Rocky/events"
+)
+
+// Stick has to be tilted further than that to count as a D-pad press.
+const stickThreshold = 0.5
+
+// gamepadKeys are keys emulated by gamepad. First four are directions of the
+// left stick: left, right, up and down. Then go buttons, usually A and B.
+var gamepadKeys = [...]events.Key{
+	events.KeyLeft,
+	events.KeyRight,
+	events.KeyUp,
+	events.KeyDown,
+	events.KeyEnter,
+	events.KeyEscape,
+}
+
+// pollGamepad reads the state of the first joystick and turns it into key
+// events, so widgets may be controlled by a gamepad just like by a keyboard:
+// left stick works as arrow keys, first button as Enter and second as Escape.
+// GLFW has no joystick callbacks, so that's done every frame.
+func (w *Window) pollGamepad() {
+	var pressed [len(gamepadKeys)]bool
+
+	if glfw.JoystickPresent(glfw.Joystick1) {
+		axes := glfw.GetJoystickAxes(glfw.Joystick1)
+		if len(axes) >= 2 {
+			pressed[0] = axes[0] < -stickThreshold
+			pressed[1] = axes[0] > stickThreshold
+			pressed[2] = axes[1] < -stickThreshold
+			pressed[3] = axes[1] > stickThreshold
+		}
+
+		buttons := glfw.GetJoystickButtons(glfw.Joystick1)
+		for i := 0; i < 2 && i < len(buttons); i++ {
+			pressed[4+i] = glfw.Action(buttons[i]) == glfw.Press
+		}
+	}
+
+	for i, p := range pressed {
+		if p == w.gamepad[i] {
+			continue
+		}
+		e := &events.Event{Type: events.KeyPress, Pos: w.cursor, Key: gamepadKeys[i]}
+		if !p {
+			e.Type = events.KeyRelease
+		}
+		w.Dispatch(e)
+	}
+	w.gamepad = pressed
+}
@@ -4,11 +4,18 @@ package glfw
 
 import (
 	"log"
+	"time"
 
 	"github.com/go-gl/gl/v3.3-core/gl"
 	"github.com/go-gl/glfw/v3.2/glfw"
 
+	g "github.com/Sergobot/Rocky/geometry"
 	"github.com/Sergobot/Rocky/opengl/gl33"
+	"github.com/Sergobot/Rocky/paint"
+	"github.com/Sergobot/Rocky/widgets"
+	wbasic "github.com/Sergobot/Rocky/widgets/basic"
+	"github.com/Sergobot/Rocky/widgets/debug"
+	wgl33 "github.com/Sergobot/Rocky/widgets/gl33"
 	"github.com/Sergobot/Rocky/window/basic"
 	"github.com/Sergobot/Rocky/window/state"
 )
@@ -18,7 +25,7 @@ import (
 // in future releases.
 // What Window does:
 // - Manage GLFW window and OpenGL context in it;
-// - That's all for this moment.
+// - Draw widgets and deliver user's input to them.
 type Window struct {
 	// Embed basic.window to match basic.Window interface and to get some very basic
 	// methods, like [Set]Geometry() and others. However, we still need to reimplement
@@ -26,6 +33,21 @@ type Window struct {
 	basic.Window
 
 	window *glfw.Window
+
+	// Last known cursor position in normalized coordinates
+	cursor g.PointF
+
+	// Keys emulated by gamepad which are held down, see pollGamepad
+	gamepad [len(gamepadKeys)]bool
+
+	// Rectangle drawn around the focused widget
+	focusRing *wgl33.Rectangle
+
+	// Debug overlay, toggled with F12
+	debug *debug.Overlay
+
+	// Time of the last Update in seconds, as returned by glfw.GetTime
+	lastUpdate float64
 }
 
 // create creates a full-screen window with OpenGL 3.3 context in it. It is usually
@@ -83,6 +105,25 @@ func (w *Window) create() {
 	gl33.SetViewport(0, 0, int32(fbWidth), int32(fbHeight))
 	gl.ClearColor(0.0, 0.0, 0.0, 1.0)
 
+	// Widgets may be semi-transparent, for example images with alpha channel
+	// or anti-aliased edges of shapes
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+
+	w.setCallbacks()
+
+	// Focus ring is just an outline drawn a bit outside of the focused widget
+	px := gl33.NormalizedPixelSize()
+	w.focusRing = wgl33.NewRectangle()
+	w.focusRing.SetColor(paint.Transparent)
+	w.focusRing.SetBorder(2*px, paint.Color{R: 1, G: 0.8, B: 0.2, A: 1})
+	w.focusRing.SetRadii(paint.UniformRadii(4 * px))
+	w.focusRing.GetReady()
+
+	w.debug = debug.New()
+
+	w.lastUpdate = glfw.GetTime()
+
 	// Since window is already shown during glfw.CreateWindow(), we need to set
 	// appropriate State
 	w.Window.Show()
@@ -122,7 +163,120 @@ func (w *Window) Hide() {
 func (w *Window) Destroy() {
 	// We don't need to destroy an already destroyed/not initialized window.
 	if w.State() != state.NotInitialized {
-		w.window.Destroy()
+		// Widgets are destroyed first, while OpenGL context is still alive
 		w.Window.Destroy()
+		w.focusRing.Destroy()
+		w.focusRing = nil
+		w.debug.Destroy()
+		w.debug = nil
+		wgl33.ReleaseShaders()
+		gl33.ReportLeaks()
+		w.window.Destroy()
+	}
+}
+
+// Update draws the widget tree, shows the result and processes pending events.
+// Call it once per frame.
+func (w *Window) Update() {
+	if w.State() == state.NotInitialized {
+		log.Println("Failed to update a window: Window is not initialized.")
+		return
+	}
+
+	now := glfw.GetTime()
+	dt := time.Duration((now - w.lastUpdate) * float64(time.Second))
+	w.lastUpdate = now
+	w.debug.Frame(dt)
+
+	// Objects of textures and programs garbage collected are deleted here, on
+	// the OpenGL thread
+	gl33.DeletePending()
+
+	// Files changed are reloaded before drawing, so they are seen this frame
+	if r := gl33.HotReloader(); r != nil {
+		r.Poll()
+		w.debug.SetError(r.Err())
+	}
+
+	// Counters are per frame
+	gl33.ResetStats()
+	gl.Clear(gl.COLOR_BUFFER_BIT)
+
+	w.Animations().Update(dt)
+
+	if stack := w.Overlay(); stack != nil {
+		// Widget tree always covers the whole viewport
+		vp := g.RectF{SizeF: gl33.NormalizedViewportSize()}
+		if stack.Geometry() != vp {
+			stack.SetGeometry(vp)
+		}
+		stack.Update(dt)
+		wbasic.DrawTree(stack)
+		w.drawFocusRing()
+
+		// Drag visual is above everything else
+		if d := w.Drags().Current(); d != nil && d.Visual != nil {
+			d.Visual.Draw()
+		}
+	}
+
+	// Stats are taken before the overlay is drawn, so it doesn't count itself
+	w.debug.Draw(w.tree(), gl33.CurrentStats())
+
+	w.window.SwapBuffers()
+	glfw.PollEvents()
+	w.pollGamepad()
+}
+
+// FocusRing returns the rectangle drawn around the focused widget. Change its
+// border, color or radii to restyle it. Its geometry is set automatically.
+// It's nil until the window is shown for the first time.
+func (w *Window) FocusRing() widgets.Rectangle {
+	if w.focusRing == nil {
+		return nil
+	}
+	return w.focusRing
+}
+
+// drawFocusRing draws focus ring around the focused widget, if there is one.
+func (w *Window) drawFocusRing() {
+	focused := w.Focus().Focused()
+	if focused == nil || w.focusRing == nil {
+		return
+	}
+
+	// Ring goes around the widget, so its border doesn't cover widget's contents
+	width, _ := w.focusRing.Border()
+	r := wbasic.GlobalGeometry(focused)
+	r.X -= width
+	r.Y -= width
+	r.W += 2 * width
+	r.H += 2 * width
+
+	// Ring is rotated together with the widget
+	w.focusRing.SetGeometry(r)
+	wbasic.WithTransform(wbasic.GlobalTransform(focused), w.focusRing.Draw)
+}
+
+// tree returns the root of the widget tree or nil.
+func (w *Window) tree() wbasic.Node {
+	if stack := w.Overlay(); stack != nil {
+		return stack
+	}
+	return nil
+}
+
+// DebugOverlay returns the overlay showing frame rate, OpenGL counters and
+// bounds of widgets. It's toggled with F12, or with its methods. It's nil until
+// the window is shown for the first time.
+func (w *Window) DebugOverlay() *debug.Overlay {
+	return w.debug
+}
+
+// ShouldClose returns true if user tried to close the window.
+func (w *Window) ShouldClose() bool {
+	if w.State() == state.NotInitialized {
+		return false
 	}
+	return w.window.ShouldClose()
 }
@@ -8,7 +8,14 @@
 package window
 
 import (
+	"github.com/Sergobot/Rocky/animation"
 	g "github.com/Sergobot/Rocky/geometry"
+	"github.com/Sergobot/Rocky/layouts"
+	"github.com/Sergobot/Rocky/widgets"
+	"github.com/Sergobot/Rocky/widgets/basic"
+	"github.com/Sergobot/Rocky/widgets/container"
+	"github.com/Sergobot/Rocky/widgets/debug"
+	"github.com/Sergobot/Rocky/widgets/overlay"
 	"github.com/Sergobot/Rocky/window/glfw"
 	"github.com/Sergobot/Rocky/window/state"
 )
@@ -31,8 +38,43 @@ type Window interface {
 
 	// Sets a layout of widgets to the window. These widgets in the layout will be drawn
 	// in Window.Update()
-	SetLayout()
-	Layout()
+	SetLayout(layouts.Layout)
+	Layout() layouts.Layout
+
+	// Root returns container holding the layout. It's the root of window's widget
+	// tree and always covers the whole window.
+	Root() *container.Container
+
+	// Overlay returns the stack of popups, modal dialogs and tooltips drawn
+	// above the root container.
+	Overlay() *overlay.Stack
+
+	// Animations returns the player of animations, which is updated with the
+	// frame delta in Update.
+	Animations() *animation.Player
+
+	// Focus returns focus manager, which keeps track of the widget receiving
+	// keyboard (and gamepad) input.
+	Focus() *basic.FocusManager
+
+	// Drags returns drag manager, which keeps track of the drag and drop in
+	// progress. Widgets start drags with it.
+	Drags() *basic.DragManager
+
+	// FocusRing returns the outline drawn around the focused widget, so it can
+	// be restyled.
+	FocusRing() widgets.Rectangle
+
+	// DebugOverlay returns the overlay with frame rate, OpenGL counters and
+	// bounds of widgets drawn on top of the window. F12 toggles it.
+	DebugOverlay() *debug.Overlay
+
+	// Update draws all the widgets and delivers pending events to them. Call it
+	// once per frame.
+	Update()
+
+	// ShouldClose returns true if user tried to close the window.
+	ShouldClose() bool
 }
 
 // New returns a newly created window. For now it returns only GLFW window, but later